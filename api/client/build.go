@@ -57,6 +57,8 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	forceRm := cmd.Bool([]string{"-force-rm"}, false, "Always remove intermediate containers")
 	pull := cmd.Bool([]string{"-pull"}, false, "Always attempt to pull a newer version of the image")
 	dockerfileName := cmd.String([]string{"f", "-file"}, "", "Name of the Dockerfile (Default is 'PATH/Dockerfile')")
+	target := cmd.String([]string{"-target"}, "", "Set the target build stage to build")
+	squash := cmd.Bool([]string{"-squash"}, false, "Squash the layers produced since the base image into a single layer")
 	flMemoryString := cmd.String([]string{"m", "-memory"}, "", "Memory limit")
 	flMemorySwap := cmd.String([]string{"-memory-swap"}, "", "Total memory (memory + swap), '-1' to disable swap")
 	flCPUShares := cmd.Int64([]string{"#c", "-cpu-shares"}, 0, "CPU shares (relative weight)")
@@ -67,6 +69,8 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	flCgroupParent := cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
 	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
 	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables")
+	flCacheFrom := opts.NewListOpts(nil)
+	cmd.Var(&flCacheFrom, []string{"-cache-from"}, "Images to consider as cache sources")
 
 	ulimits := make(map[string]*ulimit.Ulimit)
 	flUlimits := opts.NewUlimitOpt(&ulimits)
@@ -236,6 +240,10 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		v.Set("pull", "1")
 	}
 
+	if *squash {
+		v.Set("squash", "1")
+	}
+
 	v.Set("cpusetcpus", *flCPUSetCpus)
 	v.Set("cpusetmems", *flCPUSetMems)
 	v.Set("cpushares", strconv.FormatInt(*flCPUShares, 10))
@@ -247,6 +255,10 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 
 	v.Set("dockerfile", relDockerfile)
 
+	if *target != "" {
+		v.Set("target", *target)
+	}
+
 	ulimitsVar := flUlimits.GetList()
 	ulimitsJSON, err := json.Marshal(ulimitsVar)
 	if err != nil {
@@ -262,6 +274,14 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	}
 	v.Set("buildargs", string(buildArgsJSON))
 
+	if cacheFrom := flCacheFrom.GetAll(); len(cacheFrom) > 0 {
+		cacheFromJSON, err := json.Marshal(cacheFrom)
+		if err != nil {
+			return err
+		}
+		v.Set("cachefrom", string(cacheFromJSON))
+	}
+
 	headers := http.Header(make(map[string][]string))
 	buf, err := json.Marshal(cli.configFile.AuthConfigs)
 	if err != nil {
@@ -533,6 +553,10 @@ func getContextFromLocalDir(localDir, dockerfileName string) (absContextDir, rel
 
 var dockerfileFromLinePattern = regexp.MustCompile(`(?i)^[\s]*FROM[ \f\r\t\v]+(?P<image>[^ \f\r\t\v\n#]+)`)
 
+// dockerfileFromStagePattern matches the name of a build stage in a
+// "FROM <image> AS <name>" instruction.
+var dockerfileFromStagePattern = regexp.MustCompile(`(?i)^[\s]*FROM[ \f\r\t\v]+[^ \f\r\t\v\n#]+[ \f\r\t\v]+AS[ \f\r\t\v]+(?P<name>[^ \f\r\t\v\n#]+)`)
+
 type trustedDockerfile struct {
 	*os.File
 	size int64
@@ -581,12 +605,15 @@ func rewriteDockerfileFrom(dockerfileName string, translator func(string, regist
 		}
 	}()
 
+	// The names of the build stages, which are not resolved
+	stages := make(map[string]bool)
+
 	// Scan the lines of the Dockerfile, looking for a "FROM" line.
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		matches := dockerfileFromLinePattern.FindStringSubmatch(line)
-		if matches != nil && matches[1] != "scratch" {
+		if matches != nil && matches[1] != "scratch" && !stages[strings.ToLower(matches[1])] {
 			// Replace the line with a resolved "FROM repo@digest"
 			repo, tag := parsers.ParseRepositoryTag(matches[1])
 			if tag == "" {
@@ -615,6 +642,10 @@ func rewriteDockerfileFrom(dockerfileName string, translator func(string, regist
 			}
 		}
 
+		if stage := dockerfileFromStagePattern.FindStringSubmatch(line); stage != nil {
+			stages[strings.ToLower(stage[1])] = true
+		}
+
 		n, err := fmt.Fprintln(tempFile, line)
 		if err != nil {
 			return nil, nil, err
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	flag "github.com/docker/docker/pkg/mflag"
+)
+
+// CmdCheckpoint is the parent subcommand for all checkpoint commands
+//
+// Usage: docker checkpoint <COMMAND> <OPTS>
+func (cli *DockerCli) CmdCheckpoint(args ...string) error {
+	description := Cli.DockerCommands["checkpoint"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"create", "Create a checkpoint of a container"},
+		{"ls", "List the checkpoints of a container"},
+		{"rm", "Remove a checkpoint of a container"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker checkpoint COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("checkpoint", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdCheckpointCreate saves the state of the processes of a running
+// container in a checkpoint.
+//
+// Usage: docker checkpoint create [OPTIONS] CONTAINER CHECKPOINT
+func (cli *DockerCli) CmdCheckpointCreate(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint create", []string{"CONTAINER CHECKPOINT"}, "Create a checkpoint of a container", true)
+	leaveRunning := cmd.Bool([]string{"-leave-running"}, false, "Leave the container running after the checkpoint")
+
+	cmd.Require(flag.Exact, 2)
+	cmd.ParseFlags(args, true)
+
+	options := types.CheckpointCreateOptions{
+		CheckpointID: cmd.Arg(1),
+		Exit:         !*leaveRunning,
+	}
+	if _, _, err := readBody(cli.call("POST", "/containers/"+cmd.Arg(0)+"/checkpoint", options, nil)); err != nil {
+		return err
+	}
+	fmt.Fprintf(cli.out, "%s\n", cmd.Arg(1))
+	return nil
+}
+
+// CmdCheckpointLs lists the checkpoints of a container.
+//
+// Usage: docker checkpoint ls CONTAINER
+func (cli *DockerCli) CmdCheckpointLs(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint ls", []string{"CONTAINER"}, "List the checkpoints of a container", true)
+
+	cmd.Require(flag.Exact, 1)
+	cmd.ParseFlags(args, true)
+
+	resp, err := cli.call("GET", "/containers/"+cmd.Arg(0)+"/checkpoint", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	var checkpoints []types.Checkpoint
+	if err := json.NewDecoder(resp.body).Decode(&checkpoints); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintf(w, "CHECKPOINT NAME\n")
+	for _, checkpoint := range checkpoints {
+		fmt.Fprintf(w, "%s\n", checkpoint.Name)
+	}
+	w.Flush()
+	return nil
+}
+
+// CmdCheckpointRm removes checkpoints of a container.
+//
+// Usage: docker checkpoint rm CONTAINER CHECKPOINT [CHECKPOINT...]
+func (cli *DockerCli) CmdCheckpointRm(args ...string) error {
+	cmd := Cli.Subcmd("checkpoint rm", []string{"CONTAINER CHECKPOINT [CHECKPOINT...]"}, "Remove a checkpoint of a container", true)
+	cmd.Require(flag.Min, 2)
+	cmd.ParseFlags(args, true)
+
+	var status = 0
+	for _, checkpoint := range cmd.Args()[1:] {
+		_, err := cli.call("DELETE", "/containers/"+cmd.Arg(0)+"/checkpoint/"+checkpoint, nil, nil)
+		if err != nil {
+			fmt.Fprintf(cli.err, "%s\n", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(cli.out, "%s\n", checkpoint)
+	}
+
+	if status != 0 {
+		return Cli.StatusError{StatusCode: status}
+	}
+	return nil
+}
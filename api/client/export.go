@@ -2,6 +2,7 @@ package client
 
 import (
 	"errors"
+	"net/url"
 	"os"
 
 	Cli "github.com/docker/docker/cli"
@@ -16,6 +17,7 @@ import (
 func (cli *DockerCli) CmdExport(args ...string) error {
 	cmd := Cli.Subcmd("export", []string{"CONTAINER"}, Cli.DockerCommands["export"].Description, true)
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	compression := cmd.String([]string{"-compression"}, "none", "Compression of the archive (none, gzip)")
 	cmd.Require(flag.Exact, 1)
 
 	cmd.ParseFlags(args, true)
@@ -34,11 +36,15 @@ func (cli *DockerCli) CmdExport(args ...string) error {
 	}
 
 	image := cmd.Arg(0)
+	v := url.Values{}
+	if *compression != "none" {
+		v.Set("compression", *compression)
+	}
 	sopts := &streamOpts{
 		rawTerminal: true,
 		out:         output,
 	}
-	if _, err := cli.stream("GET", "/containers/"+image+"/export", sopts); err != nil {
+	if _, err := cli.stream("GET", "/containers/"+image+"/export?"+v.Encode(), sopts); err != nil {
 		return err
 	}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"strings"
 	"text/tabwriter"
 
@@ -99,16 +100,32 @@ func (cli *DockerCli) CmdNetworkRm(args ...string) error {
 
 // CmdNetworkConnect connects a container to a network
 //
-// Usage: docker network connect <NETWORK> <CONTAINER>
+// Usage: docker network connect [OPTIONS] <NETWORK> <CONTAINER>
 func (cli *DockerCli) CmdNetworkConnect(args ...string) error {
 	cmd := Cli.Subcmd("network connect", []string{"NETWORK CONTAINER"}, "Connects a container to a network", false)
+	flIPAddress := cmd.String([]string{"-ip"}, "", "IP Address")
+	flIPv6Address := cmd.String([]string{"-ip6"}, "", "IPv6 Address")
+	flLinks := opts.NewListOpts(opts.ValidateLink)
+	cmd.Var(&flLinks, []string{"-link"}, "Add link to another container")
+	flAliases := opts.NewListOpts(nil)
+	cmd.Var(&flAliases, []string{"-alias"}, "Add network-scoped alias for the container")
 	cmd.Require(flag.Exact, 2)
 	err := cmd.ParseFlags(args, true)
 	if err != nil {
 		return err
 	}
 
-	nc := types.NetworkConnect{Container: cmd.Arg(1)}
+	epConfig := &network.EndpointSettings{
+		Links:   flLinks.GetAll(),
+		Aliases: flAliases.GetAll(),
+	}
+	if *flIPAddress != "" || *flIPv6Address != "" {
+		epConfig.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: *flIPAddress,
+			IPv6Address: *flIPv6Address,
+		}
+	}
+	nc := types.NetworkConnect{Container: cmd.Arg(1), EndpointConfig: epConfig}
 	_, _, err = readBody(cli.call("POST", "/networks/"+cmd.Arg(0)+"/connect", nc, nil))
 	return err
 }
@@ -360,6 +377,7 @@ func networkUsage() string {
 		"disconnect": "Disconnect container from a network",
 		"inspect":    "Display detailed network information",
 		"ls":         "List all networks",
+		"prune":      "Remove all unused networks",
 		"rm":         "Remove a network",
 	}
 
@@ -372,3 +390,26 @@ func networkUsage() string {
 	help += fmt.Sprintf("\nRun 'docker network COMMAND --help' for more information on a command.")
 	return help
 }
+
+// CmdNetworkPrune removes the networks which are not used by any container.
+//
+// Usage: docker network prune [OPTIONS]
+func (cli *DockerCli) CmdNetworkPrune(args ...string) error {
+	cmd := Cli.Subcmd("network prune", nil, "Remove all unused networks", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	warning := "WARNING! This will remove all networks not used by at least one container."
+	if !*force && !cli.confirmPrune(warning) {
+		return nil
+	}
+
+	var report types.NetworksPruneReport
+	if err := cli.prune("/networks/prune", url.Values{}, &report); err != nil {
+		return err
+	}
+	cli.printPruned("Deleted Networks:", report.NetworksDeleted)
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 func (cli *DockerCli) CmdSave(args ...string) error {
 	cmd := Cli.Subcmd("save", []string{"IMAGE [IMAGE...]"}, Cli.DockerCommands["save"].Description+" (streamed to STDOUT by default)", true)
 	outfile := cmd.String([]string{"o", "-output"}, "", "Write to a file, instead of STDOUT")
+	compression := cmd.String([]string{"-compression"}, "none", "Compression of the archive (none, gzip)")
 	cmd.Require(flag.Min, 1)
 
 	cmd.ParseFlags(args, true)
@@ -44,6 +45,9 @@ func (cli *DockerCli) CmdSave(args ...string) error {
 	for _, arg := range cmd.Args() {
 		v.Add("names", arg)
 	}
+	if *compression != "none" {
+		v.Set("compression", *compression)
+	}
 	if _, err := cli.stream("GET", "/images/get?"+v.Encode(), sopts); err != nil {
 		return err
 	}
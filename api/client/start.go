@@ -49,10 +49,15 @@ func (cli *DockerCli) CmdStart(args ...string) error {
 	cmd := Cli.Subcmd("start", []string{"CONTAINER [CONTAINER...]"}, Cli.DockerCommands["start"].Description, true)
 	attach := cmd.Bool([]string{"a", "-attach"}, false, "Attach STDOUT/STDERR and forward signals")
 	openStdin := cmd.Bool([]string{"i", "-interactive"}, false, "Attach container's STDIN")
+	checkpoint := cmd.String([]string{"-checkpoint"}, "", "Restore the container from a checkpoint")
 	cmd.Require(flag.Min, 1)
 
 	cmd.ParseFlags(args, true)
 
+	if *checkpoint != "" && cmd.NArg() > 1 {
+		return fmt.Errorf("You cannot restore multiple containers from a checkpoint at once.")
+	}
+
 	var (
 		cErr chan error
 		tty  bool
@@ -125,8 +130,12 @@ func (cli *DockerCli) CmdStart(args ...string) error {
 
 	var encounteredError error
 	var errNames []string
+	query := url.Values{}
+	if *checkpoint != "" {
+		query.Set("checkpoint", *checkpoint)
+	}
 	for _, name := range cmd.Args() {
-		_, _, err := readBody(cli.call("POST", "/containers/"+name+"/start", nil, nil))
+		_, _, err := readBody(cli.call("POST", "/containers/"+name+"/start?"+query.Encode(), nil, nil))
 		if err != nil {
 			if !*attach && !*openStdin {
 				// attach and openStdin is false means it could be starting multiple containers
@@ -0,0 +1,321 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/units"
+)
+
+// CmdSystem is the parent subcommand for all system commands
+//
+// Usage: docker system <COMMAND> [OPTIONS]
+func (cli *DockerCli) CmdSystem(args ...string) error {
+	description := Cli.DockerCommands["system"].Description + "\n\nCommands:\n"
+	commands := [][]string{
+		{"df", "Show docker disk usage"},
+		{"prune", "Remove unused data"},
+	}
+
+	for _, cmd := range commands {
+		description += fmt.Sprintf("  %-25.25s%s\n", cmd[0], cmd[1])
+	}
+
+	description += "\nRun 'docker system COMMAND --help' for more information on a command"
+	cmd := Cli.Subcmd("system", []string{"[COMMAND]"}, description, false)
+
+	cmd.Require(flag.Exact, 0)
+	err := cmd.ParseFlags(args, true)
+	cmd.Usage()
+	return err
+}
+
+// CmdSystemDf shows the disk usage of the images, containers and volumes.
+//
+// Usage: docker system df [OPTIONS]
+func (cli *DockerCli) CmdSystemDf(args ...string) error {
+	cmd := Cli.Subcmd("system df", nil, "Show docker disk usage", true)
+	verbose := cmd.Bool([]string{"v", "-verbose"}, false, "Show detailed information on space usage")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	resp, err := cli.call("GET", "/system/df", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+
+	var du types.DiskUsage
+	if err := json.NewDecoder(resp.body).Decode(&du); err != nil {
+		return err
+	}
+
+	if *verbose {
+		cli.printDiskUsageVerbose(&du)
+		return nil
+	}
+
+	var activeImages int
+	for _, img := range du.Images {
+		if img.Containers > 0 {
+			activeImages++
+		}
+	}
+
+	var activeContainers int
+	var containersSize, containersReclaimable int64
+	for _, c := range du.Containers {
+		if c.SizeRw < 0 {
+			continue
+		}
+		containersSize += c.SizeRw
+		if c.Running {
+			activeContainers++
+		} else {
+			containersReclaimable += c.SizeRw
+		}
+	}
+
+	var activeVolumes int
+	var volumesSize, volumesReclaimable int64
+	for _, v := range du.Volumes {
+		if v.RefCount > 0 {
+			activeVolumes++
+		}
+		if v.Size < 0 {
+			continue
+		}
+		volumesSize += v.Size
+		if v.RefCount == 0 {
+			volumesReclaimable += v.Size
+		}
+	}
+
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	fmt.Fprintf(w, "Images\t%d\t%d\t%s\t%s\n", len(du.Images), activeImages,
+		units.HumanSize(float64(du.LayersSize)), reclaimableSize(du.LayersReclaimable, du.LayersSize))
+	fmt.Fprintf(w, "Containers\t%d\t%d\t%s\t%s\n", len(du.Containers), activeContainers,
+		units.HumanSize(float64(containersSize)), reclaimableSize(containersReclaimable, containersSize))
+	fmt.Fprintf(w, "Local Volumes\t%d\t%d\t%s\t%s\n", len(du.Volumes), activeVolumes,
+		units.HumanSize(float64(volumesSize)), reclaimableSize(volumesReclaimable, volumesSize))
+	w.Flush()
+	return nil
+}
+
+// reclaimableSize formats a reclaimable size along with its share of the
+// total size.
+func reclaimableSize(reclaimable, total int64) string {
+	var percent int64
+	if total > 0 {
+		percent = reclaimable * 100 / total
+	}
+	return fmt.Sprintf("%s (%d%%)", units.HumanSize(float64(reclaimable)), percent)
+}
+
+// printDiskUsageVerbose prints the disk usage of each image, container and
+// volume.
+func (cli *DockerCli) printDiskUsageVerbose(du *types.DiskUsage) {
+	fmt.Fprintf(cli.out, "Images space usage:\n\n")
+	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, img := range du.Images {
+		created := units.HumanDuration(time.Now().UTC().Sub(time.Unix(img.Created, 0))) + " ago"
+		for _, repoTag := range img.RepoTags {
+			repo, tag := parsers.ParseRepositoryTag(repoTag)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", repo, tag, stringid.TruncateID(img.ID), created,
+				units.HumanSize(float64(img.VirtualSize)), units.HumanSize(float64(img.SharedSize)),
+				units.HumanSize(float64(img.Size)), img.Containers)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintf(cli.out, "\nContainers space usage:\n\n")
+	w = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCREATED\tSTATUS\tSIZE\tNAMES")
+	for _, c := range du.Containers {
+		size := "N/A"
+		if c.SizeRw >= 0 {
+			size = units.HumanSize(float64(c.SizeRw))
+		}
+		var names []string
+		for _, name := range c.Names {
+			names = append(names, strings.TrimPrefix(name, "/"))
+		}
+		created := units.HumanDuration(time.Now().UTC().Sub(time.Unix(c.Created, 0))) + " ago"
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", stringid.TruncateID(c.ID), c.Image, created, c.Status, size,
+			strings.Join(names, ","))
+	}
+	w.Flush()
+
+	fmt.Fprintf(cli.out, "\nLocal Volumes space usage:\n\n")
+	w = tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "VOLUME NAME\tLINKS\tSIZE")
+	for _, v := range du.Volumes {
+		size := "N/A"
+		if v.Size >= 0 {
+			size = units.HumanSize(float64(v.Size))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", v.Name, v.RefCount, size)
+	}
+	w.Flush()
+}
+
+// CmdSystemPrune removes the stopped containers, the unused volumes and
+// networks and the dangling images, or all the unused images.
+//
+// Usage: docker system prune [OPTIONS]
+func (cli *DockerCli) CmdSystemPrune(args ...string) error {
+	cmd := Cli.Subcmd("system prune", nil, "Remove unused data", true)
+	all := cmd.Bool([]string{"a", "-all"}, false, "Remove all unused images, not just dangling ones")
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+	flFilter := opts.NewListOpts(nil)
+	cmd.Var(&flFilter, []string{"-filter"}, "Provide filter values (i.e. 'until=24h')")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	v, err := pruneFilterValues(flFilter)
+	if err != nil {
+		return err
+	}
+
+	imagesWarning := "all dangling images"
+	if *all {
+		imagesWarning = "all images without at least one container associated to them"
+	}
+	warning := "WARNING! This will remove:\n" +
+		"\t- all stopped containers\n"
+	if flFilter.Len() == 0 {
+		warning += "\t- all volumes not used by at least one container\n" +
+			"\t- all networks not used by at least one container\n"
+	}
+	warning += "\t- " + imagesWarning
+	if !*force && !cli.confirmPrune(warning) {
+		return nil
+	}
+
+	var spaceReclaimed uint64
+
+	var containers types.ContainersPruneReport
+	if err := cli.prune("/containers/prune", v, &containers); err != nil {
+		return err
+	}
+	cli.printPruned("Deleted Containers:", containers.ContainersDeleted)
+	spaceReclaimed += containers.SpaceReclaimed
+
+	// Volumes and networks have neither labels nor a creation time, so
+	// that the daemon rejects any filter for them
+	if flFilter.Len() == 0 {
+		var volumes types.VolumesPruneReport
+		if err := cli.prune("/volumes/prune", v, &volumes); err != nil {
+			return err
+		}
+		cli.printPruned("Deleted Volumes:", volumes.VolumesDeleted)
+		spaceReclaimed += volumes.SpaceReclaimed
+
+		var networks types.NetworksPruneReport
+		if err := cli.prune("/networks/prune", v, &networks); err != nil {
+			return err
+		}
+		cli.printPruned("Deleted Networks:", networks.NetworksDeleted)
+	}
+
+	if *all {
+		v, err = pruneFilterValues(flFilter, "dangling=false")
+		if err != nil {
+			return err
+		}
+	}
+	var images types.ImagesPruneReport
+	if err := cli.prune("/images/prune", v, &images); err != nil {
+		return err
+	}
+	if len(images.ImagesDeleted) > 0 {
+		fmt.Fprintln(cli.out, "Deleted Images:")
+		for _, del := range images.ImagesDeleted {
+			if del.Deleted != "" {
+				fmt.Fprintf(cli.out, "deleted: %s\n", del.Deleted)
+			} else {
+				fmt.Fprintf(cli.out, "untagged: %s\n", del.Untagged)
+			}
+		}
+		fmt.Fprintln(cli.out)
+	}
+	spaceReclaimed += images.SpaceReclaimed
+
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(spaceReclaimed)))
+	return nil
+}
+
+// pruneFilterValues returns the query of a prune request for the filters
+// given on the command line, followed by the extra filters.
+func pruneFilterValues(flFilter opts.ListOpts, extra ...string) (url.Values, error) {
+	pruneFilterArgs := filters.Args{}
+	for _, f := range append(flFilter.GetAll(), extra...) {
+		var err error
+		pruneFilterArgs, err = filters.ParseFlag(f, pruneFilterArgs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v := url.Values{}
+	if len(pruneFilterArgs) > 0 {
+		filterJSON, err := filters.ToParam(pruneFilterArgs)
+		if err != nil {
+			return nil, err
+		}
+		v.Set("filters", filterJSON)
+	}
+	return v, nil
+}
+
+// prune sends a prune request to the given endpoint and decodes its
+// report into report.
+func (cli *DockerCli) prune(path string, v url.Values, report interface{}) error {
+	resp, err := cli.call("POST", path+"?"+v.Encode(), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.body.Close()
+	return json.NewDecoder(resp.body).Decode(report)
+}
+
+// confirmPrune prints the warning and returns whether the user confirmed
+// the prune operation.
+func (cli *DockerCli) confirmPrune(warning string) bool {
+	fmt.Fprintf(cli.out, "%s\nAre you sure you want to continue? [y/N] ", warning)
+	reader := bufio.NewReader(cli.in)
+	line, _, err := reader.ReadLine()
+	if err != nil {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(string(line)))
+	return answer == "y" || answer == "yes"
+}
+
+// printPruned prints the pruned objects under the given header, if any.
+func (cli *DockerCli) printPruned(header string, deleted []string) {
+	if len(deleted) == 0 {
+		return
+	}
+	fmt.Fprintln(cli.out, header)
+	for _, name := range deleted {
+		fmt.Fprintln(cli.out, name)
+	}
+	fmt.Fprintln(cli.out)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/units"
 )
 
 // CmdVolume is the parent subcommand for all volume commands
@@ -25,6 +26,7 @@ func (cli *DockerCli) CmdVolume(args ...string) error {
 		{"create", "Create a volume"},
 		{"inspect", "Return low-level information on a volume"},
 		{"ls", "List volumes"},
+		{"prune", "Remove all unused volumes"},
 		{"rm", "Remove a volume"},
 	}
 
@@ -232,3 +234,27 @@ func (cli *DockerCli) CmdVolumeRm(args ...string) error {
 	}
 	return nil
 }
+
+// CmdVolumePrune removes the volumes which are not used by any container.
+//
+// Usage: docker volume prune [OPTIONS]
+func (cli *DockerCli) CmdVolumePrune(args ...string) error {
+	cmd := Cli.Subcmd("volume prune", nil, "Remove all unused volumes", true)
+	force := cmd.Bool([]string{"f", "-force"}, false, "Do not prompt for confirmation")
+
+	cmd.Require(flag.Exact, 0)
+	cmd.ParseFlags(args, true)
+
+	warning := "WARNING! This will remove all volumes not used by at least one container."
+	if !*force && !cli.confirmPrune(warning) {
+		return nil
+	}
+
+	var report types.VolumesPruneReport
+	if err := cli.prune("/volumes/prune", url.Values{}, &report); err != nil {
+		return err
+	}
+	cli.printPruned("Deleted Volumes:", report.VolumesDeleted)
+	fmt.Fprintf(cli.out, "Total reclaimed space: %s\n", units.HumanSize(float64(report.SpaceReclaimed)))
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "engine",
+	Subsystem: "api",
+	Name:      "request_duration_seconds",
+	Help:      "The number of seconds taken by the API requests",
+}, []string{"method", "route"})
+
+func init() {
+	prometheus.MustRegister(requestDuration)
+}
+
+// instrumentHandler records the duration of the requests handled by h for the
+// route with the given method and path.
+func instrumentHandler(method, route string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		h(w, r)
+		requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/autogen/dockerversion"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/version"
 	"golang.org/x/net/context"
 )
@@ -88,6 +89,54 @@ func versionMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 	}
 }
 
+// authorizationMiddleware consults the authorization plugins before the
+// request is handled, and again with the daemon's response before it is
+// written back to the client.
+func (s *Server) authorizationMiddleware(handler httputils.APIFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		// The user is authenticated through the TLS client certificate, if any
+		var user, userAuthNMethod string
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			user = r.TLS.PeerCertificates[0].Subject.CommonName
+			userAuthNMethod = "TLS"
+		}
+
+		authCtx := authorization.NewCtx(s.authZPlugins, user, userAuthNMethod, r.Method, r.RequestURI)
+		if err := authCtx.AuthZRequest(r); err != nil {
+			logrus.Errorf("AuthZRequest for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return authorizationError(err)
+		}
+
+		rw := authorization.NewResponseModifier(w)
+		if err := handler(ctx, rw, r, vars); err != nil {
+			return err
+		}
+
+		// Hijacked and streamed responses have already reached the client
+		if rw.Streamed() {
+			return nil
+		}
+
+		if err := authCtx.AuthZResponse(rw, r); err != nil {
+			logrus.Errorf("AuthZResponse for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return authorizationError(err)
+		}
+		return nil
+	}
+}
+
+// authorizationError converts an error returned by the authorization
+// plugins to an API error with the right status code.
+func authorizationError(err error) error {
+	switch e := err.(type) {
+	case *authorization.DeniedError:
+		return errors.ErrorCodeAuthorizationDenied.WithArgs(e.Plugin, e.Msg)
+	case *authorization.PluginError:
+		return errors.ErrorCodeAuthorizationPluginFailed.WithArgs(e.Plugin, e.Err)
+	}
+	return err
+}
+
 // handleWithGlobalMiddlwares wraps the handler function for a request with
 // the server's global middlewares. The order of the middlewares is backwards,
 // meaning that the first in the list will be evaludated last.
@@ -102,6 +151,10 @@ func versionMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 //		)
 //	)
 // )
+//
+// When authorization plugins are configured, the authorization middleware
+// runs first, so that unauthorized requests are rejected before anything
+// else is done with them.
 func (s *Server) handleWithGlobalMiddlewares(handler httputils.APIFunc) httputils.APIFunc {
 	middlewares := []middleware{
 		versionMiddleware,
@@ -110,6 +163,10 @@ func (s *Server) handleWithGlobalMiddlewares(handler httputils.APIFunc) httputil
 		s.loggingMiddleware,
 	}
 
+	if len(s.authZPlugins) > 0 {
+		middlewares = append(middlewares, s.authorizationMiddleware)
+	}
+
 	h := handler
 	for _, m := range middlewares {
 		h = m(h)
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/distribution/registry/api/errcode"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
 	"golang.org/x/net/context"
 )
 
@@ -55,3 +56,38 @@ func TestVersionMiddlewareWithErrors(t *testing.T) {
 		t.Fatalf("Expected ErrorCodeNewerClientVersion, got %v", err)
 	}
 }
+
+type denyAllPlugin struct{}
+
+func (denyAllPlugin) Name() string {
+	return "denyall"
+}
+
+func (denyAllPlugin) AuthZRequest(*authorization.Request) (*authorization.Response, error) {
+	return &authorization.Response{Allow: false, Msg: "not allowed"}, nil
+}
+
+func (denyAllPlugin) AuthZResponse(*authorization.Request) (*authorization.Response, error) {
+	return &authorization.Response{Allow: false, Msg: "not allowed"}, nil
+}
+
+func TestAuthorizationMiddlewareDenied(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		t.Fatal("Expected the handler not to be called for a denied request")
+		return nil
+	}
+
+	s := &Server{authZPlugins: []authorization.Plugin{denyAllPlugin{}}}
+	h := s.authorizationMiddleware(handler)
+
+	req, _ := http.NewRequest("GET", "/containers/json", nil)
+	resp := httptest.NewRecorder()
+	err := h(context.Background(), resp, req, map[string]string{})
+	derr, ok := err.(errcode.Error)
+	if !ok || derr.ErrorCode() != errors.ErrorCodeAuthorizationDenied {
+		t.Fatalf("Expected ErrorCodeAuthorizationDenied, got %v", err)
+	}
+	if code := derr.ErrorCode().Descriptor().HTTPStatusCode; code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, code)
+	}
+}
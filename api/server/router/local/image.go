@@ -49,7 +49,7 @@ func (s *router) postCommit(ctx context.Context, w http.ResponseWriter, r *http.
 		pause = true
 	}
 
-	c, _, err := runconfig.DecodeContainerConfig(r.Body)
+	c, _, _, err := runconfig.DecodeContainerConfig(r.Body)
 	if err != nil && err != io.EOF { //Do not fail if body is empty.
 		return err
 	}
@@ -62,6 +62,8 @@ func (s *router) postCommit(ctx context.Context, w http.ResponseWriter, r *http.
 		Comment: r.Form.Get("comment"),
 		Changes: r.Form["changes"],
 		Config:  c,
+		Squash:     httputils.BoolValue(r, "squash"),
+		SquashBase: r.Form.Get("squashbase"),
 	}
 
 	if !s.daemon.Exists(cname) {
@@ -119,10 +121,16 @@ func (s *router) postImagesCreate(ctx context.Context, w http.ResponseWriter, r
 			}
 		}
 
+		var closeNotifier <-chan bool
+		if notifier, ok := w.(http.CloseNotifier); ok {
+			closeNotifier = notifier.CloseNotify()
+		}
+
 		imagePullConfig := &graph.ImagePullConfig{
 			MetaHeaders: metaHeaders,
 			AuthConfig:  authConfig,
 			OutStream:   output,
+			Stop:        closeNotifier,
 		}
 
 		err = s.daemon.PullImage(image, tag, imagePullConfig)
@@ -182,6 +190,11 @@ func (s *router) postImagesPush(ctx context.Context, w http.ResponseWriter, r *h
 		}
 	}
 
+	var closeNotifier <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closeNotifier = notifier.CloseNotify()
+	}
+
 	name := vars["name"]
 	output := ioutils.NewWriteFlusher(w)
 	imagePushConfig := &graph.ImagePushConfig{
@@ -189,6 +202,7 @@ func (s *router) postImagesPush(ctx context.Context, w http.ResponseWriter, r *h
 		AuthConfig:  authConfig,
 		Tag:         r.Form.Get("tag"),
 		OutStream:   output,
+		Stop:        closeNotifier,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -208,7 +222,15 @@ func (s *router) getImagesGet(ctx context.Context, w http.ResponseWriter, r *htt
 		return err
 	}
 
-	w.Header().Set("Content-Type", "application/x-tar")
+	compression, err := exportCompression(r)
+	if err != nil {
+		return err
+	}
+	if compression == archive.Gzip {
+		w.Header().Set("Content-Type", "application/x-gzip")
+	} else {
+		w.Header().Set("Content-Type", "application/x-tar")
+	}
 
 	output := ioutils.NewWriteFlusher(w)
 	var names []string
@@ -218,7 +240,7 @@ func (s *router) getImagesGet(ctx context.Context, w http.ResponseWriter, r *htt
 		names = r.Form["names"]
 	}
 
-	if err := s.daemon.ExportImage(names, output); err != nil {
+	if err := s.daemon.ExportImage(names, compression, output); err != nil {
 		if !output.Flushed() {
 			return err
 		}
@@ -314,6 +336,7 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	}
 
 	buildConfig.DockerfileName = r.FormValue("dockerfile")
+	buildConfig.Target = r.FormValue("target")
 	buildConfig.Verbose = !httputils.BoolValue(r, "q")
 	buildConfig.UseCache = !httputils.BoolValue(r, "nocache")
 	buildConfig.ForceRemove = httputils.BoolValue(r, "forcerm")
@@ -325,6 +348,7 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	buildConfig.CPUSetCpus = r.FormValue("cpusetcpus")
 	buildConfig.CPUSetMems = r.FormValue("cpusetmems")
 	buildConfig.CgroupParent = r.FormValue("cgroupparent")
+	buildConfig.Squash = httputils.BoolValue(r, "squash")
 
 	var buildUlimits = []*ulimit.Ulimit{}
 	ulimitsJSON := r.FormValue("ulimits")
@@ -344,6 +368,15 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		buildConfig.BuildArgs = buildArgs
 	}
 
+	var cacheFrom = []string{}
+	cacheFromJSON := r.FormValue("cachefrom")
+	if cacheFromJSON != "" {
+		if err := json.NewDecoder(strings.NewReader(cacheFromJSON)).Decode(&cacheFrom); err != nil {
+			return errf(err)
+		}
+		buildConfig.CacheFrom = cacheFrom
+	}
+
 	remoteURL := r.FormValue("remote")
 
 	// Currently, only used if context is from a remote url.
@@ -529,3 +562,28 @@ func (s *router) getImagesSearch(ctx context.Context, w http.ResponseWriter, r *
 	}
 	return httputils.WriteJSON(w, http.StatusOK, query.Results)
 }
+
+func (s *router) postImagesPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	report, err := s.daemon.ImagesPrune(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
+// exportCompression returns the compression of the archive requested with
+// the "compression" parameter of an export or a save.
+func exportCompression(r *http.Request) (archive.Compression, error) {
+	switch compression := r.Form.Get("compression"); compression {
+	case "", "none":
+		return archive.Uncompressed, nil
+	case "gzip":
+		return archive.Gzip, nil
+	default:
+		return archive.Uncompressed, derr.ErrorCodeInvalidCompression.WithArgs(compression)
+	}
+}
@@ -10,9 +10,9 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/autogen/dockerversion"
 	"github.com/docker/docker/pkg/ioutils"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/pkg/parsers/kernel"
 	"github.com/docker/docker/utils"
@@ -52,6 +52,20 @@ func (s *router) getInfo(ctx context.Context, w http.ResponseWriter, r *http.Req
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *router) getSystemDiskUsage(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	var closeNotifier <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closeNotifier = notifier.CloseNotify()
+	}
+
+	du, err := s.daemon.SystemDiskUsage(closeNotifier)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, du)
+}
+
 func buildOutputEncoder(w http.ResponseWriter) *json.Encoder {
 	w.Header().Set("Content-Type", "application/json")
 	outStream := ioutils.NewWriteFlusher(w)
@@ -89,11 +103,30 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 
 	enc := buildOutputEncoder(w)
 
-	current, l, cancel := s.daemon.SubscribeToEvents()
+	// Past events are only replayed when since is given, in which case until
+	// bounds the replay to the end of that second. Otherwise only new events
+	// are streamed.
+	sinceTime := time.Now()
+	var untilTime time.Time
+	if since != -1 {
+		sinceTime = time.Unix(since, 0)
+		if until > 0 {
+			untilTime = time.Unix(until+1, 0)
+		}
+	}
+
+	replay, l, cancel := s.daemon.SubscribeToEvents(sinceTime, untilTime)
 	defer cancel()
 
+	// Clients older than 1.22 only understand container and image events,
+	// which are the ones with the deprecated status field set
+	legacy := httputils.VersionFromContext(ctx).LessThan("1.22")
+
 	eventFilter := s.daemon.GetEventFilter(ef)
-	handleEvent := func(ev *jsonmessage.JSONMessage) error {
+	handleEvent := func(ev *eventtypes.Message) error {
+		if legacy && ev.Status == "" {
+			return nil
+		}
 		if eventFilter.Include(ev) {
 			if err := enc.Encode(ev); err != nil {
 				return err
@@ -102,16 +135,8 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		return nil
 	}
 
-	if since == -1 {
-		current = nil
-	}
-	for _, ev := range current {
-		if ev.Time < since {
-			continue
-		}
-		if err := handleEvent(ev); err != nil {
-			return err
-		}
+	if err := replay(handleEvent); err != nil {
+		return err
 	}
 
 	var closeNotify <-chan bool
@@ -122,7 +147,7 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	for {
 		select {
 		case ev := <-l:
-			jev, ok := ev.(*jsonmessage.JSONMessage)
+			jev, ok := ev.(*eventtypes.Message)
 			if !ok {
 				continue
 			}
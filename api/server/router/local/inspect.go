@@ -21,6 +21,8 @@ func (s *router) getContainersByName(ctx context.Context, w http.ResponseWriter,
 		json, err = s.daemon.ContainerInspectPre120(vars["name"])
 	case version.Equal("1.20"):
 		json, err = s.daemon.ContainerInspect120(vars["name"])
+	case version.Equal("1.21"):
+		json, err = s.daemon.ContainerInspect121(vars["name"], displaySize)
 	default:
 		json, err = s.daemon.ContainerInspect(vars["name"], displaySize)
 	}
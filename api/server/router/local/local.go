@@ -99,6 +99,7 @@ func (r *router) initRoutes() {
 		NewGetRoute("/_ping", pingHandler),
 		NewGetRoute("/events", r.getEvents),
 		NewGetRoute("/info", r.getInfo),
+		NewGetRoute("/system/df", r.getSystemDiskUsage),
 		NewGetRoute("/version", r.getVersion),
 		NewGetRoute("/images/json", r.getImagesJSON),
 		NewGetRoute("/images/search", r.getImagesSearch),
@@ -116,6 +117,7 @@ func (r *router) initRoutes() {
 		NewGetRoute("/containers/{name:.*}/attach/ws", r.wsContainersAttach),
 		NewGetRoute("/exec/{id:.*}/json", r.getExecByID),
 		NewGetRoute("/containers/{name:.*}/archive", r.getContainersArchive),
+		NewGetRoute("/containers/{name:.*}/checkpoint", r.getContainerCheckpoints),
 		NewGetRoute("/volumes", r.getVolumesList),
 		NewGetRoute("/volumes/{name:.*}", r.getVolumeByName),
 		// POST
@@ -124,9 +126,11 @@ func (r *router) initRoutes() {
 		NewPostRoute("/build", r.postBuild),
 		NewPostRoute("/images/create", r.postImagesCreate),
 		NewPostRoute("/images/load", r.postImagesLoad),
+		NewPostRoute("/images/prune", r.postImagesPrune),
 		NewPostRoute("/images/{name:.*}/push", r.postImagesPush),
 		NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
 		NewPostRoute("/containers/create", r.postContainersCreate),
+		NewPostRoute("/containers/prune", r.postContainersPrune),
 		NewPostRoute("/containers/{name:.*}/kill", r.postContainersKill),
 		NewPostRoute("/containers/{name:.*}/pause", r.postContainersPause),
 		NewPostRoute("/containers/{name:.*}/unpause", r.postContainersUnpause),
@@ -141,10 +145,14 @@ func (r *router) initRoutes() {
 		NewPostRoute("/exec/{name:.*}/start", r.postContainerExecStart),
 		NewPostRoute("/exec/{name:.*}/resize", r.postContainerExecResize),
 		NewPostRoute("/containers/{name:.*}/rename", r.postContainerRename),
+		NewPostRoute("/containers/{name:.*}/update", r.postContainerUpdate),
+		NewPostRoute("/containers/{name:.*}/checkpoint", r.postContainerCheckpoint),
 		NewPostRoute("/volumes/create", r.postVolumesCreate),
+		NewPostRoute("/volumes/prune", r.postVolumesPrune),
 		// PUT
 		NewPutRoute("/containers/{name:.*}/archive", r.putContainersArchive),
 		// DELETE
+		NewDeleteRoute("/containers/{name:.*}/checkpoint/{checkpoint:.*}", r.deleteContainerCheckpoint),
 		NewDeleteRoute("/containers/{name:.*}", r.deleteContainers),
 		NewDeleteRoute("/images/{name:.*}", r.deleteImages),
 		NewDeleteRoute("/volumes/{name:.*}", r.deleteVolumes),
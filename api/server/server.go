@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/api/server/router/local"
 	"github.com/docker/docker/api/server/router/network"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/sockets"
 	"github.com/docker/docker/utils"
 	"github.com/gorilla/mux"
@@ -32,14 +33,18 @@ type Config struct {
 	SocketGroup string
 	TLSConfig   *tls.Config
 	Addrs       []Addr
+	// AuthZPluginNames lists the authorization plugins consulted, in
+	// order, for every API request.
+	AuthZPluginNames []string
 }
 
 // Server contains instance details for the server
 type Server struct {
-	cfg     *Config
-	start   chan struct{}
-	servers []*HTTPServer
-	routers []router.Router
+	cfg          *Config
+	start        chan struct{}
+	servers      []*HTTPServer
+	routers      []router.Router
+	authZPlugins []authorization.Plugin
 }
 
 // Addr contains string representation of address and its protocol (tcp, unix...).
@@ -52,8 +57,9 @@ type Addr struct {
 // It allocates resources which will be needed for ServeAPI(ports, unix-sockets).
 func New(cfg *Config) (*Server, error) {
 	s := &Server{
-		cfg:   cfg,
-		start: make(chan struct{}),
+		cfg:          cfg,
+		start:        make(chan struct{}),
+		authZPlugins: authorization.NewPlugins(cfg.AuthZPluginNames),
 	}
 	for _, addr := range cfg.Addrs {
 		srv, err := s.newServer(addr.Proto, addr.Addr)
@@ -192,7 +198,7 @@ func (s *Server) CreateMux() *mux.Router {
 	logrus.Debugf("Registering routers")
 	for _, apiRouter := range s.routers {
 		for _, r := range apiRouter.Routes() {
-			f := s.makeHTTPHandler(r.Handler())
+			f := instrumentHandler(r.Method(), r.Path(), s.makeHTTPHandler(r.Handler()))
 
 			logrus.Debugf("Registering %s, %s", r.Method(), r.Path())
 			m.Path(versionMatcher + r.Path()).Methods(r.Method()).Handler(f)
@@ -0,0 +1,41 @@
+// Package events defines the messages sent by the daemon to the clients
+// subscribed to its events.
+package events
+
+const (
+	// ContainerEventType is the event type that containers generate
+	ContainerEventType = "container"
+	// ImageEventType is the event type that images generate
+	ImageEventType = "image"
+	// VolumeEventType is the event type that volumes generate
+	VolumeEventType = "volume"
+	// NetworkEventType is the event type that networks generate
+	NetworkEventType = "network"
+	// DaemonEventType is the event type that the daemon generates
+	DaemonEventType = "daemon"
+)
+
+// Actor describes something that generates events, like a container, a
+// network or a volume. It has an ID and a set of attributes. The attributes
+// of a container are its labels, along with its name and image; other
+// actors build their attributes from their own properties.
+type Actor struct {
+	ID         string
+	Attributes map[string]string
+}
+
+// Message represents the information an event contains.
+type Message struct {
+	// Deprecated information from JSONMessage, only set for container and
+	// image events.
+	Status string `json:"status,omitempty"`
+	ID     string `json:"id,omitempty"`
+	From   string `json:"from,omitempty"`
+
+	Type   string
+	Action string
+	Actor  Actor
+
+	Time     int64 `json:"time,omitempty"`
+	TimeNano int64 `json:"timeNano,omitempty"`
+}
@@ -20,6 +20,13 @@ type ContainerCreateResponse struct {
 	Warnings []string `json:"Warnings"`
 }
 
+// ContainerUpdateResponse contains response of Remote API:
+// POST /containers/{name:.*}/update
+type ContainerUpdateResponse struct {
+	// Warnings are any warnings encountered during the updating of the container.
+	Warnings []string `json:"Warnings"`
+}
+
 // ContainerExecCreateResponse contains response of Remote API:
 // POST "/containers/{name:.*}/exec"
 type ContainerExecCreateResponse struct {
@@ -244,6 +251,30 @@ type ContainerState struct {
 	Error      string
 	StartedAt  string
 	FinishedAt string
+	Health     *Health `json:",omitempty"`
+}
+
+// Health states
+const (
+	NoHealthcheck = "none"      // Indicates there is no healthcheck
+	Starting      = "starting"  // Starting indicates that the container is not yet ready
+	Healthy       = "healthy"   // Healthy indicates that the container is running correctly
+	Unhealthy     = "unhealthy" // Unhealthy indicates that the container has a problem
+)
+
+// Health stores information about the container's healthcheck results
+type Health struct {
+	Status        string               // Status is one of Starting, Healthy or Unhealthy
+	FailingStreak int                  // FailingStreak is the number of consecutive failures
+	Log           []*HealthcheckResult // Log contains the last few results (oldest first)
+}
+
+// HealthcheckResult stores information about a single run of a healthcheck probe
+type HealthcheckResult struct {
+	Start    time.Time // Start is the time this check started
+	End      time.Time // End is the time this check ended
+	ExitCode int       // ExitCode meanings: 0=healthy, 1=unhealthy, else=error running probe
+	Output   string    // Output from last check
 }
 
 // ContainerJSONBase contains response of Remote API:
@@ -312,6 +343,87 @@ type VolumeCreateRequest struct {
 	DriverOpts map[string]string // DriverOpts holds the driver specific options to use for when creating the volume.
 }
 
+// CheckpointCreateOptions contains the request for the remote API:
+// POST "/containers/{name:.*}/checkpoint"
+type CheckpointCreateOptions struct {
+	CheckpointID string // CheckpointID is the name of the checkpoint
+	Exit         bool   // Exit stops the container once checkpointed
+}
+
+// Checkpoint contains the response for the remote API:
+// GET "/containers/{name:.*}/checkpoint"
+type Checkpoint struct {
+	Name string // Name is the name of the checkpoint
+}
+
+// ContainersPruneReport contains the response for the remote API:
+// POST "/containers/prune"
+type ContainersPruneReport struct {
+	ContainersDeleted []string // ContainersDeleted is the list of the IDs of the removed containers
+	SpaceReclaimed    uint64   // SpaceReclaimed is the size in bytes of the removed writable layers
+}
+
+// ImagesPruneReport contains the response for the remote API:
+// POST "/images/prune"
+type ImagesPruneReport struct {
+	ImagesDeleted  []ImageDelete // ImagesDeleted is the list of the untagged and deleted images
+	SpaceReclaimed uint64        // SpaceReclaimed is the size in bytes of the deleted images
+}
+
+// VolumesPruneReport contains the response for the remote API:
+// POST "/volumes/prune"
+type VolumesPruneReport struct {
+	VolumesDeleted []string // VolumesDeleted is the list of the names of the removed volumes
+	SpaceReclaimed uint64   // SpaceReclaimed is the size in bytes of the removed local volumes
+}
+
+// NetworksPruneReport contains the response for the remote API:
+// POST "/networks/prune"
+type NetworksPruneReport struct {
+	NetworksDeleted []string // NetworksDeleted is the list of the names of the removed networks
+}
+
+// DiskUsage contains the response for the remote API:
+// GET "/system/df"
+type DiskUsage struct {
+	LayersSize        int64             // LayersSize is the size of all the image layers
+	LayersReclaimable int64             // LayersReclaimable is the size of the layers not used by any container
+	Images            []*ImageUsage     // Images is the disk usage of the images
+	Containers        []*ContainerUsage // Containers is the disk usage of the containers
+	Volumes           []*VolumeUsage    // Volumes is the disk usage of the volumes
+}
+
+// ImageUsage is the disk usage of an image in a DiskUsage report
+type ImageUsage struct {
+	ID          string
+	RepoTags    []string
+	Created     int64
+	Size        int64 // Size is the size of the layers of the image which are not shared with other images
+	SharedSize  int64 // SharedSize is the size of the layers of the image shared with other images
+	VirtualSize int64 // VirtualSize is the size of all the layers of the image
+	Containers  int   // Containers is the number of containers using the image
+}
+
+// ContainerUsage is the disk usage of a container in a DiskUsage report
+type ContainerUsage struct {
+	ID         string
+	Names      []string
+	Image      string
+	Created    int64
+	Status     string
+	Running    bool
+	SizeRw     int64 // SizeRw is the size of the writable layer, or -1 if it is unknown
+	SizeRootFs int64 // SizeRootFs is the size of the root filesystem, or -1 if it is unknown
+}
+
+// VolumeUsage is the disk usage of a volume in a DiskUsage report
+type VolumeUsage struct {
+	Name     string
+	Driver   string
+	Size     int64 // Size is the size of the volume, or -1 if it is not a local volume
+	RefCount int   // RefCount is the number of containers using the volume
+}
+
 // NetworkResource is the body of the "get network" http response message
 type NetworkResource struct {
 	Name       string                      `json:"name"`
@@ -348,7 +460,8 @@ type NetworkCreateResponse struct {
 
 // NetworkConnect represents the data to be used to connect a container to the network
 type NetworkConnect struct {
-	Container string `json:"container"`
+	Container      string                    `json:"container"`
+	EndpointConfig *network.EndpointSettings `json:"endpoint_config,omitempty"`
 }
 
 // NetworkDisconnect represents the data to be used to disconnect a container from the network
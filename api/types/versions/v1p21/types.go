@@ -0,0 +1,20 @@
+// Package v1p21 provides specific API types for the API version 1, patch 21.
+package v1p21
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/network"
+)
+
+// ContainerJSON is a backcompatibility struct for the API 1.21
+type ContainerJSON struct {
+	*types.ContainerJSON
+	NetworkSettings *NetworkSettings
+}
+
+// NetworkSettings is a backcompatibility struct for APIs prior to 1.22,
+// which only list the names of the networks of the container
+type NetworkSettings struct {
+	*network.Settings
+	Networks []string
+}
@@ -115,6 +115,9 @@ type Docker interface {
 	Remove(id string, cfg *daemon.ContainerRmConfig) error
 	// Commit creates a new Docker image from an existing Docker container.
 	Commit(*daemon.Container, *daemon.ContainerCommitConfig) (*image.Image, error)
+	// Squash creates a new Docker image from the image `imageID`, with a
+	// single layer holding the changes of its layers since the image `baseID`.
+	Squash(imageID, baseID string) (*image.Image, error)
 	// Copy copies/extracts a source FileInfo to a destination path inside a container
 	// specified by a container object.
 	// TODO: make an Extract method instead of passing `decompress`
@@ -136,4 +139,9 @@ type ImageCache interface {
 	// GetCachedImage returns a reference to a cached image whose parent equals `parent`
 	// and runconfig equals `cfg`. A cache miss is expected to return an empty ID and a nil error.
 	GetCachedImage(parentID string, cfg *runconfig.Config) (imageID string, err error)
+	// GetCachedImageFrom returns a reference to an image from the history of
+	// one of the `sources` images whose parent equals `parent`, or was pulled
+	// from the same image, and runconfig equals `cfg`. A cache miss is
+	// expected to return an empty ID and a nil error.
+	GetCachedImageFrom(parentID string, cfg *runconfig.Config, sources []string) (imageID string, err error)
 }
@@ -54,6 +54,9 @@ type Config struct {
 	ForceRemove bool
 	Pull        bool
 	BuildArgs   map[string]string // build-time args received in build context for expansion/substitution and commands in 'run'.
+	Target      string            // name of the build stage to build, the last stage if empty.
+	CacheFrom   []string          // images whose history is used as a build cache in addition to the local images.
+	Squash      bool              // squash the layers produced since the base image of the built stage into a single layer.
 
 	// resource constraints
 	// TODO: factor out to be reused with Run ?
@@ -93,6 +96,10 @@ type Builder struct {
 	cancelled        chan struct{}
 	cancelOnce       sync.Once
 	allowedBuildArgs map[string]bool // list of build-time args that are allowed for expansion/substitution and passing to commands in 'run'.
+	skippedBuildArgs map[string]bool // build-time args declared in the stages which are not built.
+	cacheSources     []string        // IDs of the CacheFrom images.
+	stages           []*buildStage
+	currentStage     *buildStage
 
 	// TODO: remove once docker.Commit can receive a tag
 	id           string
@@ -120,6 +127,7 @@ func NewBuilder(config *Config, docker builder.Docker, context builder.Context,
 		cancelled:        make(chan struct{}),
 		id:               stringid.GenerateNonCryptoID(),
 		allowedBuildArgs: make(map[string]bool),
+		skippedBuildArgs: make(map[string]bool),
 	}
 	if dockerfile != nil {
 		b.dockerfile, err = parser.Parse(dockerfile)
@@ -157,34 +165,54 @@ func (b *Builder) Build() (string, error) {
 		}
 	}
 
+	b.resolveCacheSources()
+
+	stages, err := splitStages(b.dockerfile)
+	if err != nil {
+		return "", err
+	}
+	built, err := targetStages(stages, b.Target)
+	if err != nil {
+		return "", err
+	}
+	b.stages = stages
+	b.skipStages(built)
+
 	var shortImgID string
-	for i, n := range b.dockerfile.Children {
-		select {
-		case <-b.cancelled:
-			logrus.Debug("Builder: build cancelled!")
-			fmt.Fprintf(b.Stdout, "Build cancelled")
-			return "", fmt.Errorf("Build cancelled")
-		default:
-			// Not cancelled yet, keep going...
+	for _, s := range built {
+		if b.currentStage != nil {
+			b.resetStage()
 		}
-		if err := b.dispatch(i, n); err != nil {
-			if b.ForceRemove {
+		b.currentStage = s
+		for i, n := range s.nodes {
+			select {
+			case <-b.cancelled:
+				logrus.Debug("Builder: build cancelled!")
+				fmt.Fprintf(b.Stdout, "Build cancelled")
+				return "", fmt.Errorf("Build cancelled")
+			default:
+				// Not cancelled yet, keep going...
+			}
+			if err := b.dispatch(s.step+i, n); err != nil {
+				if b.ForceRemove {
+					b.clearTmp()
+				}
+				return "", err
+			}
+			shortImgID = stringid.TruncateID(b.image)
+			fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
+			if b.Remove {
 				b.clearTmp()
 			}
-			return "", err
-		}
-		shortImgID = stringid.TruncateID(b.image)
-		fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
-		if b.Remove {
-			b.clearTmp()
 		}
+		s.image = b.image
 	}
 
 	// check if there are any leftover build-args that were passed but not
 	// consumed during build. Return an error, if there are any.
 	leftoverArgs := []string{}
 	for arg := range b.BuildArgs {
-		if !b.isBuildArgAllowed(arg) {
+		if !b.isBuildArgAllowed(arg) && !b.skippedBuildArgs[arg] {
 			leftoverArgs = append(leftoverArgs, arg)
 		}
 	}
@@ -196,6 +224,18 @@ func (b *Builder) Build() (string, error) {
 		return "", fmt.Errorf("No image was generated. Is your Dockerfile empty?")
 	}
 
+	if b.Squash && b.image != b.currentStage.base {
+		img, err := b.docker.Squash(b.image, b.currentStage.base)
+		if err != nil {
+			return "", err
+		}
+		b.docker.Retain(b.id, img.ID)
+		b.activeImages = append(b.activeImages, img.ID)
+		b.image = img.ID
+		shortImgID = stringid.TruncateID(b.image)
+		fmt.Fprintf(b.Stdout, "Squashed the layers into %s\n", shortImgID)
+	}
+
 	fmt.Fprintf(b.Stdout, "Successfully built %s\n", shortImgID)
 	return b.image, nil
 }
@@ -216,6 +256,11 @@ type CommitConfig struct {
 	Comment string
 	Changes []string
 	Config  *runconfig.Config
+	// Squash is true to squash the layers of the committed image since the
+	// image SquashBase into a single layer. SquashBase defaults to the image
+	// of the container, and is "scratch" to squash all the layers.
+	Squash     bool
+	SquashBase string
 }
 
 // BuildFromConfig will do build directly from parameter 'changes', which comes
@@ -287,6 +332,20 @@ func Commit(containerName string, d *daemon.Daemon, c *CommitConfig) (string, er
 		Author:  c.Author,
 		Comment: c.Comment,
 		Config:  newConfig,
+		Squash:  c.Squash,
+	}
+	if c.Squash {
+		switch c.SquashBase {
+		case "":
+			commitCfg.SquashBase = container.ImageID
+		case NoBaseImageSpecifier:
+		default:
+			base, err := d.LookupImage(c.SquashBase)
+			if err != nil {
+				return "", err
+			}
+			commitCfg.SquashBase = base.ID
+		}
 	}
 
 	img, err := d.Commit(container, commitCfg)
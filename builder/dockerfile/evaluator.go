@@ -23,11 +23,25 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/builder/dockerfile/parser"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// stepDuration records the duration of the build steps, by instruction.
+var stepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "engine",
+	Subsystem: "builder",
+	Name:      "step_seconds",
+	Help:      "The number of seconds taken by the build steps",
+}, []string{"instruction"})
+
+func init() {
+	prometheus.MustRegister(stepDuration)
+}
+
 // Environment variable interpolation will happen on these statements only.
 var replaceEnvAllowed = map[string]struct{}{
 	command.Env:        {},
@@ -46,22 +60,23 @@ var evaluateTable map[string]func(*Builder, []string, map[string]bool, string) e
 
 func init() {
 	evaluateTable = map[string]func(*Builder, []string, map[string]bool, string) error{
-		command.Env:        env,
-		command.Label:      label,
-		command.Maintainer: maintainer,
-		command.Add:        add,
-		command.Copy:       dispatchCopy, // copy() is a go builtin
-		command.From:       from,
-		command.Onbuild:    onbuild,
-		command.Workdir:    workdir,
-		command.Run:        run,
-		command.Cmd:        cmd,
-		command.Entrypoint: entrypoint,
-		command.Expose:     expose,
-		command.Volume:     volume,
-		command.User:       user,
-		command.StopSignal: stopSignal,
-		command.Arg:        arg,
+		command.Env:         env,
+		command.Label:       label,
+		command.Maintainer:  maintainer,
+		command.Add:         add,
+		command.Copy:        dispatchCopy, // copy() is a go builtin
+		command.From:        from,
+		command.Onbuild:     onbuild,
+		command.Workdir:     workdir,
+		command.Run:         run,
+		command.Cmd:         cmd,
+		command.Entrypoint:  entrypoint,
+		command.Expose:      expose,
+		command.Volume:      volume,
+		command.User:        user,
+		command.StopSignal:  stopSignal,
+		command.Arg:         arg,
+		command.Healthcheck: healthcheck,
 	}
 }
 
@@ -80,6 +95,7 @@ func init() {
 // deal with that, at least until it becomes more of a general concern with new
 // features.
 func (b *Builder) dispatch(stepN int, ast *parser.Node) error {
+	start := time.Now()
 	cmd := ast.Value
 	upperCasedCmd := strings.ToUpper(cmd)
 
@@ -173,6 +189,9 @@ func (b *Builder) dispatch(stepN int, ast *parser.Node) error {
 	if f, ok := evaluateTable[cmd]; ok {
 		b.flags = NewBFlags()
 		b.flags.Args = flags
+		defer func() {
+			stepDuration.WithLabelValues(cmd).Observe(time.Since(start).Seconds())
+		}()
 		return f(b, strList, attrs, original)
 	}
 
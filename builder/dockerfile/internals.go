@@ -104,8 +104,16 @@ type copyInfo struct {
 	decompress bool
 }
 
-func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalDecompression bool, cmdName string) error {
-	if b.context == nil {
+// copySource is where ADD and COPY copy files from: the build context, or
+// the root filesystem of an image for COPY --from.
+type copySource struct {
+	context builder.Context
+	// imageID is the ID of the image the files are copied from, if any.
+	imageID string
+}
+
+func (b *Builder) runContextCommand(src *copySource, args []string, allowRemote bool, allowLocalDecompression bool, cmdName string) error {
+	if src.context == nil {
 		return fmt.Errorf("No context given. Impossible to use %s", cmdName)
 	}
 
@@ -141,7 +149,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 			continue
 		}
 		// not a URL
-		subInfos, err := b.calcCopyInfo(cmdName, orig, allowLocalDecompression, true)
+		subInfos, err := b.calcCopyInfo(src.context, cmdName, orig, allowLocalDecompression, true)
 		if err != nil {
 			return err
 		}
@@ -182,6 +190,10 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 		srcHash = "multi:" + hex.EncodeToString(hasher.Sum(nil))
 		origPaths = strings.Join(origs, " ")
 	}
+	// Files of an image are not hashed, but they are identified by the image
+	if src.imageID != "" {
+		srcHash = "from:" + src.imageID + ":" + strings.Join(args[0:len(args)-1], " ")
+	}
 
 	cmd := b.runConfig.Cmd
 	if runtime.GOOS != "windows" {
@@ -325,7 +337,7 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 	return &builder.HashedFileInfo{FileInfo: builder.PathFileInfo{FileInfo: tmpFileSt, FilePath: tmpFileName}, FileHash: hash}, nil
 }
 
-func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
+func (b *Builder) calcCopyInfo(context builder.Context, cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
 
 	// Work in daemon-specific OS filepath semantics
 	origPath = filepath.FromSlash(origPath)
@@ -338,7 +350,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	// Deal with wildcards
 	if allowWildcards && containsWildcards(origPath) {
 		var copyInfos []copyInfo
-		if err := b.context.Walk("", func(path string, info builder.FileInfo, err error) error {
+		if err := context.Walk("", func(path string, info builder.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -352,7 +364,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 			// Note we set allowWildcards to false in case the name has
 			// a * in it
-			subInfos, err := b.calcCopyInfo(cmdName, path, allowLocalDecompression, false)
+			subInfos, err := b.calcCopyInfo(context, cmdName, path, allowLocalDecompression, false)
 			if err != nil {
 				return err
 			}
@@ -366,7 +378,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 	// Must be a dir or a file
 
-	fi, err := b.context.Stat(origPath)
+	fi, err := context.Stat(origPath)
 	if err != nil {
 		return nil, err
 	}
@@ -387,7 +399,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	// Must be a dir
 
 	var subfiles []string
-	b.context.Walk(origPath, func(path string, info builder.FileInfo, err error) error {
+	context.Walk(origPath, func(path string, info builder.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -418,6 +430,9 @@ func containsWildcards(name string) bool {
 
 func (b *Builder) processImageFrom(img *image.Image) error {
 	b.image = img.ID
+	if b.currentStage != nil {
+		b.currentStage.base = img.ID
+	}
 
 	if img.Config != nil {
 		b.runConfig = img.Config
@@ -476,7 +491,16 @@ func (b *Builder) probeCache() (bool, error) {
 	if !ok || !b.UseCache || b.cacheBusted {
 		return false, nil
 	}
-	cache, err := c.GetCachedImage(b.image, b.runConfig)
+	var (
+		cache string
+		err   error
+	)
+	// Only the history of the cache sources is used when they are given
+	if len(b.CacheFrom) > 0 {
+		cache, err = c.GetCachedImageFrom(b.image, b.runConfig, b.cacheSources)
+	} else {
+		cache, err = c.GetCachedImage(b.image, b.runConfig)
+	}
 	if err != nil {
 		return false, err
 	}
@@ -497,6 +521,22 @@ func (b *Builder) probeCache() (bool, error) {
 	return true, nil
 }
 
+// resolveCacheSources resolves the CacheFrom images to the IDs used by
+// probeCache. The images which are not found are skipped with a warning.
+func (b *Builder) resolveCacheSources() {
+	if _, ok := b.docker.(builder.ImageCache); !ok || !b.UseCache {
+		return
+	}
+	for _, name := range b.CacheFrom {
+		img, err := b.docker.LookupImage(name)
+		if err != nil {
+			fmt.Fprintf(b.Stdout, "Could not find cache source %s, skipping\n", name)
+			continue
+		}
+		b.cacheSources = append(b.cacheSources, img.ID)
+	}
+}
+
 func (b *Builder) create() (*daemon.Container, error) {
 	if b.image == "" && !b.noBaseImage {
 		return nil, fmt.Errorf("Please provide a source image with `from` prior to run")
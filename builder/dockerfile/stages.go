@@ -0,0 +1,352 @@
+package dockerfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/builder"
+	"github.com/docker/docker/builder/dockerfile/command"
+	"github.com/docker/docker/builder/dockerfile/parser"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/runconfig"
+)
+
+var validStageName = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// buildStage is a part of a Dockerfile starting with a FROM instruction.
+// The image of a stage can be used as the base image of a later stage, or
+// as the source of a COPY --from instruction.
+type buildStage struct {
+	index int
+	name  string
+	// step is the index of the first instruction of the stage in the
+	// Dockerfile.
+	step  int
+	nodes []*parser.Node
+	// deps are the indexes of the earlier stages the stage uses.
+	deps []int
+	// image is the ID of the image built by the stage.
+	image string
+	// base is the ID of the image the stage is built FROM, empty for
+	// scratch.
+	base string
+}
+
+// String returns the name of the stage, or its index if it is unnamed.
+func (s *buildStage) String() string {
+	if s.name != "" {
+		return s.name
+	}
+	return strconv.Itoa(s.index)
+}
+
+// splitStages splits the instructions of a Dockerfile into build stages.
+// Any instruction before the first FROM is part of the first stage.
+func splitStages(dockerfile *parser.Node) ([]*buildStage, error) {
+	var stages []*buildStage
+	for i, n := range dockerfile.Children {
+		if n.Value == command.From || len(stages) == 0 {
+			stages = append(stages, &buildStage{index: len(stages), step: i})
+		}
+		s := stages[len(stages)-1]
+		s.nodes = append(s.nodes, n)
+
+		switch n.Value {
+		case command.From:
+			base, name, err := parseFrom(nodeArgs(n))
+			if err != nil {
+				return nil, err
+			}
+			if name != "" {
+				if dep := findStage(stages[:s.index], name); dep != nil {
+					return nil, fmt.Errorf("duplicate name %s for build stages %d and %d", name, dep.index, s.index)
+				}
+				s.name = name
+			}
+			if dep := findNamedStage(stages[:s.index], base); dep != nil {
+				s.deps = append(s.deps, dep.index)
+			}
+		case command.Copy:
+			from := copyFromFlag(n)
+			if from == "" {
+				break
+			}
+			if dep := findStage(stages[:s.index], from); dep != nil {
+				s.deps = append(s.deps, dep.index)
+			}
+		}
+	}
+	return stages, nil
+}
+
+// nodeArgs returns the arguments of an instruction as they were written.
+func nodeArgs(n *parser.Node) []string {
+	var args []string
+	for n = n.Next; n != nil; n = n.Next {
+		args = append(args, n.Value)
+	}
+	return args
+}
+
+// parseFrom returns the base image and the name of the stage of the
+// arguments of a FROM instruction: `FROM image [AS name]`.
+func parseFrom(args []string) (string, string, error) {
+	switch {
+	case len(args) == 1:
+		return args[0], "", nil
+	case len(args) == 3 && strings.EqualFold(args[1], "as"):
+		name := strings.ToLower(args[2])
+		if !validStageName.MatchString(name) {
+			return "", "", fmt.Errorf("invalid name for build stage: %q, name can't start with a number or contain symbols", args[2])
+		}
+		return args[0], name, nil
+	}
+	return "", "", fmt.Errorf("FROM requires either one or three arguments")
+}
+
+// copyFromFlag returns the value of the --from flag of a COPY instruction.
+func copyFromFlag(n *parser.Node) string {
+	for _, f := range n.Flags {
+		if strings.HasPrefix(f, "--from=") {
+			return strings.TrimPrefix(f, "--from=")
+		}
+	}
+	return ""
+}
+
+// findStage returns the stage with the given name or index, or nil.
+func findStage(stages []*buildStage, nameOrIndex string) *buildStage {
+	if i, err := strconv.Atoi(nameOrIndex); err == nil {
+		if i >= 0 && i < len(stages) {
+			return stages[i]
+		}
+		return nil
+	}
+	return findNamedStage(stages, nameOrIndex)
+}
+
+// findNamedStage returns the stage with the given name, or nil.
+func findNamedStage(stages []*buildStage, name string) *buildStage {
+	name = strings.ToLower(name)
+	for _, s := range stages {
+		if s.name != "" && s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// targetStages returns the stages needed to build the stage named target,
+// or the last stage if target is empty, in the order they are built.
+func targetStages(stages []*buildStage, target string) ([]*buildStage, error) {
+	last := stages[len(stages)-1]
+	if target != "" {
+		if last = findNamedStage(stages, target); last == nil {
+			return nil, fmt.Errorf("failed to reach build target %s in Dockerfile", target)
+		}
+	}
+
+	needed := map[int]bool{last.index: true}
+	for i := last.index; i >= 0; i-- {
+		if !needed[i] {
+			continue
+		}
+		for _, dep := range stages[i].deps {
+			needed[dep] = true
+		}
+	}
+
+	var built []*buildStage
+	for _, s := range stages[:last.index+1] {
+		if needed[s.index] {
+			built = append(built, s)
+		}
+	}
+	return built, nil
+}
+
+// resetStage resets the state of the builder at the start of a new stage.
+func (b *Builder) resetStage() {
+	b.image = ""
+	b.noBaseImage = false
+	b.maintainer = ""
+	b.cmdSet = false
+	b.cacheBusted = false
+	b.runConfig = new(runconfig.Config)
+}
+
+// previousStages returns the stages built before the current one.
+func (b *Builder) previousStages() []*buildStage {
+	if b.currentStage == nil {
+		return nil
+	}
+	return b.stages[:b.currentStage.index]
+}
+
+// skipStages records the build-time args declared in the stages which are
+// not built, so that passing them is not an error.
+func (b *Builder) skipStages(built []*buildStage) {
+	isBuilt := make(map[int]bool, len(built))
+	for _, s := range built {
+		isBuilt[s.index] = true
+	}
+	for _, s := range b.stages {
+		if isBuilt[s.index] {
+			continue
+		}
+		fmt.Fprintf(b.Stdout, "Skipping stage %s\n", s)
+		for _, n := range s.nodes {
+			if n.Value != command.Arg || n.Next == nil {
+				continue
+			}
+			b.skippedBuildArgs[strings.SplitN(n.Next.Value, "=", 2)[0]] = true
+		}
+	}
+}
+
+// stageImage returns the ID of the image built by the stage s, or an error if
+// the stage produced no image, as a stage with only FROM scratch does.
+func stageImage(s *buildStage) (string, error) {
+	if s.image == "" {
+		return "", fmt.Errorf("build stage %s produced no image", s)
+	}
+	return s.image, nil
+}
+
+// lookupImage returns the image with the given name, pulling it if it is
+// not present or if the build always pulls images.
+func (b *Builder) lookupImage(name string) (*image.Image, error) {
+	var img *image.Image
+	// TODO: don't use `name`, instead resolve it to a digest
+	if !b.Pull {
+		img, _ = b.docker.LookupImage(name)
+		// TODO: shouldn't we error out if error is different from "not found" ?
+	}
+	if img == nil {
+		return b.docker.Pull(name)
+	}
+	return img, nil
+}
+
+// imageSource returns the source of a COPY --from instruction: the root
+// filesystem of the image of an earlier stage, named or given by its index,
+// or of any other image. The returned function releases the source.
+func (b *Builder) imageSource(from string) (*copySource, func(), error) {
+	var imgID string
+	if s := findStage(b.previousStages(), from); s != nil {
+		var err error
+		if imgID, err = stageImage(s); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if _, err := strconv.Atoi(from); err == nil {
+			return nil, nil, fmt.Errorf("invalid build stage index %s for COPY --from", from)
+		}
+		img, err := b.lookupImage(from)
+		if err != nil {
+			return nil, nil, err
+		}
+		imgID = img.ID
+	}
+
+	config := &runconfig.Config{Image: imgID}
+	if runtime.GOOS != "windows" {
+		config.Cmd = stringutils.NewStrSlice("/bin/sh", "-c", "#(nop) COPY --from="+from)
+	} else {
+		config.Cmd = stringutils.NewStrSlice("cmd", "/S /C", "REM (nop) COPY --from="+from)
+	}
+	container, _, err := b.docker.Create(config, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		container.Unmount()
+		b.removeContainer(container.ID)
+	}
+
+	if err := container.Mount(); err != nil {
+		b.removeContainer(container.ID)
+		return nil, nil, err
+	}
+	root, err := container.GetResourcePath("/")
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	src := &copySource{
+		context: &rootfsContext{root: root},
+		imageID: imgID,
+	}
+	return src, release, nil
+}
+
+// rootfsContext is a read-only builder.Context over the root filesystem of
+// a container, from which COPY --from copies files.
+type rootfsContext struct {
+	root string
+}
+
+func (c *rootfsContext) Close() error {
+	return nil
+}
+
+func (c *rootfsContext) resolve(path string) (string, error) {
+	fullpath, err := symlink.FollowSymlinkInScope(filepath.Join(c.root, path), c.root)
+	if err != nil {
+		return "", fmt.Errorf("Forbidden path outside the image root filesystem: %s (%s)", path, fullpath)
+	}
+	return fullpath, nil
+}
+
+func (c *rootfsContext) Stat(path string) (builder.FileInfo, error) {
+	fullpath, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Lstat(fullpath)
+	if err != nil {
+		if perr, ok := err.(*os.PathError); ok {
+			perr.Path = path
+		}
+		return nil, err
+	}
+	return builder.PathFileInfo{FileInfo: st, FilePath: fullpath}, nil
+}
+
+func (c *rootfsContext) Open(path string) (io.ReadCloser, error) {
+	fullpath, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fullpath)
+}
+
+func (c *rootfsContext) Walk(root string, walkFn builder.WalkFunc) error {
+	fullroot, err := c.resolve(root)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(fullroot, func(fullpath string, info os.FileInfo, err error) error {
+		path, relErr := filepath.Rel(c.root, fullpath)
+		if relErr != nil {
+			return relErr
+		}
+		// Like for the build context, the root itself is not walked
+		if fullpath == fullroot {
+			return err
+		}
+		if err != nil {
+			return walkFn(path, nil, err)
+		}
+		return walkFn(path, builder.PathFileInfo{FileInfo: info, FilePath: fullpath}, nil)
+	})
+}
@@ -0,0 +1,131 @@
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/builder/dockerfile/parser"
+)
+
+func parseStages(t *testing.T, dockerfile string) []*buildStage {
+	node, err := parser.Parse(strings.NewReader(dockerfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stages, err := splitStages(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stages
+}
+
+func TestSplitStages(t *testing.T) {
+	stages := parseStages(t, `
+FROM busybox AS Base
+RUN echo base
+FROM golang:1.5 AS build
+COPY --from=base /bin/echo /echo
+FROM base
+COPY --from=1 /go /go
+FROM scratch
+`)
+	if len(stages) != 4 {
+		t.Fatalf("Expected 4 stages, got %d", len(stages))
+	}
+
+	expected := []struct {
+		name string
+		step int
+		deps []int
+	}{
+		{"base", 0, nil},
+		{"build", 2, []int{0}},
+		{"", 4, []int{0, 1}},
+		{"", 6, nil},
+	}
+	for i, e := range expected {
+		s := stages[i]
+		if s.index != i || s.name != e.name || s.step != e.step {
+			t.Fatalf("Expected stage %d to be named %q at step %d, got %q at step %d", i, e.name, e.step, s.name, s.step)
+		}
+		if len(s.deps) != len(e.deps) {
+			t.Fatalf("Expected stage %d to depend on %v, got %v", i, e.deps, s.deps)
+		}
+		for j := range e.deps {
+			if s.deps[j] != e.deps[j] {
+				t.Fatalf("Expected stage %d to depend on %v, got %v", i, e.deps, s.deps)
+			}
+		}
+	}
+}
+
+func TestSplitStagesInvalid(t *testing.T) {
+	invalid := []string{
+		"FROM busybox AS",
+		"FROM busybox FOR base",
+		"FROM busybox AS 1base",
+		"FROM busybox AS base\nFROM busybox AS base",
+	}
+	for _, dockerfile := range invalid {
+		node, err := parser.Parse(strings.NewReader(dockerfile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := splitStages(node); err == nil {
+			t.Fatalf("Expected an error for Dockerfile %q", dockerfile)
+		}
+	}
+}
+
+func TestTargetStages(t *testing.T) {
+	stages := parseStages(t, `
+FROM busybox AS base
+FROM busybox AS unused
+FROM golang:1.5 AS build
+COPY --from=base /bin/echo /echo
+FROM build AS test
+FROM busybox
+COPY --from=build /go /go
+`)
+
+	built, err := targetStages(stages, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStages(t, built, 0, 2, 4)
+
+	built, err = targetStages(stages, "TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStages(t, built, 0, 2, 3)
+
+	if _, err := targetStages(stages, "missing"); err == nil {
+		t.Fatal("Expected an error for a missing target")
+	}
+}
+
+func assertStages(t *testing.T, stages []*buildStage, indexes ...int) {
+	if len(stages) != len(indexes) {
+		t.Fatalf("Expected stages %v, got %d stages", indexes, len(stages))
+	}
+	for i, s := range stages {
+		if s.index != indexes[i] {
+			t.Fatalf("Expected stages %v, got stage %d at position %d", indexes, s.index, i)
+		}
+	}
+}
+
+func TestStageImage(t *testing.T) {
+	if _, err := stageImage(&buildStage{index: 0, name: "empty"}); err == nil || !strings.Contains(err.Error(), "build stage empty produced no image") {
+		t.Fatalf("Expected an error for a stage without an image, got %v", err)
+	}
+
+	id, err := stageImage(&buildStage{index: 0, image: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "abc" {
+		t.Fatalf("Expected image abc, got %s", id)
+	}
+}
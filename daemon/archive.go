@@ -68,6 +68,9 @@ func (daemon *Daemon) ContainerExtractToDir(name, path string, noOverwriteDirNon
 		return err
 	}
 
+	// The size of the writable layer changes, even if the extraction fails
+	// midway
+	defer daemon.diskUsage.forgetContainer(container.ID)
 	return container.ExtractToDir(path, noOverwriteDirNonDir, content)
 }
 
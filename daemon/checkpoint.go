@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+)
+
+// checkpointsDir is the directory of the checkpoints in the root of a
+// container.
+const checkpointsDir = "checkpoints"
+
+// CheckpointCreate saves the state of the processes of a running container
+// in a new checkpoint. The container is stopped once checkpointed, unless
+// config.Exit is false.
+func (daemon *Daemon) CheckpointCreate(name string, config types.CheckpointCreateOptions) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+	checkpointer, err := daemon.checkpointer()
+	if err != nil {
+		return err
+	}
+	if !validContainerNamePattern.MatchString(config.CheckpointID) {
+		return derr.ErrorCodeInvalidCheckpointName.WithArgs(config.CheckpointID, validContainerNameChars)
+	}
+
+	container.Lock()
+	defer container.Unlock()
+	if !container.Running {
+		return derr.ErrorCodeNotRunning.WithArgs(name)
+	}
+	if err := canCheckpoint(container); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(container.root, checkpointsDir, config.CheckpointID)
+	if _, err := os.Stat(dir); err == nil {
+		return derr.ErrorCodeCheckpointExists.WithArgs(config.CheckpointID, name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if err := checkpointer.Checkpoint(container.command, dir, !config.Exit); err != nil {
+		os.RemoveAll(dir)
+		return derr.ErrorCodeCantCheckpoint.WithArgs(name, err)
+	}
+	// The container is not restarted when its processes are killed once
+	// checkpointed. The monitor takes the lock of the container before
+	// checking its restart policy, so it does not see the processes exit
+	// before this.
+	if config.Exit {
+		container.monitor.ExitOnNext()
+	}
+	container.logEvent("checkpoint")
+	return nil
+}
+
+// CheckpointList returns the checkpoints of a container.
+func (daemon *Daemon) CheckpointList(name string) ([]types.Checkpoint, error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := []types.Checkpoint{}
+	dirs, err := ioutil.ReadDir(filepath.Join(container.root, checkpointsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoints, nil
+		}
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if dir.IsDir() {
+			checkpoints = append(checkpoints, types.Checkpoint{Name: dir.Name()})
+		}
+	}
+	return checkpoints, nil
+}
+
+// CheckpointDelete removes a checkpoint of a container.
+func (daemon *Daemon) CheckpointDelete(name, checkpoint string) error {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+	dir, err := container.checkpointDir(checkpoint)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// checkpointDir returns the directory of an existing checkpoint of the
+// container.
+func (container *Container) checkpointDir(checkpoint string) (string, error) {
+	if !validContainerNamePattern.MatchString(checkpoint) {
+		return "", derr.ErrorCodeInvalidCheckpointName.WithArgs(checkpoint, validContainerNameChars)
+	}
+	dir := filepath.Join(container.root, checkpointsDir, checkpoint)
+	if _, err := os.Stat(dir); err != nil {
+		return "", derr.ErrorCodeNoSuchCheckpoint.WithArgs(checkpoint, container.Name[1:])
+	}
+	return dir, nil
+}
+
+// checkpointer returns the execution driver, if it supports checkpoints.
+func (daemon *Daemon) checkpointer() (execdriver.Checkpointer, error) {
+	checkpointer, ok := daemon.execDriver.(execdriver.Checkpointer)
+	if !ok {
+		return nil, derr.ErrorCodeCheckpointNotSupported.WithArgs(daemon.execDriver.Name())
+	}
+	return checkpointer, nil
+}
+
+// canCheckpoint returns an error if the processes of the container cannot be
+// checkpointed, or restored.
+func canCheckpoint(container *Container) error {
+	switch c := container.command; {
+	case container.Paused:
+		return derr.ErrorCodeCantCheckpoint.WithArgs(container.Name[1:], "the container is paused")
+	case c.ProcessConfig.Tty:
+		return derr.ErrorCodeCantCheckpoint.WithArgs(container.Name[1:], "the container has a TTY")
+	case c.Network != nil && c.Network.ContainerID != "", c.Ipc != nil && c.Ipc.ContainerID != "":
+		return derr.ErrorCodeCantCheckpoint.WithArgs(container.Name[1:], "the container shares the namespaces of another container")
+	}
+	return nil
+}
+
+// runCheckpoint runs a container whose processes are restored from the
+// checkpoint in dir.
+func (daemon *Daemon) runCheckpoint(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback, dir string) (execdriver.ExitStatus, error) {
+	checkpointer, err := daemon.checkpointer()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	hooks := execdriver.Hooks{
+		Start: startCallback,
+	}
+	hooks.PreStart = append(hooks.PreStart, func(processConfig *execdriver.ProcessConfig, pid int, chOOM <-chan struct{}) error {
+		return c.setNetworkNamespaceKey(pid)
+	})
+	return checkpointer.RunCheckpoint(c.command, pipes, hooks, dir)
+}
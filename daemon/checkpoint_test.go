@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/docker/runconfig"
+)
+
+type failingCheckpointer struct {
+	execdriver.Driver
+}
+
+func (d *failingCheckpointer) Checkpoint(c *execdriver.Command, dir string, leaveRunning bool) error {
+	return errors.New("criu failed")
+}
+
+func (d *failingCheckpointer) RunCheckpoint(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, dir string) (execdriver.ExitStatus, error) {
+	return execdriver.ExitStatus{ExitCode: -1}, errors.New("criu failed")
+}
+
+func TestCheckpointCreateFailureKeepsRestartPolicy(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-daemon-checkpoint-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	c := &Container{
+		CommonContainer: CommonContainer{
+			ID:      "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			Name:    "/checkpointed",
+			State:   NewState(),
+			root:    tmp,
+			command: &execdriver.Command{},
+		},
+	}
+	c.Running = true
+	c.monitor = newContainerMonitor(c, runconfig.RestartPolicy{Name: "always"})
+
+	index := truncindex.NewTruncIndex([]string{})
+	index.Add(c.ID)
+	daemon := &Daemon{
+		containers: &contStore{s: map[string]*Container{c.ID: c}},
+		idIndex:    index,
+		execDriver: &failingCheckpointer{},
+	}
+
+	if err := daemon.CheckpointCreate(c.ID, types.CheckpointCreateOptions{CheckpointID: "checkpoint1", Exit: true}); err == nil {
+		t.Fatal("Expected an error when the checkpoint fails")
+	}
+	if c.monitor.shouldStop {
+		t.Fatal("Expected the container to still be restarted after a failed checkpoint")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, checkpointsDir, "checkpoint1")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the directory of the failed checkpoint to be removed, got %v", err)
+	}
+}
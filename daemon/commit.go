@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/runconfig"
 )
@@ -14,6 +15,11 @@ type ContainerCommitConfig struct {
 	Author  string
 	Comment string
 	Config  *runconfig.Config
+	// Squash is true to squash the layers of the image since the image
+	// SquashBase into a single layer, or all of its layers if SquashBase
+	// is empty.
+	Squash     bool
+	SquashBase string
 }
 
 // Commit creates a new filesystem image from the current state of a container.
@@ -40,6 +46,19 @@ func (daemon *Daemon) Commit(container *Container, c *ContainerCommitConfig) (*i
 		return nil, err
 	}
 
+	if c.Squash {
+		squashed, err := daemon.graph.Squash(img.ID, c.SquashBase)
+		// The image of the container's layer is only used to compute
+		// the squashed layer
+		if err := daemon.graph.Delete(img.ID); err != nil {
+			logrus.Errorf("Error removing the image %s after squashing it: %v", img.ID, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		img = squashed
+	}
+
 	// Register the image if needed
 	if c.Repo != "" {
 		if err := daemon.repositories.Tag(c.Repo, c.Tag, img.ID, true); err != nil {
@@ -49,3 +68,10 @@ func (daemon *Daemon) Commit(container *Container, c *ContainerCommitConfig) (*i
 	container.logEvent("commit")
 	return img, nil
 }
+
+// SquashImage creates an image with the configuration of the image id, and a
+// single layer holding the changes of its layers since the image base. base
+// may be "" to squash all the layers of id.
+func (daemon *Daemon) SquashImage(id, base string) (*image.Image, error) {
+	return daemon.graph.Squash(id, base)
+}
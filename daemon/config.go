@@ -1,6 +1,14 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/runconfig"
@@ -9,11 +17,23 @@ import (
 const (
 	defaultNetworkMtu    = 1500
 	disableNetworkBridge = "none"
+
+	// defaultMaxConcurrentDownloads and defaultMaxConcurrentUploads are the
+	// default number of layers transferred at the same time by the daemon.
+	defaultMaxConcurrentDownloads = 3
+	defaultMaxConcurrentUploads   = 5
+
+	// defaultMaxDownloadAttempts is the default number of attempts to
+	// download a layer, and defaultDownloadRetryDelay the default delay
+	// before the first retry.
+	defaultMaxDownloadAttempts = 5
+	defaultDownloadRetryDelay  = time.Second
 )
 
 // CommonConfig defines the configuration of a docker daemon which are
 // common across platforms.
 type CommonConfig struct {
+	AuthZPlugins   []string // AuthZPlugins holds list of authorization plugins
 	AutoRestart    bool
 	Bridge         bridgeConfig // Bridge holds bridge network specific configuration.
 	Context        map[string][]string
@@ -28,6 +48,7 @@ type CommonConfig struct {
 	GraphOptions   []string
 	Labels         []string
 	LogConfig      runconfig.LogConfig
+	MetricsAddress string // MetricsAddress is the address serving the Prometheus metrics of the daemon.
 	Mtu            int
 	Pidfile        string
 	RemappedRoot   string
@@ -35,6 +56,33 @@ type CommonConfig struct {
 	TrustKeyPath   string
 	DefaultNetwork string
 
+	// TrustPolicy is the path of the content trust policy of the daemon,
+	// which lists the registries whose tags are verified against the trust
+	// data of their repositories when they are pulled.
+	TrustPolicy string
+
+	// MaxConcurrentDownloads and MaxConcurrentUploads are the maximum
+	// number of layers downloaded and uploaded at the same time by all the
+	// pulls and pushes of the daemon.
+	MaxConcurrentDownloads int
+	MaxConcurrentUploads   int
+
+	// MaxDownloadAttempts is the number of attempts to download a layer
+	// before a pull fails, and DownloadRetryDelay the delay before the
+	// first retry, doubled after each retry.
+	MaxDownloadAttempts int
+	DownloadRetryDelay  time.Duration
+
+	// LiveRestore keeps the containers running while the daemon is stopped,
+	// and restores them when it starts again.
+	LiveRestore bool
+
+	// EventsRetentionSize is the maximum number of events kept in the
+	// events journal, and EventsRetentionAge their maximum age. Zero means
+	// no limit.
+	EventsRetentionSize int
+	EventsRetentionAge  time.Duration
+
 	// ClusterStore is the storage backend used for the cluster information. It is used by both
 	// multihost networking (to store networks and endpoints information) and by the node discovery
 	// mechanism.
@@ -56,6 +104,7 @@ type CommonConfig struct {
 // from the command-line.
 func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string) string) {
 	cmd.Var(opts.NewListOptsRef(&config.GraphOptions, nil), []string{"-storage-opt"}, usageFn("Set storage driver options"))
+	cmd.Var(opts.NewListOptsRef(&config.AuthZPlugins, nil), []string{"-authorization-plugin"}, usageFn("List authorization plugins in order from first evaluator"))
 	cmd.Var(opts.NewListOptsRef(&config.ExecOptions, nil), []string{"-exec-opt"}, usageFn("Set exec driver options"))
 	cmd.StringVar(&config.Pidfile, []string{"p", "-pidfile"}, defaultPidFile, usageFn("Path to use for daemon PID file"))
 	cmd.StringVar(&config.Root, []string{"g", "-graph"}, defaultGraph, usageFn("Root of the Docker runtime"))
@@ -71,7 +120,149 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.Var(opts.NewListOptsRef(&config.Labels, opts.ValidateLabel), []string{"-label"}, usageFn("Set key=value labels to the daemon"))
 	cmd.StringVar(&config.LogConfig.Type, []string{"-log-driver"}, "json-file", usageFn("Default driver for container logs"))
 	cmd.Var(opts.NewMapOpts(config.LogConfig.Config, nil), []string{"-log-opt"}, usageFn("Set log driver options"))
+	cmd.IntVar(&config.EventsRetentionSize, []string{"-events-retention-size"}, 100000, usageFn("Maximum number of events kept in the events journal"))
+	cmd.DurationVar(&config.EventsRetentionAge, []string{"-events-retention-age"}, 0, usageFn("Maximum age of the events kept in the events journal"))
 	cmd.StringVar(&config.ClusterAdvertise, []string{"-cluster-advertise"}, "", usageFn("Address of the daemon instance to advertise"))
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
 	cmd.Var(opts.NewMapOpts(config.ClusterOpts, nil), []string{"-cluster-store-opt"}, usageFn("Set cluster store options"))
+	cmd.StringVar(&config.MetricsAddress, []string{"-metrics-addr"}, "", usageFn("Set address and port to serve the metrics api"))
+	cmd.StringVar(&config.TrustPolicy, []string{"-content-trust-policy"}, "", usageFn("Path to the content trust policy of the registries"))
+	cmd.IntVar(&config.MaxConcurrentDownloads, []string{"-max-concurrent-downloads"}, defaultMaxConcurrentDownloads, usageFn("Maximum number of layers downloaded at the same time"))
+	cmd.IntVar(&config.MaxConcurrentUploads, []string{"-max-concurrent-uploads"}, defaultMaxConcurrentUploads, usageFn("Maximum number of layers uploaded at the same time"))
+	cmd.IntVar(&config.MaxDownloadAttempts, []string{"-max-download-attempts"}, defaultMaxDownloadAttempts, usageFn("Maximum number of attempts to download a layer"))
+	cmd.DurationVar(&config.DownloadRetryDelay, []string{"-download-retry-delay"}, defaultDownloadRetryDelay, usageFn("Delay before retrying a failed layer download, doubled after each retry"))
+}
+
+// ReadConfigFile reads the JSON configuration file of the daemon and returns
+// the values of its options. The keys of the file are the long names of the
+// flags of the daemon, such as "label" or "storage-driver". Options which can
+// be given several times take an array, and key=value options an object.
+func ReadConfigFile(path string) (map[string][]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid configuration file: %v", err)
+	}
+
+	options := make(map[string][]string, len(raw))
+	for name, value := range raw {
+		values, err := configValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for option %s in the configuration file: %v", name, err)
+		}
+		options[name] = values
+	}
+	return options, nil
+}
+
+// configValues returns the values to set a flag to for the value of an
+// option of the configuration file.
+func configValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		var values []string
+		for _, e := range v {
+			s, err := configScalar(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var values []string
+		for _, k := range keys {
+			s, err := configScalar(v[k])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, k+"="+s)
+		}
+		return values, nil
+	}
+	s, err := configScalar(value)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+func configScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, json.Number:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// MergeConfigFile sets the flags of the daemon to the values of the options
+// of its configuration file. It fails if an option is unknown, or if it is
+// also set on the command line.
+func MergeConfigFile(flags *flag.FlagSet, options map[string][]string) error {
+	if err := findConfigConflicts(flags, options); err != nil {
+		return err
+	}
+	return setConfigOptions(flags, options, false)
+}
+
+// findConfigConflicts returns an error listing the options of the
+// configuration file which are also set as flags, if any.
+func findConfigConflicts(flags *flag.FlagSet, options map[string][]string) error {
+	var conflicts []string
+	flags.Visit(func(f *flag.Flag) {
+		for _, name := range f.Names {
+			name = strings.TrimPrefix(strings.TrimPrefix(name, "#"), "-")
+			if _, ok := options[name]; ok {
+				conflicts = append(conflicts, name)
+				return
+			}
+		}
+	})
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the following options are set both as a flag and in the configuration file: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// setConfigOptions sets the flags to the values of the options of a
+// configuration file. The unknown options are ignored if ignoreUnknown is
+// true.
+func setConfigOptions(flags *flag.FlagSet, options map[string][]string, ignoreUnknown bool) error {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := flags.Lookup("-" + name)
+		if f == nil || name == "config-file" {
+			if ignoreUnknown {
+				continue
+			}
+			return fmt.Errorf("unknown option %s in the configuration file", name)
+		}
+		// The value is set without marking the flag as set, so that only
+		// the flags of the command line conflict with the file when it is
+		// reloaded.
+		for _, value := range options[name] {
+			if err := f.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid value %q for option %s in the configuration file: %v", value, name, err)
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,175 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	flag "github.com/docker/docker/pkg/mflag"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"label": ["a=1", "b=2"],
+		"log-opt": {"max-size": "10m", "max-file": 3},
+		"debug": true,
+		"mtu": 1450,
+		"storage-driver": "vfs"
+	}`)
+	defer os.Remove(path)
+
+	options, err := ReadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"label":          {"a=1", "b=2"},
+		"log-opt":        {"max-file=3", "max-size=10m"},
+		"debug":          {"true"},
+		"mtu":            {"1450"},
+		"storage-driver": {"vfs"},
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Fatalf("Expected %v, got %v", expected, options)
+	}
+}
+
+func TestReadConfigFileInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"label": `,
+		`["label"]`,
+		`{"label": [["a=1"]]}`,
+		`{"log-opt": {"max-size": null}}`,
+	} {
+		path := writeConfigFile(t, content)
+		if _, err := ReadConfigFile(path); err == nil {
+			t.Fatalf("Expected an error reading %s", content)
+		}
+		os.Remove(path)
+	}
+}
+
+func newConfigFlags() (*Config, *flag.FlagSet) {
+	config := &Config{}
+	config.LogConfig.Config = make(map[string]string)
+	config.ClusterOpts = make(map[string]string)
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.InstallFlags(flags, func(string) string { return "" })
+	return config, flags
+}
+
+func TestMergeConfigFile(t *testing.T) {
+	config, flags := newConfigFlags()
+	if err := flags.Parse([]string{"--mtu", "1400"}); err != nil {
+		t.Fatal(err)
+	}
+
+	options := map[string][]string{
+		"label":   {"a=1", "b=2"},
+		"log-opt": {"max-size=10m"},
+		"graph":   {"/var/lib/test"},
+	}
+	if err := MergeConfigFile(flags, options); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Labels, []string{"a=1", "b=2"}) {
+		t.Fatalf("Expected the labels of the file, got %v", config.Labels)
+	}
+	if config.LogConfig.Config["max-size"] != "10m" {
+		t.Fatalf("Expected the log options of the file, got %v", config.LogConfig.Config)
+	}
+	if config.Root != "/var/lib/test" {
+		t.Fatalf("Expected the graph of the file, got %s", config.Root)
+	}
+	if config.Mtu != 1400 {
+		t.Fatalf("Expected the mtu of the command line, got %d", config.Mtu)
+	}
+	// The options of the file are not marked as set
+	if flags.IsSet("-label") {
+		t.Fatal("Expected the label flag not to be set")
+	}
+}
+
+func TestMergeConfigFileConflicts(t *testing.T) {
+	_, flags := newConfigFlags()
+	if err := flags.Parse([]string{"--label", "a=1", "-g", "/var/lib/test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	options := map[string][]string{
+		"label": {"b=2"},
+		"graph": {"/var/lib/other"},
+		"mtu":   {"1400"},
+	}
+	err := MergeConfigFile(flags, options)
+	if err == nil {
+		t.Fatal("Expected an error merging conflicting options")
+	}
+	for _, name := range []string{"label", "graph"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("Expected the conflict on %s to be reported, got %v", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "mtu") {
+		t.Fatalf("Expected no conflict on mtu, got %v", err)
+	}
+}
+
+func TestMergeConfigFileInvalidOptions(t *testing.T) {
+	for _, options := range []map[string][]string{
+		{"unknown": {"value"}},
+		{"config-file": {"/etc/docker/other.json"}},
+		{"mtu": {"large"}},
+	} {
+		_, flags := newConfigFlags()
+		if err := MergeConfigFile(flags, options); err == nil {
+			t.Fatalf("Expected an error merging %v", options)
+		}
+	}
+}
+
+func TestNewReloadConfig(t *testing.T) {
+	_, daemonFlags := newConfigFlags()
+	if err := daemonFlags.Parse([]string{"--mtu", "1400"}); err != nil {
+		t.Fatal(err)
+	}
+
+	options := map[string][]string{
+		"label":           {"a=1"},
+		"debug":           {"true"},
+		"registry-mirror": {"https://mirror.example.com"},
+		"tlsverify":       {"true"},
+	}
+	config, err := NewReloadConfig(options, daemonFlags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Labels, []string{"a=1"}) || !config.Debug {
+		t.Fatalf("Expected the labels and debug mode of the file, got %v and %v", config.Labels, config.Debug)
+	}
+	if !reflect.DeepEqual(config.Mirrors, []string{"https://mirror.example.com/"}) {
+		t.Fatalf("Expected the mirrors of the file, got %v", config.Mirrors)
+	}
+	if config.isSet("cluster-store-opt") {
+		t.Fatal("Expected the cluster store options not to be set")
+	}
+
+	options["mtu"] = []string{"1500"}
+	if _, err := NewReloadConfig(options, daemonFlags); err == nil {
+		t.Fatal("Expected an error reloading an option set as a flag")
+	}
+}
@@ -175,12 +175,7 @@ func (container *Container) writeHostConfig() error {
 }
 
 func (container *Container) logEvent(action string) {
-	d := container.daemon
-	d.EventsService.Log(
-		action,
-		container.ID,
-		container.Config.Image,
-	)
+	container.daemon.LogContainerEvent(container, action)
 }
 
 // GetResourcePath evaluates `path` in the scope of the container's basefs, with proper path
@@ -242,7 +237,13 @@ func (container *Container) exportContainerRw() (archive.Archive, error) {
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
 // begin running.
-func (container *Container) Start() (err error) {
+func (container *Container) Start() error {
+	return container.start("")
+}
+
+// start starts the container, restoring its processes from the checkpoint
+// in checkpointDir if it is not empty.
+func (container *Container) start(checkpointDir string) (err error) {
 	container.Lock()
 	defer container.Unlock()
 
@@ -305,7 +306,7 @@ func (container *Container) Start() (err error) {
 	mounts = append(mounts, container.ipcMounts()...)
 
 	container.command.Mounts = mounts
-	return container.waitForStart()
+	return container.waitForStart(checkpointDir)
 }
 
 // streamConfig.StdinPipe returns a WriteCloser which can be used to feed data
@@ -552,14 +553,14 @@ func (container *Container) Resize(h, w int) error {
 	return nil
 }
 
-func (container *Container) export() (archive.Archive, error) {
+func (container *Container) export(compression archive.Compression) (archive.Archive, error) {
 	if err := container.Mount(); err != nil {
 		return nil, err
 	}
 
 	uidMaps, gidMaps := container.daemon.GetUIDGIDMaps()
 	archive, err := archive.TarWithOptions(container.basefs, &archive.TarOptions{
-		Compression: archive.Uncompressed,
+		Compression: compression,
 		UIDMaps:     uidMaps,
 		GIDMaps:     gidMaps,
 	})
@@ -701,7 +702,7 @@ func (container *Container) getLogConfig() runconfig.LogConfig {
 		return cfg
 	}
 	// Use daemon's default log config for containers
-	return container.daemon.defaultLogConfig
+	return container.daemon.getDefaultLogConfig()
 }
 
 func (container *Container) getLogger() (logger.Logger, error) {
@@ -763,8 +764,9 @@ func (container *Container) startLogging() error {
 	return nil
 }
 
-func (container *Container) waitForStart() error {
+func (container *Container) waitForStart(checkpointDir string) error {
 	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	container.monitor.checkpointDir = checkpointDir
 
 	// block until we either receive an error from the initial start of the container's
 	// process or until the process is running in the container
@@ -814,6 +816,9 @@ func (container *Container) exec(ec *ExecConfig) error {
 				c.Close()
 			}
 		}
+		ec.Lock()
+		ec.pid = pid
+		ec.Unlock()
 		close(ec.waitStart)
 		return nil
 	}
@@ -1179,6 +1184,11 @@ func (container *Container) unmountVolumes(forceSyscall bool) error {
 			if err := volumeMount.Volume.Unmount(); err != nil {
 				return err
 			}
+			attributes := map[string]string{
+				"driver":    volumeMount.Volume.DriverName(),
+				"container": container.ID,
+			}
+			container.daemon.LogVolumeEvent(volumeMount.Volume.Name(), "unmount", attributes)
 		}
 	}
 
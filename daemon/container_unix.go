@@ -22,6 +22,7 @@ import (
 	"github.com/docker/docker/pkg/directory"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/nat"
+	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
@@ -50,7 +51,11 @@ type Container struct {
 	CommonContainer
 
 	// Fields below here are platform specific.
-	activeLinks     map[string]*links.Link
+	activeLinks map[string]*links.Link
+	// The addresses a restored container is given again in its networks,
+	// by network name
+	restoredAddresses map[string]*network.EndpointIPAMConfig
+
 	AppArmorProfile string
 	HostnamePath    string
 	HostsPath       string
@@ -278,6 +283,27 @@ func populateCommand(c *Container, env []string) error {
 		rlimits = append(rlimits, rl)
 	}
 
+	weightDevices, err := getBlkioWeightDevices(c.hostConfig)
+	if err != nil {
+		return err
+	}
+	readBpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceReadBps)
+	if err != nil {
+		return err
+	}
+	writeBpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceWriteBps)
+	if err != nil {
+		return err
+	}
+	readIOpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceReadIOps)
+	if err != nil {
+		return err
+	}
+	writeIOpsDevices, err := getBlkioThrottleDevices(c.hostConfig.BlkioDeviceWriteIOps)
+	if err != nil {
+		return err
+	}
+
 	resources := &execdriver.Resources{
 		Memory:            c.hostConfig.Memory,
 		MemorySwap:        c.hostConfig.MemorySwap,
@@ -292,6 +318,13 @@ func populateCommand(c *Container, env []string) error {
 		Rlimits:           rlimits,
 		OomKillDisable:    c.hostConfig.OomKillDisable,
 		MemorySwappiness:  -1,
+
+		BlkioWeightDevice:            weightDevices,
+		BlkioThrottleReadBpsDevice:   readBpsDevices,
+		BlkioThrottleWriteBpsDevice:  writeBpsDevices,
+		BlkioThrottleReadIOpsDevice:  readIOpsDevices,
+		BlkioThrottleWriteIOpsDevice: writeIOpsDevices,
+		PidsLimit:                    c.hostConfig.PidsLimit,
 	}
 
 	if c.hostConfig.MemorySwappiness != nil {
@@ -342,6 +375,9 @@ func populateCommand(c *Container, env []string) error {
 		LxcConfig:          lxcConfig,
 		AppArmorProfile:    c.AppArmorProfile,
 		CgroupParent:       c.hostConfig.CgroupParent,
+		// The output of a container with a TTY cannot be kept while the
+		// daemon is stopped, which is why it is never restored.
+		LiveRestore: c.daemon.configStore.LiveRestore && !c.Config.Tty,
 	}
 
 	return nil
@@ -637,7 +673,7 @@ func (container *Container) buildPortMapInfo(ep libnetwork.Endpoint, networkSett
 	return networkSettings, nil
 }
 
-func (container *Container) buildEndpointInfo(ep libnetwork.Endpoint, networkSettings *network.Settings) (*network.Settings, error) {
+func (container *Container) buildEndpointInfo(n libnetwork.Network, ep libnetwork.Endpoint, networkSettings *network.Settings) (*network.Settings, error) {
 	if ep == nil {
 		return nil, derr.ErrorCodeEmptyEndpoint
 	}
@@ -646,6 +682,11 @@ func (container *Container) buildEndpointInfo(ep libnetwork.Endpoint, networkSet
 		return nil, derr.ErrorCodeEmptyNetwork
 	}
 
+	epSettings := networkSettings.Networks[n.Name()]
+	epSettings.NetworkID = n.ID()
+	epSettings.EndpointID = ep.ID()
+	epSettings.MacAddress = networkSettings.MacAddress
+
 	epInfo := ep.Info()
 	if epInfo == nil {
 		// It is not an error to get an empty endpoint info
@@ -661,39 +702,49 @@ func (container *Container) buildEndpointInfo(ep libnetwork.Endpoint, networkSet
 		ones, _ := iface.Address().Mask.Size()
 		networkSettings.IPAddress = iface.Address().IP.String()
 		networkSettings.IPPrefixLen = ones
+		epSettings.IPAddress = networkSettings.IPAddress
+		epSettings.IPPrefixLen = ones
 	}
 
 	if iface.AddressIPv6() != nil && iface.AddressIPv6().IP.To16() != nil {
 		onesv6, _ := iface.AddressIPv6().Mask.Size()
 		networkSettings.GlobalIPv6Address = iface.AddressIPv6().IP.String()
 		networkSettings.GlobalIPv6PrefixLen = onesv6
+		epSettings.GlobalIPv6Address = networkSettings.GlobalIPv6Address
+		epSettings.GlobalIPv6PrefixLen = onesv6
 	}
 
 	return networkSettings, nil
 }
 
-func (container *Container) updateJoinInfo(ep libnetwork.Endpoint) error {
+func (container *Container) updateJoinInfo(n libnetwork.Network, ep libnetwork.Endpoint) error {
 	epInfo := ep.Info()
 	if epInfo == nil {
 		// It is not an error to get an empty endpoint info
 		return nil
 	}
 
+	epSettings := container.NetworkSettings.Networks[n.Name()]
 	container.NetworkSettings.Gateway = epInfo.Gateway().String()
+	epSettings.Gateway = container.NetworkSettings.Gateway
 	if epInfo.GatewayIPv6().To16() != nil {
 		container.NetworkSettings.IPv6Gateway = epInfo.GatewayIPv6().String()
+		epSettings.IPv6Gateway = container.NetworkSettings.IPv6Gateway
 	}
 
 	return nil
 }
 
-func (container *Container) updateNetworkSettings(n libnetwork.Network) error {
+func (container *Container) updateNetworkSettings(n libnetwork.Network, endpointConfig *network.EndpointSettings) error {
 	if container.NetworkSettings == nil {
-		container.NetworkSettings = &network.Settings{Networks: []string{}}
+		container.NetworkSettings = &network.Settings{}
 	}
 	settings := container.NetworkSettings
+	if settings.Networks == nil {
+		settings.Networks = make(map[string]*network.EndpointSettings)
+	}
 
-	for _, s := range settings.Networks {
+	for s := range settings.Networks {
 		sn, err := container.daemon.FindNetwork(s)
 		if err != nil {
 			continue
@@ -712,7 +763,11 @@ func (container *Container) updateNetworkSettings(n libnetwork.Network) error {
 			return runconfig.ErrConflictNoNetwork
 		}
 	}
-	settings.Networks = append(settings.Networks, n.Name())
+
+	if endpointConfig == nil {
+		endpointConfig = &network.EndpointSettings{}
+	}
+	settings.Networks[n.Name()] = endpointConfig
 
 	return nil
 }
@@ -723,7 +778,7 @@ func (container *Container) updateEndpointNetworkSettings(n libnetwork.Network,
 		return err
 	}
 
-	networkSettings, err = container.buildEndpointInfo(ep, networkSettings)
+	networkSettings, err = container.buildEndpointInfo(n, ep, networkSettings)
 	if err != nil {
 		return err
 	}
@@ -754,7 +809,7 @@ func (container *Container) updateNetwork() error {
 
 	// Find if container is connected to the default bridge network
 	var n libnetwork.Network
-	for _, name := range container.NetworkSettings.Networks {
+	for name := range container.NetworkSettings.Networks {
 		sn, err := container.daemon.FindNetwork(name)
 		if err != nil {
 			continue
@@ -782,7 +837,7 @@ func (container *Container) updateNetwork() error {
 	return nil
 }
 
-func (container *Container) buildCreateEndpointOptions(n libnetwork.Network) ([]libnetwork.EndpointOption, error) {
+func (container *Container) buildCreateEndpointOptions(n libnetwork.Network, epConfig *network.EndpointSettings) ([]libnetwork.EndpointOption, error) {
 	var (
 		portSpecs     = make(nat.PortSet)
 		bindings      = make(nat.PortMap)
@@ -864,9 +919,112 @@ func (container *Container) buildCreateEndpointOptions(n libnetwork.Network) ([]
 		createOptions = append(createOptions, libnetwork.CreateOptionAnonymous())
 	}
 
+	// Only the user defined networks use the embedded DNS server, the
+	// names on the other networks are written to the hosts file
+	if !runconfig.NetworkMode(n.Name()).IsUserDefined() {
+		createOptions = append(createOptions, libnetwork.CreateOptionDisableResolution())
+	}
+
+	if ipam, ok := container.restoredAddresses[n.Name()]; ok {
+		createOptions = append(createOptions, libnetwork.CreateOptionIpam(net.ParseIP(ipam.IPv4Address), net.ParseIP(ipam.IPv6Address), nil))
+	} else if epConfig != nil {
+		ipamOptions, err := buildEndpointIPAMOptions(n, epConfig)
+		if err != nil {
+			return nil, err
+		}
+		createOptions = append(createOptions, ipamOptions...)
+	}
+
+	if epConfig != nil {
+		for _, alias := range epConfig.Aliases {
+			createOptions = append(createOptions, libnetwork.CreateOptionMyAlias(alias))
+		}
+
+		for _, l := range epConfig.Links {
+			name, alias, err := parsers.ParseLink(l)
+			if err != nil {
+				return nil, err
+			}
+			child, err := container.daemon.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			createOptions = append(createOptions, libnetwork.CreateOptionAlias(strings.TrimPrefix(child.Name, "/"), alias))
+		}
+	}
+
 	return createOptions, nil
 }
 
+// buildEndpointIPAMOptions returns the options requesting the IP addresses
+// given in the endpoint settings. An address can only be requested in a
+// network with a subnet configured by the user.
+func buildEndpointIPAMOptions(n libnetwork.Network, epConfig *network.EndpointSettings) ([]libnetwork.EndpointOption, error) {
+	ipam := epConfig.IPAMConfig
+	if ipam == nil || (ipam.IPv4Address == "" && ipam.IPv6Address == "") {
+		return nil, nil
+	}
+
+	_, _, v4Configs, v6Configs := n.Info().IpamConfig()
+	hasSubnet := func(configs []*libnetwork.IpamConf) bool {
+		for _, c := range configs {
+			if c.PreferredPool != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if (ipam.IPv4Address != "" && !hasSubnet(v4Configs)) || (ipam.IPv6Address != "" && !hasSubnet(v6Configs)) {
+		return nil, runconfig.ErrUnsupportedNetworkNoSubnetAndIP
+	}
+
+	return []libnetwork.EndpointOption{
+		libnetwork.CreateOptionIpam(net.ParseIP(ipam.IPv4Address), net.ParseIP(ipam.IPv6Address), nil),
+	}, nil
+}
+
+// verifyEndpointSettings checks the endpoint settings requested for a
+// container in the network named networkName. Addresses, aliases and links
+// can only be given in user defined networks.
+func verifyEndpointSettings(networkName string, epConfig *network.EndpointSettings) error {
+	if epConfig == nil {
+		return nil
+	}
+	userDefined := runconfig.NetworkMode(networkName).IsUserDefined()
+
+	if ipam := epConfig.IPAMConfig; ipam != nil && (ipam.IPv4Address != "" || ipam.IPv6Address != "") {
+		if !userDefined {
+			return runconfig.ErrUnsupportedNetworkAndIP
+		}
+		if ipam.IPv4Address != "" {
+			if ip := net.ParseIP(ipam.IPv4Address); ip == nil || ip.To4() == nil {
+				return derr.ErrorCodeInvalidEndpointIP.WithArgs(ipam.IPv4Address, 4)
+			}
+		}
+		if ipam.IPv6Address != "" {
+			if ip := net.ParseIP(ipam.IPv6Address); ip == nil || ip.To4() != nil {
+				return derr.ErrorCodeInvalidEndpointIP.WithArgs(ipam.IPv6Address, 6)
+			}
+		}
+	}
+
+	if len(epConfig.Aliases) > 0 && !userDefined {
+		return runconfig.ErrUnsupportedNetworkAndAlias
+	}
+
+	if len(epConfig.Links) > 0 {
+		if !userDefined {
+			return runconfig.ErrUnsupportedNetworkAndLinks
+		}
+		for _, l := range epConfig.Links {
+			if _, _, err := parsers.ParseLink(l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func createNetwork(controller libnetwork.NetworkController, dnet string, driver string) (libnetwork.Network, error) {
 	createOptions := []libnetwork.NetworkOption{}
 	genericOption := options.Generic{}
@@ -883,26 +1041,71 @@ func createNetwork(controller libnetwork.NetworkController, dnet string, driver
 	return controller.NewNetwork(driver, dnet, createOptions...)
 }
 
-func (container *Container) allocateNetwork() error {
-	settings := container.NetworkSettings.Networks
-	updateSettings := false
-	if settings == nil {
-		mode := container.hostConfig.NetworkMode
-		controller := container.daemon.netController
-		if container.Config.NetworkDisabled || mode.IsContainer() {
-			return nil
+// defaultNetworkName returns the name of the network the container is
+// connected to when it starts, as given by its network mode.
+func (container *Container) defaultNetworkName() string {
+	mode := container.hostConfig.NetworkMode
+	if mode.IsDefault() {
+		return container.daemon.netController.Config().Daemon.DefaultNetwork
+	}
+	networkName := mode.NetworkName()
+	if n, err := container.daemon.FindNetwork(networkName); err == nil {
+		networkName = n.Name()
+	}
+	return networkName
+}
+
+// initNetworkSettings records the network the container is connected to
+// when it starts, along with the endpoint settings requested for it. The
+// endpoint settings can only be given for the network of the container.
+func (container *Container) initNetworkSettings(endpointsConfig map[string]*network.EndpointSettings) error {
+	mode := container.hostConfig.NetworkMode
+	networkName := string(mode)
+	if !mode.IsContainer() {
+		networkName = container.defaultNetworkName()
+	}
+
+	var epConfig *network.EndpointSettings
+	for name, config := range endpointsConfig {
+		if name != string(mode) && name != networkName {
+			if n, err := container.daemon.FindNetwork(name); err != nil || n.Name() != networkName {
+				return derr.ErrorCodeEndpointNetworkMismatch.WithArgs(name, networkName)
+			}
 		}
+		epConfig = config
+	}
+
+	if container.Config.NetworkDisabled || mode.IsContainer() {
+		return nil
+	}
+	if epConfig == nil {
+		epConfig = &network.EndpointSettings{}
+	}
+	container.NetworkSettings.Networks = map[string]*network.EndpointSettings{networkName: epConfig}
+	return nil
+}
+
+func (container *Container) allocateNetwork() error {
+	mode := container.hostConfig.NetworkMode
+	if container.Config.NetworkDisabled || mode.IsContainer() {
+		return nil
+	}
 
-		networkName := mode.NetworkName()
-		if mode.IsDefault() {
-			networkName = controller.Config().Daemon.DefaultNetwork
+	updateSettings := false
+	if len(container.NetworkSettings.Networks) == 0 {
+		container.NetworkSettings.Networks = map[string]*network.EndpointSettings{
+			container.defaultNetworkName(): {},
 		}
-		settings = []string{networkName}
 		updateSettings = true
 	}
 
-	for _, n := range settings {
-		if err := container.connectToNetwork(n, updateSettings); err != nil {
+	// Networks can be renamed in the settings while connecting
+	networks := make(map[string]*network.EndpointSettings, len(container.NetworkSettings.Networks))
+	for n, epConfig := range container.NetworkSettings.Networks {
+		networks[n] = epConfig
+	}
+	for n, epConfig := range networks {
+		if err := container.connectToNetwork(n, epConfig, updateSettings); err != nil {
 			return err
 		}
 	}
@@ -910,15 +1113,16 @@ func (container *Container) allocateNetwork() error {
 	return container.writeHostConfig()
 }
 
-// ConnectToNetwork connects a container to a netork
-func (container *Container) ConnectToNetwork(idOrName string) error {
+// ConnectToNetwork connects a container to a network, with the given
+// endpoint settings
+func (container *Container) ConnectToNetwork(idOrName string, endpointConfig *network.EndpointSettings) error {
 	if !container.Running {
 		return derr.ErrorCodeNotRunning.WithArgs(container.ID)
 	}
-	return container.connectToNetwork(idOrName, true)
+	return container.connectToNetwork(idOrName, endpointConfig, true)
 }
 
-func (container *Container) connectToNetwork(idOrName string, updateSettings bool) error {
+func (container *Container) connectToNetwork(idOrName string, endpointConfig *network.EndpointSettings, updateSettings bool) error {
 	var err error
 
 	if container.hostConfig.NetworkMode.IsContainer() {
@@ -938,10 +1142,22 @@ func (container *Container) connectToNetwork(idOrName string, updateSettings boo
 		return err
 	}
 
+	if err := verifyEndpointSettings(n.Name(), endpointConfig); err != nil {
+		return err
+	}
+
 	if updateSettings {
-		if err := container.updateNetworkSettings(n); err != nil {
+		if err := container.updateNetworkSettings(n, endpointConfig); err != nil {
 			return err
 		}
+	} else if _, ok := container.NetworkSettings.Networks[n.Name()]; !ok {
+		// The settings of the network are recorded under its name, and not
+		// under the ID or the former name it was given by
+		delete(container.NetworkSettings.Networks, idOrName)
+		if endpointConfig == nil {
+			endpointConfig = &network.EndpointSettings{}
+		}
+		container.NetworkSettings.Networks[n.Name()] = endpointConfig
 	}
 
 	ep, err := container.getEndpointInNetwork(n)
@@ -953,7 +1169,7 @@ func (container *Container) connectToNetwork(idOrName string, updateSettings boo
 		return err
 	}
 
-	createOptions, err := container.buildCreateEndpointOptions(n)
+	createOptions, err := container.buildCreateEndpointOptions(n, endpointConfig)
 	if err != nil {
 		return err
 	}
@@ -965,7 +1181,7 @@ func (container *Container) connectToNetwork(idOrName string, updateSettings boo
 	}
 	defer func() {
 		if err != nil {
-			if e := ep.Delete(); e != nil {
+			if e := ep.Delete(false); e != nil {
 				logrus.Warnf("Could not rollback container connection to network %s", idOrName)
 			}
 		}
@@ -1000,10 +1216,11 @@ func (container *Container) connectToNetwork(idOrName string, updateSettings boo
 		return err
 	}
 
-	if err := container.updateJoinInfo(ep); err != nil {
+	if err := container.updateJoinInfo(n, ep); err != nil {
 		return derr.ErrorCodeJoinInfo.WithArgs(err)
 	}
 
+	container.logNetworkEvent(n, "connect")
 	return nil
 }
 
@@ -1126,9 +1343,7 @@ func (container *Container) releaseNetwork() {
 	}
 
 	sid := container.NetworkSettings.SandboxID
-	networks := container.NetworkSettings.Networks
-
-	container.NetworkSettings = &network.Settings{Networks: networks}
+	networks := container.resetNetworkSettings()
 
 	if sid == "" || len(networks) == 0 {
 		return
@@ -1142,7 +1357,76 @@ func (container *Container) releaseNetwork() {
 
 	if err := sb.Delete(); err != nil {
 		logrus.Errorf("Error deleting sandbox id %s for container %s: %v", sid, container.ID, err)
+		return
+	}
+
+	for name := range networks {
+		if n, err := container.daemon.FindNetwork(name); err == nil {
+			container.logNetworkEvent(n, "disconnect")
+		}
+	}
+}
+
+// resetNetworkSettings clears the network settings of the container, only
+// keeping the endpoint configuration of its networks for the next start, and
+// returns its networks.
+func (container *Container) resetNetworkSettings() map[string]*network.EndpointSettings {
+	networks := make(map[string]*network.EndpointSettings, len(container.NetworkSettings.Networks))
+	for name, epSettings := range container.NetworkSettings.Networks {
+		networks[name] = &network.EndpointSettings{
+			IPAMConfig: epSettings.IPAMConfig,
+			Links:      epSettings.Links,
+			Aliases:    epSettings.Aliases,
+		}
 	}
+
+	container.NetworkSettings = &network.Settings{Networks: networks}
+	return networks
+}
+
+// reconnectNetwork connects a container left running by the previous daemon
+// to its networks again. The network controller removes the sandboxes of the
+// previous daemon when it starts, so the container gets a new sandbox whose
+// interfaces are moved into the network namespace of its process. The
+// container keeps its addresses if they are still available, so that the
+// other containers reach it as before.
+func (container *Container) reconnectNetwork() error {
+	if container.hostConfig.NetworkMode.IsContainer() || container.Config.NetworkDisabled {
+		return nil
+	}
+	if container.NetworkSettings == nil || container.NetworkSettings.SandboxID == "" {
+		return nil
+	}
+
+	addresses := make(map[string]*network.EndpointIPAMConfig, len(container.NetworkSettings.Networks))
+	for name, epSettings := range container.NetworkSettings.Networks {
+		if epSettings.IPAddress != "" || epSettings.GlobalIPv6Address != "" {
+			addresses[name] = &network.EndpointIPAMConfig{
+				IPv4Address: epSettings.IPAddress,
+				IPv6Address: epSettings.GlobalIPv6Address,
+			}
+		}
+	}
+
+	container.resetNetworkSettings()
+	container.restoredAddresses = addresses
+	err := container.allocateNetwork()
+	container.restoredAddresses = nil
+	if err != nil {
+		logrus.Warnf("Failed to connect container %s to its networks with its previous addresses: %v", container.ID, err)
+		container.releaseNetwork()
+		if err := container.allocateNetwork(); err != nil {
+			return err
+		}
+	}
+	return container.setNetworkNamespaceKey(container.Pid)
+}
+
+// logNetworkEvent generates an event for the network n, to which the
+// container is connected or from which it is disconnected.
+func (container *Container) logNetworkEvent(n libnetwork.Network, action string) {
+	attributes := map[string]string{"container": container.ID}
+	container.daemon.LogNetworkEventWithAttributes(n, action, attributes)
 }
 
 // DisconnectFromNetwork disconnects a container from a network
@@ -1180,19 +1464,18 @@ func (container *Container) disconnectFromNetwork(n libnetwork.Network) error {
 		return fmt.Errorf("container %s failed to leave network %s: %v", container.ID, n.Name(), err)
 	}
 
-	if err := ep.Delete(); err != nil {
+	if err := ep.Delete(false); err != nil {
 		return fmt.Errorf("endpoint delete failed for container %s on network %s: %v", container.ID, n.Name(), err)
 	}
+	container.logNetworkEvent(n, "disconnect")
 
-	networks := container.NetworkSettings.Networks
-	for i, s := range networks {
+	for s := range container.NetworkSettings.Networks {
 		sn, err := container.daemon.FindNetwork(s)
 		if err != nil {
 			continue
 		}
 		if sn.Name() == n.Name() {
-			networks = append(networks[:i], networks[i+1:]...)
-			container.NetworkSettings.Networks = networks
+			delete(container.NetworkSettings.Networks, s)
 			break
 		}
 	}
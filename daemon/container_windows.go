@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/volume"
 	"github.com/docker/libnetwork"
@@ -40,8 +41,16 @@ func (container *Container) initializeNetworking() error {
 	return nil
 }
 
+func (container *Container) initNetworkSettings(endpointsConfig map[string]*network.EndpointSettings) error {
+	return nil
+}
+
+func verifyEndpointSettings(networkName string, epConfig *network.EndpointSettings) error {
+	return nil
+}
+
 // ConnectToNetwork connects a container to the network
-func (container *Container) ConnectToNetwork(idOrName string) error {
+func (container *Container) ConnectToNetwork(idOrName string, endpointConfig *network.EndpointSettings) error {
 	return nil
 }
 
@@ -170,6 +179,11 @@ func (container *Container) updateNetwork() error {
 func (container *Container) releaseNetwork() {
 }
 
+// reconnectNetwork is a no-op on Windows.
+func (container *Container) reconnectNetwork() error {
+	return nil
+}
+
 // appendNetworkMounts appends any network mounts to the array of mount points passed in.
 // Windows does not support network mounts (not to be confused with SMB network mounts), so
 // this is a no-op.
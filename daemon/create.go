@@ -1,10 +1,13 @@
 package daemon
 
 import (
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/graph/tags"
 	"github.com/docker/docker/image"
@@ -17,14 +20,17 @@ import (
 
 // ContainerCreateConfig is the parameter set to ContainerCreate()
 type ContainerCreateConfig struct {
-	Name            string
-	Config          *runconfig.Config
-	HostConfig      *runconfig.HostConfig
-	AdjustCPUShares bool
+	Name             string
+	Config           *runconfig.Config
+	HostConfig       *runconfig.HostConfig
+	NetworkingConfig *network.NetworkingConfig
+	AdjustCPUShares  bool
 }
 
 // ContainerCreate takes configs and creates a container.
-func (daemon *Daemon) ContainerCreate(params *ContainerCreateConfig) (types.ContainerCreateResponse, error) {
+func (daemon *Daemon) ContainerCreate(params *ContainerCreateConfig) (_ types.ContainerCreateResponse, retErr error) {
+	defer observeContainerAction("create", time.Now(), &retErr)
+
 	if params.Config == nil {
 		return types.ContainerCreateResponse{}, derr.ErrorCodeEmptyConfig
 	}
@@ -34,6 +40,10 @@ func (daemon *Daemon) ContainerCreate(params *ContainerCreateConfig) (types.Cont
 		return types.ContainerCreateResponse{"", warnings}, err
 	}
 
+	if err := verifyNetworkingConfig(params.NetworkingConfig); err != nil {
+		return types.ContainerCreateResponse{"", warnings}, err
+	}
+
 	daemon.adaptContainerSettings(params.HostConfig, params.AdjustCPUShares)
 
 	container, err := daemon.create(params)
@@ -64,6 +74,9 @@ func (daemon *Daemon) create(params *ContainerCreateConfig) (retC *Container, re
 	)
 
 	if params.Config.Image != "" {
+		if err := daemon.repositories.VerifyTrusted(params.Config.Image); err != nil {
+			return nil, err
+		}
 		img, err = daemon.repositories.LookupImage(params.Config.Image)
 		if err != nil {
 			return nil, err
@@ -101,12 +114,19 @@ func (daemon *Daemon) create(params *ContainerCreateConfig) (retC *Container, re
 	if err := daemon.Register(container); err != nil {
 		return nil, err
 	}
-	if err := daemon.createRootfs(container); err != nil {
+	if err := daemon.createRootfs(container, params.HostConfig.StorageOpt); err != nil {
 		return nil, err
 	}
 	if err := daemon.setHostConfig(container, params.HostConfig); err != nil {
 		return nil, err
 	}
+	var endpointsConfig map[string]*network.EndpointSettings
+	if params.NetworkingConfig != nil {
+		endpointsConfig = params.NetworkingConfig.EndpointsConfig
+	}
+	if err := container.initNetworkSettings(endpointsConfig); err != nil {
+		return nil, err
+	}
 	defer func() {
 		if retErr != nil {
 			if err := container.removeMountPoints(true); err != nil {
@@ -131,6 +151,30 @@ func (daemon *Daemon) create(params *ContainerCreateConfig) (retC *Container, re
 	return container, nil
 }
 
+// verifyNetworkingConfig checks the endpoint settings requested on create.
+// They can only be given for the network the container is created in.
+func verifyNetworkingConfig(networkingConfig *network.NetworkingConfig) error {
+	if networkingConfig == nil || len(networkingConfig.EndpointsConfig) == 0 {
+		return nil
+	}
+
+	if len(networkingConfig.EndpointsConfig) > 1 {
+		var names []string
+		for name := range networkingConfig.EndpointsConfig {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return derr.ErrorCodeMultipleNetworkConnect.WithArgs(strings.Join(names, ", "))
+	}
+
+	for name, epConfig := range networkingConfig.EndpointsConfig {
+		if err := verifyEndpointSettings(name, epConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (daemon *Daemon) generateSecurityOpt(ipcMode runconfig.IpcMode, pidMode runconfig.PidMode) ([]string, error) {
 	if ipcMode.IsHost() || pidMode.IsHost() {
 		return label.DisableSecOpt(), nil
@@ -20,6 +20,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/cliconfig"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/execdriver"
@@ -38,7 +39,6 @@ import (
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/parsers/filters"
@@ -119,11 +119,17 @@ type Daemon struct {
 	EventsService    *events.Events
 	netController    libnetwork.NetworkController
 	volumes          *store.VolumeStore
+	diskUsage        *diskUsageCache
 	discoveryWatcher discovery.Watcher
+	discoveryStop    chan struct{}
 	root             string
 	shutdown         bool
 	uidMaps          []idtools.IDMap
 	gidMaps          []idtools.IDMap
+
+	// reloadLock protects the options which can be changed by reloading
+	// the configuration of the daemon.
+	reloadLock sync.Mutex
 }
 
 // Get looks for a container using the provided information, which could be
@@ -213,7 +219,7 @@ func (daemon *Daemon) Register(container *Container) error {
 	// we'll waste time if we update it for every container
 	daemon.idIndex.Add(container.ID)
 
-	if container.IsRunning() {
+	if container.IsRunning() && !daemon.isRestorable(container.ID) {
 		logrus.Debugf("killing old running container %s", container.ID)
 		// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
 		container.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
@@ -315,6 +321,10 @@ func (daemon *Daemon) restore() error {
 		}
 	}
 
+	var (
+		mu     sync.Mutex
+		loaded []*Container
+	)
 	group := sync.WaitGroup{}
 	for _, c := range containers {
 		group.Add(1)
@@ -336,6 +346,61 @@ func (daemon *Daemon) restore() error {
 				return
 			}
 
+			mu.Lock()
+			loaded = append(loaded, container)
+			mu.Unlock()
+		}(c.container, c.registered)
+	}
+	group.Wait()
+
+	// The network controller is initialized once the containers are
+	// registered, so that the restored containers can be connected again.
+	if daemon.netController, err = daemon.initNetworkController(daemon.configStore); err != nil {
+		return fmt.Errorf("Error initializing network controller: %v", err)
+	}
+
+	// The containers left running by the previous daemon are restored one
+	// at a time, the linked containers before the containers linking to
+	// them, so that the iptables rules of their links are set up again when
+	// they are connected to their networks.
+	restored := make(map[string]bool)
+	var restoreRunning func(container *Container)
+	restoreRunning = func(container *Container) {
+		if restored[container.ID] {
+			return
+		}
+		restored[container.ID] = true
+
+		children, err := daemon.children(container.Name)
+		if err != nil {
+			logrus.Warnf("Failed to get the linked containers of container %s: %v", container.ID, err)
+		}
+		for _, child := range children {
+			if child.IsRunning() {
+				restoreRunning(child)
+			}
+		}
+
+		logrus.Debugf("Restoring container %s", container.ID)
+		if err := daemon.restoreContainer(container); err != nil {
+			logrus.Errorf("Failed to restore container %s: %s", container.ID, err)
+		}
+	}
+	for _, c := range loaded {
+		if c.IsRunning() {
+			restoreRunning(c)
+		}
+	}
+
+	for _, c := range loaded {
+		if restored[c.ID] {
+			continue
+		}
+		group.Add(1)
+
+		go func(container *Container) {
+			defer group.Done()
+
 			// check the restart policy on the containers and restart any container with
 			// the restart policy of "always"
 			if daemon.configStore.AutoRestart && container.shouldRestart() {
@@ -345,7 +410,7 @@ func (daemon *Daemon) restore() error {
 					logrus.Errorf("Failed to start container %s: %s", container.ID, err)
 				}
 			}
-		}(c.container, c.registered)
+		}(c)
 	}
 	group.Wait()
 
@@ -548,9 +613,9 @@ func (daemon *Daemon) GetEventFilter(filter filters.Args) *events.Filter {
 	return events.NewFilter(filter, daemon.GetLabels)
 }
 
-// SubscribeToEvents returns the currently record of events, a channel to stream new events from, and a function to cancel the stream of events.
-func (daemon *Daemon) SubscribeToEvents() ([]*jsonmessage.JSONMessage, chan interface{}, func()) {
-	return daemon.EventsService.Subscribe()
+// SubscribeToEvents returns a function replaying the recorded events with since <= time < until, a channel to stream new events from, and a function to cancel the stream of events.
+func (daemon *Daemon) SubscribeToEvents(since, until time.Time) (func(func(*eventtypes.Message) error) error, chan interface{}, func()) {
+	return daemon.EventsService.SubscribeRange(since, until)
 }
 
 // GetLabels for a container or image id
@@ -686,7 +751,7 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	logrus.Debugf("Using graph driver %s", driver)
 
 	d := &Daemon{}
-	d.driver = driver
+	d.driver = graphdriver.NewMeteredDriver(driver)
 
 	// Ensure the graph driver is shutdown at a later point
 	defer func() {
@@ -744,20 +809,42 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, err
 	}
 
-	eventsService := events.New()
+	journal, err := events.NewJournal(filepath.Join(config.Root, "events.db"), events.JournalConfig{
+		MaxEntries: config.EventsRetentionSize,
+		MaxAge:     config.EventsRetentionAge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open events journal: %v", err)
+	}
+	eventsService := events.NewWithJournal(journal)
+
+	var trustPolicy graph.TrustPolicy
+	if config.TrustPolicy != "" {
+		if trustPolicy, err = graph.LoadTrustPolicy(config.TrustPolicy); err != nil {
+			return nil, err
+		}
+	}
+
 	logrus.Debug("Creating repository list")
 	tagCfg := &graph.TagStoreConfig{
-		Graph:    g,
-		Key:      trustKey,
-		Registry: registryService,
-		Events:   eventsService,
+		Graph:       g,
+		Key:         trustKey,
+		Registry:    registryService,
+		Events:      eventsService,
+		TrustPolicy: trustPolicy,
+		TrustDir:    trustDir,
+
+		MaxConcurrentDownloads: config.MaxConcurrentDownloads,
+		MaxConcurrentUploads:   config.MaxConcurrentUploads,
+		MaxDownloadAttempts:    config.MaxDownloadAttempts,
+		DownloadRetryDelay:     config.DownloadRetryDelay,
 	}
 	repositories, err := graph.NewTagStore(filepath.Join(config.Root, "repositories-"+d.driver.String()), tagCfg)
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't create Tag store repositories-%s: %s", d.driver.String(), err)
 	}
 
-	if restorer, ok := d.driver.(graphdriver.ImageRestorer); ok {
+	if restorer, ok := graphdriver.Unwrap(d.driver).(graphdriver.ImageRestorer); ok {
 		if _, err := restorer.RestoreCustomImages(repositories, g); err != nil {
 			return nil, fmt.Errorf("Couldn't restore custom images: %s", err)
 		}
@@ -768,16 +855,12 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	// DiscoveryWatcher version.
 	if config.ClusterStore != "" && config.ClusterAdvertise != "" {
 		var err error
-		if d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts); err != nil {
+		d.discoveryStop = make(chan struct{})
+		if d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts, d.discoveryStop); err != nil {
 			return nil, fmt.Errorf("discovery initialization failed (%v)", err)
 		}
 	}
 
-	d.netController, err = d.initNetworkController(config)
-	if err != nil {
-		return nil, fmt.Errorf("Error initializing network controller: %v", err)
-	}
-
 	graphdbPath := filepath.Join(config.Root, "linkgraph.db")
 	graph, err := graphdb.NewSqliteConn(graphdbPath)
 	if err != nil {
@@ -822,6 +905,8 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.RegistryService = registryService
 	d.EventsService = eventsService
 	d.volumes = volStore
+	volStore.SetEventLogger(d.LogVolumeEvent)
+	d.diskUsage = newDiskUsageCache()
 	d.root = config.Root
 	d.uidMaps = uidMaps
 	d.gidMaps = gidMaps
@@ -842,11 +927,18 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	// left is the number of containers kept running
+	left := 0
 	if daemon.containers != nil {
 		group := sync.WaitGroup{}
 		logrus.Debug("starting clean shutdown of all containers...")
 		for _, container := range daemon.List() {
 			c := container
+			if daemon.keepRunning(c) {
+				logrus.Debugf("leaving %s running", c.ID)
+				left++
+				continue
+			}
 			if c.IsRunning() {
 				logrus.Debugf("stopping %s", c.ID)
 				group.Add(1)
@@ -894,6 +986,12 @@ func (daemon *Daemon) Shutdown() error {
 		}
 		group.Wait()
 
+		if left > 0 {
+			if err := daemon.execDriver.(execdriver.Restorer).Detach(); err != nil {
+				logrus.Errorf("Error recording the exit of the containers left running: %v", err)
+			}
+		}
+
 		// trigger libnetwork Stop only if it's initialized
 		if daemon.netController != nil {
 			daemon.netController.Stop()
@@ -906,6 +1004,17 @@ func (daemon *Daemon) Shutdown() error {
 		}
 	}
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.Errorf("Error during events journal Close(): %v", err)
+		}
+	}
+
+	// The containers left running keep their filesystems mounted
+	if left > 0 {
+		return nil
+	}
+
 	if daemon.driver != nil {
 		if err := daemon.driver.Cleanup(); err != nil {
 			logrus.Errorf("Error during graph storage driver.Cleanup(): %v", err)
@@ -984,7 +1093,10 @@ func (daemon *Daemon) diff(container *Container) (archive.Archive, error) {
 	return daemon.driver.Diff(container.ID, initID)
 }
 
-func (daemon *Daemon) createRootfs(container *Container) error {
+// createRootfs creates the init layer and the read-write layer of a
+// container. storageOpt holds the storage driver options of the read-write
+// layer.
+func (daemon *Daemon) createRootfs(container *Container, storageOpt map[string]string) error {
 	// Step 1: create the container directory.
 	// This doubles as a barrier to avoid race conditions.
 	rootUID, rootGID, err := idtools.GetRootUIDGID(daemon.uidMaps, daemon.gidMaps)
@@ -995,7 +1107,7 @@ func (daemon *Daemon) createRootfs(container *Container) error {
 		return err
 	}
 	initID := fmt.Sprintf("%s-init", container.ID)
-	if err := daemon.driver.Create(initID, container.ImageID); err != nil {
+	if err := daemon.driver.Create(initID, container.ImageID, nil); err != nil {
 		return err
 	}
 	initPath, err := daemon.driver.Get(initID, "")
@@ -1012,7 +1124,7 @@ func (daemon *Daemon) createRootfs(container *Container) error {
 	// for the actual container.
 	daemon.driver.Put(initID)
 
-	if err := daemon.driver.Create(container.ID, initID); err != nil {
+	if err := daemon.driver.Create(container.ID, initID, storageOpt); err != nil {
 		return err
 	}
 	return nil
@@ -1035,7 +1147,7 @@ func (daemon *Daemon) TagImage(repoName, tag, imageName string, force bool) erro
 	if err := daemon.repositories.Tag(repoName, tag, imageName, force); err != nil {
 		return err
 	}
-	daemon.EventsService.Log("tag", utils.ImageReference(repoName, tag), "")
+	daemon.LogImageEvent(utils.ImageReference(repoName, tag), "tag")
 	return nil
 }
 
@@ -1054,12 +1166,13 @@ func (daemon *Daemon) ImportImage(src, repo, tag, msg string, inConfig io.ReadCl
 }
 
 // ExportImage exports a list of images to the given output stream. The
-// exported images are archived into a tar when written to the output
-// stream. All images with the given tag and all versions containing
-// the same tag are exported. names is the set of tags to export, and
-// outStream is the writer which the images are written to.
-func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
-	return daemon.repositories.ImageExport(names, outStream)
+// exported images are archived into a tar, compressed with the given
+// compression, when written to the output stream. All images with the
+// given tag and all versions containing the same tag are exported. names
+// is the set of tags to export, and outStream is the writer which the
+// images are written to.
+func (daemon *Daemon) ExportImage(names []string, compression archive.Compression, outStream io.Writer) error {
+	return daemon.repositories.ImageExport(names, compression, outStream)
 }
 
 // PushImage initiates a push operation on the repository named localName.
@@ -1182,6 +1295,51 @@ func (daemon *Daemon) ImageGetCached(imgID string, config *runconfig.Config) (*i
 	return match, nil
 }
 
+// ImageGetCachedFrom returns the most recently created image in the history
+// of one of the images with the sources IDs that had the same config when it
+// was created, and whose parent is the image with imgID. Images pulled from a
+// registry get content-addressable IDs, so that the parent also matches if it
+// was pulled from the same image as the image with imgID. This lets builds use
+// the history of pulled images as a cache, although it is not linked to the
+// local images. nil is returned if no such image can be found.
+func (daemon *Daemon) ImageGetCachedFrom(imgID string, config *runconfig.Config, sources []string) (*image.Image, error) {
+	images := daemon.Graph().Map()
+	originalID := daemon.originalImageID(imgID)
+
+	var match *image.Image
+	for _, source := range sources {
+		for img, ok := images[source]; ok; img, ok = images[img.Parent] {
+			parentMatches := img.Parent == imgID ||
+				imgID != "" && img.Parent != "" && daemon.originalImageID(img.Parent) == originalID
+			if !parentMatches {
+				continue
+			}
+			if runconfig.Compare(&img.ContainerConfig, config) {
+				if match == nil || match.Created.Before(img.Created) {
+					match = img
+				}
+			}
+			break
+		}
+	}
+	return match, nil
+}
+
+// originalImageID returns the ID the image with the given ID had where it was
+// created, as recorded in its v1Compatibility data when it was pulled by a
+// content-addressable ID. The given ID is returned for the other images.
+func (daemon *Daemon) originalImageID(id string) string {
+	data, err := daemon.Graph().GetV1CompatibilityConfig(id)
+	if err != nil {
+		return id
+	}
+	img, err := image.NewImgJSON(data)
+	if err != nil || img.ID == "" {
+		return id
+	}
+	return img.ID
+}
+
 // tempDir returns the default directory to use for temporary files.
 func tempDir(rootDir string, rootUID, rootGID int) (string, error) {
 	var tmpDir string
@@ -1249,6 +1407,10 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *runconfig.HostConfig,
 				return nil, err
 			}
 		}
+
+		if err := daemon.verifyLiveRestoreSettings(config); err != nil {
+			return nil, err
+		}
 	}
 
 	if hostConfig == nil {
@@ -11,7 +11,7 @@ import (
 // Given the graphdriver ad, if it is aufs, then migrate it.
 // If aufs driver is not built, this func is a noop.
 func migrateIfAufs(driver graphdriver.Driver, root string) error {
-	if ad, ok := driver.(*aufs.Driver); ok {
+	if ad, ok := graphdriver.Unwrap(driver).(*aufs.Driver); ok {
 		logrus.Debugf("Migrating existing containers")
 		if err := ad.Migrate(root, setupInitLayer); err != nil {
 			return err
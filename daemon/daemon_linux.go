@@ -36,6 +36,10 @@ func (daemon *Daemon) cleanupMountsFromReader(reader io.Reader, unmount func(tar
 			logrus.Debugf("Mount base: %v, repository %s", fields[4], daemon.repository)
 			mnt := fields[4]
 			mountBase := filepath.Base(mnt)
+			// The mounts of the containers left running are kept
+			if daemon.isRestorable(filepath.Base(filepath.Dir(mnt))) {
+				continue
+			}
 			if mountBase == "mqueue" || mountBase == "shm" {
 				logrus.Debugf("Unmounting %v", mnt)
 				if err := unmount(mnt); err != nil {
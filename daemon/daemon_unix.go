@@ -15,6 +15,7 @@ import (
 	"github.com/docker/docker/autogen/dockerversion"
 	"github.com/docker/docker/daemon/graphdriver"
 	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/blkiodev"
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/parsers"
@@ -30,6 +31,8 @@ import (
 	"github.com/docker/libnetwork/netlabel"
 	"github.com/docker/libnetwork/options"
 	"github.com/docker/libnetwork/types"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/devices"
 	"github.com/opencontainers/runc/libcontainer/label"
 	"github.com/vishvananda/netlink"
 )
@@ -227,6 +230,41 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 	if hostConfig.BlkioWeight > 0 && (hostConfig.BlkioWeight < 10 || hostConfig.BlkioWeight > 1000) {
 		return warnings, fmt.Errorf("Range of blkio weight is from 10 to 1000.")
 	}
+	if len(hostConfig.BlkioWeightDevice) > 0 && !sysInfo.BlkioWeightDevice {
+		warnings = append(warnings, "Your kernel does not support Block I/O weight_device. Weight-device discarded.")
+		logrus.Warnf("Your kernel does not support Block I/O weight_device. Weight-device discarded.")
+		hostConfig.BlkioWeightDevice = []*blkiodev.WeightDevice{}
+	}
+	for _, wd := range hostConfig.BlkioWeightDevice {
+		if wd.Weight > 0 && (wd.Weight < 10 || wd.Weight > 1000) {
+			return warnings, fmt.Errorf("Range of blkio weight for device %s is from 10 to 1000.", wd.Path)
+		}
+	}
+	if len(hostConfig.BlkioDeviceReadBps) > 0 && !sysInfo.BlkioReadBpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block read limit in bytes per second. --device-read-bps discarded.")
+		logrus.Warnf("Your kernel does not support Block read limit in bytes per second. --device-read-bps discarded.")
+		hostConfig.BlkioDeviceReadBps = []*blkiodev.ThrottleDevice{}
+	}
+	if len(hostConfig.BlkioDeviceWriteBps) > 0 && !sysInfo.BlkioWriteBpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block write limit in bytes per second. --device-write-bps discarded.")
+		logrus.Warnf("Your kernel does not support Block write limit in bytes per second. --device-write-bps discarded.")
+		hostConfig.BlkioDeviceWriteBps = []*blkiodev.ThrottleDevice{}
+	}
+	if len(hostConfig.BlkioDeviceReadIOps) > 0 && !sysInfo.BlkioReadIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block read limit in IO per second. --device-read-iops discarded.")
+		logrus.Warnf("Your kernel does not support Block read limit in IO per second. --device-read-iops discarded.")
+		hostConfig.BlkioDeviceReadIOps = []*blkiodev.ThrottleDevice{}
+	}
+	if len(hostConfig.BlkioDeviceWriteIOps) > 0 && !sysInfo.BlkioWriteIOpsDevice {
+		warnings = append(warnings, "Your kernel does not support Block write limit in IO per second. --device-write-iops discarded.")
+		logrus.Warnf("Your kernel does not support Block write limit in IO per second. --device-write-iops discarded.")
+		hostConfig.BlkioDeviceWriteIOps = []*blkiodev.ThrottleDevice{}
+	}
+	if hostConfig.PidsLimit != 0 && !sysInfo.PidsLimit {
+		warnings = append(warnings, "Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		logrus.Warnf("Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		hostConfig.PidsLimit = 0
+	}
 	if hostConfig.OomKillDisable && !sysInfo.OomKillDisable {
 		hostConfig.OomKillDisable = false
 		return warnings, fmt.Errorf("Your kernel does not support oom kill disable.")
@@ -239,6 +277,46 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 	return warnings, nil
 }
 
+// getBlkioWeightDevices converts the weight devices of a container to the
+// major:minor form of the blkio cgroup.
+func getBlkioWeightDevices(config *runconfig.HostConfig) ([]*configs.WeightDevice, error) {
+	var blkioWeightDevices []*configs.WeightDevice
+	for _, weightDevice := range config.BlkioWeightDevice {
+		major, minor, err := getBlkioDeviceNumbers(weightDevice.Path)
+		if err != nil {
+			return nil, err
+		}
+		blkioWeightDevices = append(blkioWeightDevices, configs.NewWeightDevice(major, minor, weightDevice.Weight, 0))
+	}
+	return blkioWeightDevices, nil
+}
+
+// getBlkioThrottleDevices converts the throttle devices of a container to
+// the major:minor form of the blkio cgroup.
+func getBlkioThrottleDevices(devs []*blkiodev.ThrottleDevice) ([]*configs.ThrottleDevice, error) {
+	var throttleDevices []*configs.ThrottleDevice
+	for _, d := range devs {
+		major, minor, err := getBlkioDeviceNumbers(d.Path)
+		if err != nil {
+			return nil, err
+		}
+		throttleDevices = append(throttleDevices, configs.NewThrottleDevice(major, minor, d.Rate))
+	}
+	return throttleDevices, nil
+}
+
+// getBlkioDeviceNumbers returns the major and minor numbers of a block device.
+func getBlkioDeviceNumbers(path string) (int64, int64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	if stat.Mode&syscall.S_IFMT != syscall.S_IFBLK {
+		return 0, 0, fmt.Errorf("%s is not a block device", path)
+	}
+	return devices.Major(int(stat.Rdev)), devices.Minor(int(stat.Rdev)), nil
+}
+
 // checkConfigOptions checks for mutually incompatible config options
 func checkConfigOptions(config *Config) error {
 	// Check for mutually incompatible config options
@@ -490,7 +568,7 @@ func initBridgeDriver(controller libnetwork.NetworkController, config *Config) e
 			netlabel.GenericData: netOption,
 			netlabel.EnableIPv6:  config.Bridge.EnableIPv6,
 		}),
-		libnetwork.NetworkOptionIpam("default", "", v4Conf, v6Conf))
+		libnetwork.NetworkOptionIpam("default", "", v4Conf, v6Conf, nil))
 	if err != nil {
 		return fmt.Errorf("Error creating default \"bridge\" network: %v", err)
 	}
@@ -5,8 +5,11 @@ package daemon
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
 
+	"github.com/docker/docker/pkg/system"
 	"github.com/docker/docker/runconfig"
 )
 
@@ -85,3 +88,33 @@ func TestAdjustCPUSharesNoAdjustment(t *testing.T) {
 		t.Error("Expected CPUShares to be unchanged")
 	}
 }
+
+func TestGetBlkioDeviceNumbers(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Test requires root to create a block device")
+	}
+	tmp, err := ioutil.TempDir("", "docker-daemon-unix-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, dev := range []struct{ major, minor int64 }{{8, 0}, {8, 17}, {259, 300}, {253, 1048575}} {
+		path := filepath.Join(tmp, "blk")
+		if err := system.Mknod(path, syscall.S_IFBLK|0600, int(system.Mkdev(dev.major, dev.minor))); err != nil {
+			t.Fatal(err)
+		}
+		major, minor, err := getBlkioDeviceNumbers(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if major != dev.major || minor != dev.minor {
+			t.Fatalf("Expected device %d:%d, got %d:%d", dev.major, dev.minor, major, minor)
+		}
+		os.Remove(path)
+	}
+
+	if _, _, err := getBlkioDeviceNumbers(tmp); err == nil {
+		t.Fatal("Expected an error for a path which is not a block device")
+	}
+}
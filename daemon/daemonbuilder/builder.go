@@ -109,6 +109,12 @@ func (d Docker) Commit(c *daemon.Container, cfg *daemon.ContainerCommitConfig) (
 	return d.Daemon.Commit(c, cfg)
 }
 
+// Squash creates a new Docker image from the image `imageID`, with a single
+// layer holding the changes of its layers since the image `baseID`.
+func (d Docker) Squash(imageID, baseID string) (*image.Image, error) {
+	return d.Daemon.SquashImage(imageID, baseID)
+}
+
 // Retain retains an image avoiding it to be removed or overwritten until a corresponding Release() call.
 func (d Docker) Retain(sessionID, imgID string) {
 	d.Daemon.Graph().Retain(sessionID, imgID)
@@ -205,6 +211,18 @@ func (d Docker) GetCachedImage(imgID string, cfg *runconfig.Config) (string, err
 	return cache.ID, nil
 }
 
+// GetCachedImageFrom returns a reference to an image from the history of one
+// of the `sources` images whose parent equals `parent`, or was pulled from the
+// same image, and runconfig equals `cfg`. A cache miss is expected to return an
+// empty ID and a nil error.
+func (d Docker) GetCachedImageFrom(imgID string, cfg *runconfig.Config, sources []string) (string, error) {
+	cache, err := d.Daemon.ImageGetCachedFrom(imgID, cfg, sources)
+	if cache == nil || err != nil {
+		return "", err
+	}
+	return cache.ID, nil
+}
+
 // Following is specific to builder contexts
 
 // DetectContextFromRemoteURL returns a context and in certain cases the name of the dockerfile to be used
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	derr "github.com/docker/docker/errors"
@@ -19,7 +20,9 @@ type ContainerRmConfig struct {
 // is returned if the container is not found, or if the remove
 // fails. If the remove succeeds, the container name is released, and
 // network links are removed.
-func (daemon *Daemon) ContainerRm(name string, config *ContainerRmConfig) error {
+func (daemon *Daemon) ContainerRm(name string, config *ContainerRmConfig) (retErr error) {
+	defer observeContainerAction("delete", time.Now(), &retErr)
+
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
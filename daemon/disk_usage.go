@@ -0,0 +1,213 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/volume"
+)
+
+// diskUsageCache caches the sizes computed for the disk usage reports, as
+// computing the size of the writable layer of a container can be expensive
+// with some graph drivers. Only one report is computed at a time.
+type diskUsageCache struct {
+	running chan struct{}
+
+	mu         sync.Mutex
+	containers map[string]containerSize
+}
+
+// containerSize is the cached size of a stopped container. The writable
+// layer of a stopped container only changes when it is started again, which
+// changes the time it finished at, or when files are copied into it, which
+// discards its cached size. The containers which were never started are not
+// cached, as the builder copies files into them directly.
+type containerSize struct {
+	finishedAt time.Time
+	sizeRw     int64
+	sizeRootFs int64
+}
+
+func newDiskUsageCache() *diskUsageCache {
+	return &diskUsageCache{
+		running:    make(chan struct{}, 1),
+		containers: make(map[string]containerSize),
+	}
+}
+
+// SystemDiskUsage returns the disk usage of the images, containers and
+// volumes. The computation is abandoned with an error as soon as stop is
+// closed or receives a value, as when the client disconnects.
+func (daemon *Daemon) SystemDiskUsage(stop <-chan bool) (*types.DiskUsage, error) {
+	select {
+	case daemon.diskUsage.running <- struct{}{}:
+		defer func() { <-daemon.diskUsage.running }()
+	case <-stop:
+		return nil, derr.ErrorCodeDiskUsageCancelled
+	}
+
+	du := &types.DiskUsage{}
+	containers := daemon.List()
+	if err := daemon.imagesDiskUsage(du, containers); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		select {
+		case <-stop:
+			return nil, derr.ErrorCodeDiskUsageCancelled
+		default:
+		}
+		du.Containers = append(du.Containers, daemon.containerDiskUsage(container))
+		seen[container.ID] = true
+	}
+	daemon.diskUsage.forget(seen)
+
+	for _, v := range daemon.volumes.List() {
+		select {
+		case <-stop:
+			return nil, derr.ErrorCodeDiskUsageCancelled
+		default:
+		}
+		vu := &types.VolumeUsage{
+			Name:     v.Name(),
+			Driver:   v.DriverName(),
+			Size:     -1,
+			RefCount: int(daemon.volumes.Count(v)),
+		}
+		if v.DriverName() == volume.DefaultDriverName {
+			size, err := directory.Size(v.Path())
+			if err != nil {
+				logrus.Warnf("could not determine the size of volume %s: %v", v.Name(), err)
+			} else {
+				vu.Size = size
+			}
+		}
+		du.Volumes = append(du.Volumes, vu)
+	}
+	return du, nil
+}
+
+// imagesDiskUsage fills in the disk usage of the images listed by docker
+// images. The size of each image is split between the layers it shares with
+// other images and its own layers, walking the parent chain of each image
+// with the saved size of each layer.
+func (daemon *Daemon) imagesDiskUsage(du *types.DiskUsage, containers []*Container) error {
+	images, err := daemon.repositories.Images("", "", false)
+	if err != nil {
+		return err
+	}
+	layers := daemon.graph.Map()
+
+	chain := func(id string) []*image.Image {
+		var parents []*image.Image
+		for img, ok := layers[id]; ok; img, ok = layers[img.Parent] {
+			parents = append(parents, img)
+		}
+		return parents
+	}
+
+	// Count the images which include each layer
+	refs := make(map[string]int)
+	for _, img := range images {
+		for _, layer := range chain(img.ID) {
+			refs[layer.ID]++
+		}
+	}
+
+	usedImages := make(map[string]int)
+	usedLayers := make(map[string]bool)
+	for _, container := range containers {
+		usedImages[container.ImageID]++
+		for _, layer := range chain(container.ImageID) {
+			usedLayers[layer.ID] = true
+		}
+	}
+
+	for id, layer := range layers {
+		du.LayersSize += layer.Size
+		if !usedLayers[id] {
+			du.LayersReclaimable += layer.Size
+		}
+	}
+
+	for _, img := range images {
+		iu := &types.ImageUsage{
+			ID:         img.ID,
+			RepoTags:   img.RepoTags,
+			Created:    img.Created,
+			Containers: usedImages[img.ID],
+		}
+		for _, layer := range chain(img.ID) {
+			iu.VirtualSize += layer.Size
+			if refs[layer.ID] > 1 {
+				iu.SharedSize += layer.Size
+			}
+		}
+		iu.Size = iu.VirtualSize - iu.SharedSize
+		du.Images = append(du.Images, iu)
+	}
+	return nil
+}
+
+// containerDiskUsage returns the disk usage of a container, from the cache
+// if the container is stopped and its writable layer has not changed since
+// its size was computed.
+func (daemon *Daemon) containerDiskUsage(container *Container) *types.ContainerUsage {
+	cu := &types.ContainerUsage{
+		ID:      container.ID,
+		Names:   []string{container.Name},
+		Image:   container.Config.Image,
+		Created: container.Created.Unix(),
+		Status:  container.State.String(),
+		Running: container.IsRunning(),
+	}
+
+	cacheable := !cu.Running && !container.FinishedAt.IsZero()
+	c := daemon.diskUsage
+	c.mu.Lock()
+	cached, ok := c.containers[container.ID]
+	c.mu.Unlock()
+	if ok && cacheable && cached.finishedAt.Equal(container.FinishedAt) {
+		cu.SizeRw, cu.SizeRootFs = cached.sizeRw, cached.sizeRootFs
+		return cu
+	}
+
+	cu.SizeRw, cu.SizeRootFs = container.getSize()
+	if cacheable && cu.SizeRw != -1 {
+		c.mu.Lock()
+		c.containers[container.ID] = containerSize{
+			finishedAt: container.FinishedAt,
+			sizeRw:     cu.SizeRw,
+			sizeRootFs: cu.SizeRootFs,
+		}
+		c.mu.Unlock()
+	}
+	return cu
+}
+
+// forgetContainer discards the cached size of a container, as its writable
+// layer changed.
+func (c *diskUsageCache) forgetContainer(id string) {
+	c.mu.Lock()
+	delete(c.containers, id)
+	c.mu.Unlock()
+}
+
+// forget discards the cached sizes of the containers which are not in seen,
+// as they were removed.
+func (c *diskUsageCache) forget(seen map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.containers {
+		if !seen[id] {
+			delete(c.containers, id)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"testing"
+
+	derr "github.com/docker/docker/errors"
+)
+
+func TestSystemDiskUsageCancelled(t *testing.T) {
+	daemon := &Daemon{diskUsage: newDiskUsageCache()}
+
+	// Another report is being computed
+	daemon.diskUsage.running <- struct{}{}
+
+	stop := make(chan bool)
+	close(stop)
+	if _, err := daemon.SystemDiskUsage(stop); err != derr.ErrorCodeDiskUsageCancelled {
+		t.Fatalf("Expected the computation to be cancelled, got %v", err)
+	}
+}
+
+func TestDiskUsageCacheForget(t *testing.T) {
+	c := newDiskUsageCache()
+	c.containers["kept"] = containerSize{sizeRw: 1}
+	c.containers["removed"] = containerSize{sizeRw: 2}
+
+	c.forget(map[string]bool{"kept": true})
+
+	if _, ok := c.containers["kept"]; !ok {
+		t.Fatal("Expected the size of an existing container to be kept")
+	}
+	if _, ok := c.containers["removed"]; ok {
+		t.Fatal("Expected the size of a removed container to be forgotten")
+	}
+}
+
+func TestDiskUsageCacheForgetContainer(t *testing.T) {
+	c := newDiskUsageCache()
+	c.containers["copied"] = containerSize{sizeRw: 1}
+	c.containers["other"] = containerSize{sizeRw: 2}
+
+	c.forgetContainer("copied")
+
+	if _, ok := c.containers["copied"]; ok {
+		t.Fatal("Expected the size of a container whose layer changed to be forgotten")
+	}
+	if _, ok := c.containers["other"]; !ok {
+		t.Fatal("Expected the size of the other containers to be kept")
+	}
+}
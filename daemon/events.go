@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"os"
+	"strings"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/libnetwork"
+)
+
+// LogContainerEvent generates an event related to a container. The
+// attributes of the event are the labels, name and image of the container.
+func (daemon *Daemon) LogContainerEvent(container *Container, action string) {
+	attributes := copyAttributes(container.Config.Labels)
+	if container.Config.Image != "" {
+		attributes["image"] = container.Config.Image
+	}
+	attributes["name"] = strings.TrimLeft(container.Name, "/")
+
+	actor := eventtypes.Actor{
+		ID:         container.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.ContainerEventType, actor)
+}
+
+// LogImageEvent generates an event related to an image. ref is the image
+// reference or ID the action applied to.
+func (daemon *Daemon) LogImageEvent(ref, action string) {
+	actor := eventtypes.Actor{
+		ID:         ref,
+		Attributes: map[string]string{"name": ref},
+	}
+	daemon.EventsService.Log(action, eventtypes.ImageEventType, actor)
+}
+
+// LogVolumeEvent generates an event related to a volume.
+func (daemon *Daemon) LogVolumeEvent(volumeID, action string, attributes map[string]string) {
+	actor := eventtypes.Actor{
+		ID:         volumeID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.VolumeEventType, actor)
+}
+
+// LogNetworkEvent generates an event related to a network with only the
+// default attributes.
+func (daemon *Daemon) LogNetworkEvent(nw libnetwork.Network, action string) {
+	daemon.LogNetworkEventWithAttributes(nw, action, map[string]string{})
+}
+
+// LogNetworkEventWithAttributes generates an event related to a network
+// with specific given attributes, in addition to the name and type of the
+// network.
+func (daemon *Daemon) LogNetworkEventWithAttributes(nw libnetwork.Network, action string, attributes map[string]string) {
+	attributes["name"] = nw.Name()
+	attributes["type"] = nw.Type()
+
+	actor := eventtypes.Actor{
+		ID:         nw.ID(),
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.NetworkEventType, actor)
+}
+
+// LogDaemonEventWithAttributes generates an event related to the daemon
+// itself, with the given attributes in addition to the daemon's name.
+func (daemon *Daemon) LogDaemonEventWithAttributes(action string, attributes map[string]string) {
+	if name, err := os.Hostname(); err == nil {
+		attributes["name"] = name
+	}
+
+	actor := eventtypes.Actor{
+		ID:         daemon.ID,
+		Attributes: attributes,
+	}
+	daemon.EventsService.Log(action, eventtypes.DaemonEventType, actor)
+}
+
+// copyAttributes returns a copy of labels, which can be extended with
+// the other attributes of an event.
+func copyAttributes(labels map[string]string) map[string]string {
+	attributes := make(map[string]string, len(labels))
+	for k, v := range labels {
+		attributes[k] = v
+	}
+	return attributes
+}
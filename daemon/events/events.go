@@ -4,34 +4,113 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
 )
 
 const eventsLimit = 64
 
-// Events is pubsub channel for *jsonmessage.JSONMessage
+const (
+	// journalQueueSize is the number of events which can be waiting to be
+	// written to the journal before Log blocks.
+	journalQueueSize = 1024
+	// journalBatchSize is the maximum number of events written to the
+	// journal in a single transaction.
+	journalBatchSize = 256
+)
+
+// Events is pubsub channel for *eventtypes.Message
 type Events struct {
-	mu     sync.Mutex
-	events []*jsonmessage.JSONMessage
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []*eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *Journal
+
+	// journalQueue holds the events waiting to be written to the journal,
+	// and the requests to wait for them to be written.
+	journalQueue chan journalRequest
+	// journalDone is closed once the journal writer has returned.
+	journalDone chan struct{}
+	closed      bool
+}
+
+// journalRequest is either an event to write to the journal, or a request
+// to be notified once the events queued before it are written.
+type journalRequest struct {
+	event   *eventtypes.Message
+	flushed chan struct{}
 }
 
 // New returns new *Events instance
 func New() *Events {
 	return &Events{
-		events: make([]*jsonmessage.JSONMessage, 0, eventsLimit),
+		events: make([]*eventtypes.Message, 0, eventsLimit),
 		pub:    pubsub.NewPublisher(100*time.Millisecond, 1024),
 	}
 }
 
+// NewWithJournal returns a new *Events instance which records every event
+// in journal, so that they can be replayed after the in-memory buffer has
+// been overwritten or the daemon has restarted. The events are written to
+// the journal in the background, in batches.
+func NewWithJournal(journal *Journal) *Events {
+	e := New()
+	e.journal = journal
+	e.journalQueue = make(chan journalRequest, journalQueueSize)
+	e.journalDone = make(chan struct{})
+	go e.writeJournal()
+	return e
+}
+
+// writeJournal writes the queued events to the journal until the queue is
+// closed. The events queued while a transaction is running are written
+// together in the next one.
+func (e *Events) writeJournal() {
+	defer close(e.journalDone)
+	for req := range e.journalQueue {
+		var (
+			batch   []*eventtypes.Message
+			flushed []chan struct{}
+		)
+		add := func(req journalRequest) {
+			if req.event != nil {
+				batch = append(batch, req.event)
+			}
+			if req.flushed != nil {
+				flushed = append(flushed, req.flushed)
+			}
+		}
+		add(req)
+	drain:
+		for len(batch) < journalBatchSize {
+			select {
+			case req, ok := <-e.journalQueue:
+				if !ok {
+					break drain
+				}
+				add(req)
+			default:
+				break drain
+			}
+		}
+
+		if err := e.journal.WriteBatch(batch); err != nil {
+			logrus.Errorf("Error writing events to journal: %v", err)
+		}
+		for _, f := range flushed {
+			close(f)
+		}
+	}
+}
+
 // Subscribe adds new listener to events, returns slice of 64 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
 // to stop the stream of events.
-func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func()) {
+func (e *Events) Subscribe() ([]*eventtypes.Message, chan interface{}, func()) {
 	e.mu.Lock()
-	current := make([]*jsonmessage.JSONMessage, len(e.events))
+	current := make([]*eventtypes.Message, len(e.events))
 	copy(current, e.events)
 	l := e.pub.Subscribe()
 	e.mu.Unlock()
@@ -42,6 +121,62 @@ func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func
 	return current, l, cancel
 }
 
+// SubscribeRange adds new listener to events, like Subscribe, but instead
+// of the last 64 events it returns a function replaying the stored events
+// with since <= time < until to fn, oldest first. The replay stops at the
+// events sent to the listener. Stored events are read from the journal if
+// there is one. A zero since or until means there is no bound.
+func (e *Events) SubscribeRange(since, until time.Time) (func(fn func(*eventtypes.Message) error) error, chan interface{}, func()) {
+	e.mu.Lock()
+	l := e.pub.Subscribe()
+	cancel := func() {
+		e.Evict(l)
+	}
+
+	if e.journal == nil || e.closed {
+		current := e.bufferedRange(since, until)
+		e.mu.Unlock()
+		replay := func(fn func(*eventtypes.Message) error) error {
+			for _, ev := range current {
+				if err := fn(ev); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return replay, l, cancel
+	}
+
+	// The events logged from now on are sent to the listener
+	if end := time.Now(); until.IsZero() || end.Before(until) {
+		until = end
+	}
+	flushed := make(chan struct{})
+	e.journalQueue <- journalRequest{flushed: flushed}
+	e.mu.Unlock()
+
+	replay := func(fn func(*eventtypes.Message) error) error {
+		<-flushed
+		return e.journal.Walk(since, until, fn)
+	}
+	return replay, l, cancel
+}
+
+// bufferedRange returns the in-memory events with since <= time < until.
+func (e *Events) bufferedRange(since, until time.Time) []*eventtypes.Message {
+	var current []*eventtypes.Message
+	for _, ev := range e.events {
+		if !since.IsZero() && ev.TimeNano < since.UnixNano() {
+			continue
+		}
+		if !until.IsZero() && ev.TimeNano >= until.UnixNano() {
+			continue
+		}
+		current = append(current, ev)
+	}
+	return current
+}
+
 // Evict evicts listener from pubsub
 func (e *Events) Evict(l chan interface{}) {
 	e.pub.Evict(l)
@@ -49,10 +184,31 @@ func (e *Events) Evict(l chan interface{}) {
 
 // Log broadcasts event to listeners. Each listener has 100 millisecond for
 // receiving event or it will be skipped.
-func (e *Events) Log(action, id, from string) {
-	now := time.Now().UTC()
-	jm := &jsonmessage.JSONMessage{Status: action, ID: id, From: from, Time: now.Unix(), TimeNano: now.UnixNano()}
+func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
+	// The time is taken under the lock, so that the events are timed in the
+	// order they are logged
 	e.mu.Lock()
+	now := time.Now().UTC()
+	jm := &eventtypes.Message{
+		Type:     eventType,
+		Action:   action,
+		Actor:    actor,
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	}
+
+	// fill deprecated fields for container and image events, for clients
+	// which only understand the old format
+	switch eventType {
+	case eventtypes.ContainerEventType:
+		jm.Status = action
+		jm.ID = actor.ID
+		jm.From = actor.Attributes["image"]
+	case eventtypes.ImageEventType:
+		jm.Status = action
+		jm.ID = actor.ID
+	}
+
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
 		copy(e.events, e.events[1:])
@@ -60,10 +216,31 @@ func (e *Events) Log(action, id, from string) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	if e.journal != nil && !e.closed {
+		e.journalQueue <- journalRequest{event: jm}
+	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
 }
 
+// Close writes the queued events to the journal, if any, and closes it.
+func (e *Events) Close() error {
+	if e.journal == nil {
+		return nil
+	}
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.journalQueue)
+	e.mu.Unlock()
+
+	<-e.journalDone
+	return e.journal.Close()
+}
+
 // SubscribersCount returns number of event listeners
 func (e *Events) SubscribersCount() int {
 	return e.pub.Len()
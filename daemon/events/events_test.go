@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	eventtypes "github.com/docker/docker/api/types/events"
 )
 
 func TestEventsLog(t *testing.T) {
@@ -18,10 +18,10 @@ func TestEventsLog(t *testing.T) {
 	if count != 2 {
 		t.Fatalf("Must be 2 subscribers, got %d", count)
 	}
-	e.Log("test", "cont", "image")
+	e.Log("test", eventtypes.ContainerEventType, containerActor("cont", "image"))
 	select {
 	case msg := <-l1:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -42,7 +42,7 @@ func TestEventsLog(t *testing.T) {
 	}
 	select {
 	case msg := <-l2:
-		jmsg, ok := msg.(*jsonmessage.JSONMessage)
+		jmsg, ok := msg.(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
@@ -70,7 +70,7 @@ func TestEventsLogTimeout(t *testing.T) {
 
 	c := make(chan struct{})
 	go func() {
-		e.Log("test", "cont", "image")
+		e.Log("test", eventtypes.ContainerEventType, containerActor("cont", "image"))
 		close(c)
 	}()
 
@@ -88,7 +88,7 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", i)
 		id := fmt.Sprintf("cont_%d", i)
 		from := fmt.Sprintf("image_%d", i)
-		e.Log(action, id, from)
+		e.Log(action, eventtypes.ContainerEventType, containerActor(id, from))
 	}
 	time.Sleep(50 * time.Millisecond)
 	current, l, _ := e.Subscribe()
@@ -97,16 +97,16 @@ func TestLogEvents(t *testing.T) {
 		action := fmt.Sprintf("action_%d", num)
 		id := fmt.Sprintf("cont_%d", num)
 		from := fmt.Sprintf("image_%d", num)
-		e.Log(action, id, from)
+		e.Log(action, eventtypes.ContainerEventType, containerActor(id, from))
 	}
 	if len(e.events) != eventsLimit {
 		t.Fatalf("Must be %d events, got %d", eventsLimit, len(e.events))
 	}
 
-	var msgs []*jsonmessage.JSONMessage
+	var msgs []*eventtypes.Message
 	for len(msgs) < 10 {
 		m := <-l
-		jm, ok := (m).(*jsonmessage.JSONMessage)
+		jm, ok := (m).(*eventtypes.Message)
 		if !ok {
 			t.Fatalf("Unexpected type %T", m)
 		}
@@ -133,3 +133,31 @@ func TestLogEvents(t *testing.T) {
 		t.Fatalf("Last action is %s, must be action_89", lastC.Status)
 	}
 }
+
+func containerActor(id, image string) eventtypes.Actor {
+	return eventtypes.Actor{
+		ID:         id,
+		Attributes: map[string]string{"image": image},
+	}
+}
+
+func TestLogNonContainerEvent(t *testing.T) {
+	e := New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	actor := eventtypes.Actor{ID: "vol", Attributes: map[string]string{"driver": "local"}}
+	e.Log("create", eventtypes.VolumeEventType, actor)
+	select {
+	case msg := <-l:
+		jmsg := msg.(*eventtypes.Message)
+		if jmsg.Type != eventtypes.VolumeEventType || jmsg.Action != "create" || jmsg.Actor.ID != "vol" {
+			t.Fatalf("Unexpected event %v", jmsg)
+		}
+		if jmsg.Status != "" || jmsg.ID != "" || jmsg.From != "" {
+			t.Fatalf("Expected the deprecated fields to be empty, got %v", jmsg)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for broadcasted message")
+	}
+}
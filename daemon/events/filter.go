@@ -1,7 +1,7 @@
 package events
 
 import (
-	"github.com/docker/docker/pkg/jsonmessage"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/filters"
 )
@@ -18,18 +18,48 @@ func NewFilter(filter filters.Args, getLabels func(id string) map[string]string)
 }
 
 // Include returns true when the event ev is included by the filters
-func (ef *Filter) Include(ev *jsonmessage.JSONMessage) bool {
-	return isFieldIncluded(ev.Status, ef.filter["event"]) &&
+func (ef *Filter) Include(ev *eventtypes.Message) bool {
+	return isFieldIncluded(ev.Type, ef.filter["type"]) &&
+		isFieldIncluded(ev.Action, ef.filter["event"]) &&
 		isFieldIncluded(ev.ID, ef.filter["container"]) &&
 		ef.isImageIncluded(ev.ID, ev.From) &&
-		ef.isLabelFieldIncluded(ev.ID)
+		ef.isActorIncluded(ev, eventtypes.VolumeEventType, "volume") &&
+		ef.isActorIncluded(ev, eventtypes.NetworkEventType, "network") &&
+		ef.isLabelFieldIncluded(ev)
 }
 
-func (ef *Filter) isLabelFieldIncluded(id string) bool {
+// The label filter is matched against the attributes of the event's actor,
+// which include the labels of containers, so that any event can be filtered
+// by its attributes. Image labels are not part of image events, so they are
+// looked up, as are the labels of events recorded before attributes existed.
+func (ef *Filter) isLabelFieldIncluded(ev *eventtypes.Message) bool {
 	if _, ok := ef.filter["label"]; !ok {
 		return true
 	}
-	return ef.filter.MatchKVList("label", ef.getLabels(id))
+	switch ev.Type {
+	case eventtypes.ImageEventType, "":
+		return ef.filter.MatchKVList("label", ef.getLabels(ev.ID))
+	}
+	return ef.filter.MatchKVList("label", ev.Actor.Attributes)
+}
+
+// isActorIncluded matches the field filter against the ID and the name of
+// the actor of events of the given type. Events of other types are
+// excluded when the filter is set.
+func (ef *Filter) isActorIncluded(ev *eventtypes.Message, eventType, field string) bool {
+	values, ok := ef.filter[field]
+	if !ok {
+		return true
+	}
+	if ev.Type != eventType {
+		return false
+	}
+	for _, v := range values {
+		if v == ev.Actor.ID || v == ev.Actor.Attributes["name"] {
+			return true
+		}
+	}
+	return false
 }
 
 // The image filter will be matched against both event.ID (for image events)
@@ -49,12 +79,12 @@ func (ef *Filter) isImageIncluded(eventID string, eventFrom string) bool {
 }
 
 func isFieldIncluded(field string, filter []string) bool {
-	if len(field) == 0 {
-		return true
-	}
 	if len(filter) == 0 {
 		return true
 	}
+	if len(field) == 0 {
+		return false
+	}
 	for _, v := range filter {
 		if v == field {
 			return true
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"testing"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func noLabels(id string) map[string]string {
+	return nil
+}
+
+func TestFilterTypedEvents(t *testing.T) {
+	container := &eventtypes.Message{
+		Status: "start",
+		ID:     "cont",
+		From:   "busybox",
+		Type:   eventtypes.ContainerEventType,
+		Action: "start",
+		Actor:  eventtypes.Actor{ID: "cont", Attributes: map[string]string{"image": "busybox", "name": "top"}},
+	}
+	volume := &eventtypes.Message{
+		Type:   eventtypes.VolumeEventType,
+		Action: "mount",
+		Actor:  eventtypes.Actor{ID: "data", Attributes: map[string]string{"driver": "local", "container": "cont"}},
+	}
+	network := &eventtypes.Message{
+		Type:   eventtypes.NetworkEventType,
+		Action: "connect",
+		Actor:  eventtypes.Actor{ID: "abcdef", Attributes: map[string]string{"name": "net1", "type": "bridge", "container": "other"}},
+	}
+
+	cases := []struct {
+		filter   filters.Args
+		expected []bool
+	}{
+		{filters.Args{}, []bool{true, true, true}},
+		{filters.Args{"type": {"volume", "network"}}, []bool{false, true, true}},
+		{filters.Args{"volume": {"data"}}, []bool{false, true, false}},
+		{filters.Args{"network": {"net1"}}, []bool{false, false, true}},
+		{filters.Args{"network": {"abcdef"}}, []bool{false, false, true}},
+		{filters.Args{"container": {"cont"}}, []bool{true, false, false}},
+		{filters.Args{"image": {"busybox"}}, []bool{true, false, false}},
+		{filters.Args{"label": {"driver=local"}}, []bool{false, true, false}},
+		{filters.Args{"label": {"type"}}, []bool{false, false, true}},
+		{filters.Args{"event": {"connect"}}, []bool{false, false, true}},
+	}
+
+	for _, c := range cases {
+		ef := NewFilter(c.filter, noLabels)
+		for i, ev := range []*eventtypes.Message{container, volume, network} {
+			if ef.Include(ev) != c.expected[i] {
+				t.Fatalf("Expected Include to be %v for %v with filter %v", c.expected[i], ev, c.filter)
+			}
+		}
+	}
+}
+
+func TestFilterLabelsOfImages(t *testing.T) {
+	image := &eventtypes.Message{
+		Status: "tag",
+		ID:     "busybox:latest",
+		Type:   eventtypes.ImageEventType,
+		Action: "tag",
+		Actor:  eventtypes.Actor{ID: "busybox:latest", Attributes: map[string]string{"name": "busybox:latest"}},
+	}
+	getLabels := func(id string) map[string]string {
+		if id == "busybox:latest" {
+			return map[string]string{"foo": "bar"}
+		}
+		return nil
+	}
+
+	if !NewFilter(filters.Args{"label": {"foo=bar"}}, getLabels).Include(image) {
+		t.Fatal("Expected the image labels to be matched")
+	}
+	if NewFilter(filters.Args{"label": {"name"}}, getLabels).Include(image) {
+		t.Fatal("Expected the image event attributes not to be matched as labels")
+	}
+}
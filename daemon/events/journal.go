@@ -0,0 +1,218 @@
+package events
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+var journalBucket = []byte("events")
+
+// journalReadSize is the number of events read from the journal in a single
+// transaction when replaying events.
+const journalReadSize = 256
+
+// JournalConfig holds the retention settings of an event journal.
+type JournalConfig struct {
+	// MaxEntries is the maximum number of events kept in the journal.
+	// Zero means no limit.
+	MaxEntries int
+	// MaxAge is the maximum age of the events kept in the journal.
+	// Zero means no limit.
+	MaxAge time.Duration
+}
+
+// Journal is an on-disk store of events, backed by a boltdb database.
+// Events are keyed by time, so that they can be replayed for any window
+// of time, and the oldest events are discarded according to the journal's
+// retention settings.
+type Journal struct {
+	mu     sync.Mutex
+	db     *bolt.DB
+	config JournalConfig
+	count  int
+}
+
+// NewJournal opens the journal stored at path, creating it if needed.
+func NewJournal(path string, config JournalConfig) (*Journal, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{
+		db:     db,
+		config: config,
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(journalBucket)
+		if err != nil {
+			return err
+		}
+		j.count, err = j.prune(b, b.Stats().KeyN, time.Now())
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Write stores an event in the journal, discarding old events if the
+// journal is over its retention limits.
+func (j *Journal) Write(jm *eventtypes.Message) error {
+	return j.WriteBatch([]*eventtypes.Message{jm})
+}
+
+// WriteBatch stores events in the journal in a single transaction,
+// discarding old events if the journal is over its retention limits.
+func (j *Journal) WriteBatch(events []*eventtypes.Message) error {
+	if len(events) == 0 {
+		return nil
+	}
+	values := make([][]byte, len(events))
+	for i, jm := range events {
+		value, err := json.Marshal(jm)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var count int
+	if err := j.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(journalBucket)
+		for i, jm := range events {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := b.Put(journalKey(jm.TimeNano, seq), values[i]); err != nil {
+				return err
+			}
+		}
+		var err error
+		count, err = j.prune(b, j.count+len(events), time.Unix(0, events[len(events)-1].TimeNano))
+		return err
+	}); err != nil {
+		return err
+	}
+	j.count = count
+	return nil
+}
+
+// Walk calls fn for each event stored in the journal with
+// since <= time < until, oldest first, and stops at the first error returned
+// by fn. A zero since or until means there is no bound. The events are read
+// in small transactions, so that fn can take its time, e.g. to send the
+// events to a client, without holding the whole replay in memory.
+func (j *Journal) Walk(since, until time.Time, fn func(*eventtypes.Message) error) error {
+	var start []byte
+	if !since.IsZero() {
+		start = journalKey(since.UnixNano(), 0)
+	}
+	for {
+		var events []*eventtypes.Message
+		if err := j.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(journalBucket).Cursor()
+			k, v := c.First()
+			if start != nil {
+				k, v = c.Seek(start)
+			}
+			for ; k != nil && len(events) < journalReadSize; k, v = c.Next() {
+				if !until.IsZero() && keyTime(k) >= until.UnixNano() {
+					break
+				}
+				jm := &eventtypes.Message{}
+				if err := json.Unmarshal(v, jm); err != nil {
+					return err
+				}
+				// Events recorded before typed events only have the
+				// deprecated fields set
+				if jm.Action == "" {
+					jm.Action = jm.Status
+					jm.Actor.ID = jm.ID
+				}
+				events = append(events, jm)
+				// Resume after this key in the next transaction
+				start = nextKey(k)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, jm := range events {
+			if err := fn(jm); err != nil {
+				return err
+			}
+		}
+		if len(events) < journalReadSize {
+			return nil
+		}
+	}
+}
+
+// Close closes the journal's database.
+func (j *Journal) Close() error {
+	return j.db.Close()
+}
+
+// prune discards the events older than the journal's maximum age, and the
+// oldest events above its maximum number of entries. It returns the number
+// of events left in the bucket, given that it held count events.
+func (j *Journal) prune(b *bolt.Bucket, count int, now time.Time) (int, error) {
+	var (
+		expired [][]byte
+		c       = b.Cursor()
+	)
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		old := j.config.MaxAge > 0 && keyTime(k) < now.Add(-j.config.MaxAge).UnixNano()
+		full := j.config.MaxEntries > 0 && count > j.config.MaxEntries
+		if !old && !full {
+			break
+		}
+		// Copy the key, as it is only valid during the transaction and
+		// deleting while iterating would move the cursor
+		expired = append(expired, append([]byte(nil), k...))
+		count--
+	}
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// journalKey returns the key of an event: its time followed by a sequence
+// number, so that keys sort chronologically and never collide.
+func journalKey(timeNano int64, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(timeNano))
+	binary.BigEndian.PutUint64(k[8:], seq)
+	return k
+}
+
+// nextKey returns the smallest key greater than k.
+func nextKey(k []byte) []byte {
+	next := append([]byte(nil), k...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func keyTime(k []byte) int64 {
+	return int64(binary.BigEndian.Uint64(k[:8]))
+}
@@ -0,0 +1,244 @@
+package events
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+func newTestJournal(t *testing.T, config JournalConfig) (*Journal, string) {
+	dir, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := NewJournal(filepath.Join(dir, "events.db"), config)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return j, dir
+}
+
+func writeEvents(t *testing.T, j *Journal, start time.Time, n int) {
+	for i := 0; i < n; i++ {
+		ts := start.Add(time.Duration(i) * time.Second)
+		jm := &eventtypes.Message{
+			Type:     eventtypes.ContainerEventType,
+			Action:   "action",
+			Actor:    eventtypes.Actor{ID: fmt.Sprintf("%d", i)},
+			Time:     ts.Unix(),
+			TimeNano: ts.UnixNano(),
+		}
+		if err := j.Write(jm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readEvents returns the events of the journal with since <= time < until.
+func readEvents(t *testing.T, j *Journal, since, until time.Time) []*eventtypes.Message {
+	var events []*eventtypes.Message
+	if err := j.Walk(since, until, func(jm *eventtypes.Message) error {
+		events = append(events, jm)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestJournalReadWindow(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{})
+	defer os.RemoveAll(dir)
+	defer j.Close()
+
+	start := time.Unix(1000, 0)
+	writeEvents(t, j, start, 10)
+
+	all := readEvents(t, j, time.Time{}, time.Time{})
+	if len(all) != 10 {
+		t.Fatalf("Expected 10 events, got %d", len(all))
+	}
+
+	window := readEvents(t, j, start.Add(3*time.Second), start.Add(6*time.Second))
+	if len(window) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(window))
+	}
+	for i, jm := range window {
+		if jm.Actor.ID != fmt.Sprintf("%d", i+3) {
+			t.Fatalf("Expected event %d, got %s", i+3, jm.ID)
+		}
+	}
+}
+
+func TestJournalWalkChunks(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{})
+	defer os.RemoveAll(dir)
+	defer j.Close()
+
+	// Several events with the same time, over several read transactions
+	n := 2*journalReadSize + 10
+	start := time.Unix(1000, 0)
+	var batch []*eventtypes.Message
+	for i := 0; i < n; i++ {
+		batch = append(batch, &eventtypes.Message{
+			Action:   "action",
+			Actor:    eventtypes.Actor{ID: fmt.Sprintf("%d", i)},
+			TimeNano: start.Add(time.Duration(i/2) * time.Second).UnixNano(),
+		})
+	}
+	if err := j.WriteBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	events := readEvents(t, j, time.Time{}, time.Time{})
+	if len(events) != n {
+		t.Fatalf("Expected %d events, got %d", n, len(events))
+	}
+	for i, jm := range events {
+		if jm.Actor.ID != fmt.Sprintf("%d", i) {
+			t.Fatalf("Expected event %d, got %s", i, jm.Actor.ID)
+		}
+	}
+
+	// The walk stops at the first error
+	var count int
+	stop := fmt.Errorf("stop")
+	if err := j.Walk(time.Time{}, time.Time{}, func(*eventtypes.Message) error {
+		count++
+		if count == journalReadSize+1 {
+			return stop
+		}
+		return nil
+	}); err != stop {
+		t.Fatalf("Expected the error of the walk function, got %v", err)
+	}
+	if count != journalReadSize+1 {
+		t.Fatalf("Expected the walk to stop after %d events, got %d", journalReadSize+1, count)
+	}
+}
+
+func TestJournalMaxEntries(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{MaxEntries: 5})
+	defer os.RemoveAll(dir)
+	defer j.Close()
+
+	writeEvents(t, j, time.Unix(1000, 0), 8)
+
+	events := readEvents(t, j, time.Time{}, time.Time{})
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %d", len(events))
+	}
+	if events[0].Actor.ID != "3" {
+		t.Fatalf("Expected the oldest events to be discarded, first event is %s", events[0].Actor.ID)
+	}
+}
+
+func TestJournalMaxAge(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{MaxAge: 5 * time.Second})
+	defer os.RemoveAll(dir)
+	defer j.Close()
+
+	writeEvents(t, j, time.Unix(1000, 0), 10)
+
+	events := readEvents(t, j, time.Time{}, time.Time{})
+	// The last event is at 1009, so events before 1004 have expired
+	if len(events) != 6 {
+		t.Fatalf("Expected 6 events, got %d", len(events))
+	}
+	if events[0].Actor.ID != "4" {
+		t.Fatalf("Expected the first event to be 4, got %s", events[0].Actor.ID)
+	}
+}
+
+func TestJournalReopen(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{})
+	defer os.RemoveAll(dir)
+
+	writeEvents(t, j, time.Unix(1000, 0), 4)
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening with a smaller limit prunes the stored events
+	j, err := NewJournal(filepath.Join(dir, "events.db"), JournalConfig{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	events := readEvents(t, j, time.Time{}, time.Time{})
+	if len(events) != 2 || events[0].Actor.ID != "2" {
+		t.Fatalf("Expected events 2 and 3 to be kept, got %v", events)
+	}
+}
+
+func TestEventsSubscribeRange(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{})
+	defer os.RemoveAll(dir)
+
+	e := NewWithJournal(j)
+	defer e.Close()
+
+	// More events than the in-memory buffer holds
+	for i := 0; i < eventsLimit+10; i++ {
+		e.Log("action", eventtypes.ContainerEventType, containerActor(fmt.Sprintf("%d", i), "image"))
+	}
+
+	replay, l, cancel := e.SubscribeRange(time.Unix(0, 0), time.Time{})
+	defer cancel()
+	if l == nil {
+		t.Fatal("Expected a listener")
+	}
+	// The events logged after subscribing are only sent to the listener
+	e.Log("action", eventtypes.ContainerEventType, containerActor("after", "image"))
+
+	var current []*eventtypes.Message
+	if err := replay(func(jm *eventtypes.Message) error {
+		current = append(current, jm)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(current) != eventsLimit+10 {
+		t.Fatalf("Expected %d events, got %d", eventsLimit+10, len(current))
+	}
+	if current[0].ID != "0" {
+		t.Fatalf("Expected the first event to be 0, got %s", current[0].ID)
+	}
+	select {
+	case msg := <-l:
+		if jm := msg.(*eventtypes.Message); jm.ID != "after" {
+			t.Fatalf("Expected the event logged after subscribing, got %s", jm.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for broadcasted message")
+	}
+}
+
+func TestEventsCloseWritesQueuedEvents(t *testing.T) {
+	j, dir := newTestJournal(t, JournalConfig{})
+	defer os.RemoveAll(dir)
+
+	e := NewWithJournal(j)
+	for i := 0; i < 2*journalBatchSize; i++ {
+		e.Log("action", eventtypes.ContainerEventType, containerActor(fmt.Sprintf("%d", i), "image"))
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := NewJournal(filepath.Join(dir, "events.db"), JournalConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if events := readEvents(t, j, time.Time{}, time.Time{}); len(events) != 2*journalBatchSize {
+		t.Fatalf("Expected %d events, got %d", 2*journalBatchSize, len(events))
+	}
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +34,11 @@ type ExecConfig struct {
 	OpenStdout bool
 	Container  *Container
 	canRemove  bool
+	// internal is true for the commands run by the daemon itself, such as
+	// the health check probes, for which no event is logged.
+	internal bool
+	// pid is the pid of the process, once it is started.
+	pid int
 
 	// waitStart will be closed immediately after the exec is really started.
 	waitStart chan struct{}
@@ -147,14 +153,24 @@ func (d *Daemon) getActiveContainer(name string) (*Container, error) {
 
 // ContainerExecCreate sets up an exec in a running container.
 func (d *Daemon) ContainerExecCreate(config *runconfig.ExecConfig) (string, error) {
+	ec, err := d.createExec(config, false)
+	if err != nil {
+		return "", err
+	}
+	return ec.ID, nil
+}
+
+// createExec sets up an exec in a running container. The events of the exec
+// are not logged if it is internal to the daemon.
+func (d *Daemon) createExec(config *runconfig.ExecConfig, internal bool) (*ExecConfig, error) {
 	// Not all drivers support Exec (LXC for example)
 	if err := checkExecSupport(d.execDriver.Name()); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	container, err := d.getActiveContainer(config.Container)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	cmd := stringutils.NewStrSlice(config.Cmd...)
@@ -183,13 +199,16 @@ func (d *Daemon) ContainerExecCreate(config *runconfig.ExecConfig) (string, erro
 		Container:     container,
 		Running:       false,
 		waitStart:     make(chan struct{}),
+		internal:      internal,
 	}
 
 	d.registerExecCommand(ExecConfig)
 
-	container.logEvent("exec_create: " + ExecConfig.ProcessConfig.Entrypoint + " " + strings.Join(ExecConfig.ProcessConfig.Arguments, " "))
+	if !internal {
+		container.logEvent("exec_create: " + ExecConfig.ProcessConfig.Entrypoint + " " + strings.Join(ExecConfig.ProcessConfig.Arguments, " "))
+	}
 
-	return ExecConfig.ID, nil
+	return ExecConfig, nil
 }
 
 // ContainerExecStart starts a previously set up exec instance. The
@@ -215,7 +234,9 @@ func (d *Daemon) ContainerExecStart(name string, stdin io.ReadCloser, stdout io.
 
 	logrus.Debugf("starting exec command %s in container %s", ec.ID, ec.Container.ID)
 	container := ec.Container
-	container.logEvent("exec_start: " + ec.ProcessConfig.Entrypoint + " " + strings.Join(ec.ProcessConfig.Arguments, " "))
+	if !ec.internal {
+		container.logEvent("exec_start: " + ec.ProcessConfig.Entrypoint + " " + strings.Join(ec.ProcessConfig.Arguments, " "))
+	}
 
 	if ec.OpenStdin {
 		r, w := io.Pipe()
@@ -288,12 +309,28 @@ func (d *Daemon) Exec(c *Container, ExecConfig *ExecConfig, pipes *execdriver.Pi
 		exitStatus = 128
 	}
 
+	ExecConfig.Lock()
 	ExecConfig.ExitCode = exitStatus
 	ExecConfig.Running = false
+	ExecConfig.Unlock()
 
 	return exitStatus, err
 }
 
+// killExec kills the process of an exec, if it is still running.
+func (d *Daemon) killExec(ec *ExecConfig) error {
+	ec.Lock()
+	defer ec.Unlock()
+	if !ec.Running || ec.pid == 0 {
+		return nil
+	}
+	p, err := os.FindProcess(ec.pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
 // execCommandGC runs a ticker to clean up the daemon references
 // of exec configs that are no longer part of the container.
 func (d *Daemon) execCommandGC() {
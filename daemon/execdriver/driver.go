@@ -106,6 +106,42 @@ type Driver interface {
 
 	// SupportsHooks refers to the driver capability to exploit pre/post hook functionality
 	SupportsHooks() bool
+
+	// Update updates the resource configs of a running container
+	// according to the Resources in the given command.
+	Update(c *Command) error
+}
+
+// Restorer is implemented by the drivers which can leave the processes of
+// the containers running when the daemon exits, and re-attach to them when
+// it starts again.
+type Restorer interface {
+	// Restorable returns whether the container with the given id was left
+	// running by a previous daemon, with its output kept open.
+	Restorable(id string) bool
+
+	// Restore re-attaches to the process of a container left running by a
+	// previous daemon. Like Run, it calls the Start hook, blocks until the
+	// process exits and returns its exit status. It returns immediately if
+	// the process exited while the daemon was down.
+	Restore(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
+
+	// Detach is called when the daemon exits leaving containers running. The
+	// driver records the exit status of their processes until the next
+	// daemon restores them.
+	Detach() error
+}
+
+// Checkpointer is implemented by the drivers which can save the state of the
+// processes of a running container to disk and restore them later.
+type Checkpointer interface {
+	// Checkpoint saves the state of the processes of the container in dir.
+	// The processes are killed once saved, unless leaveRunning is true.
+	Checkpoint(c *Command, dir string, leaveRunning bool) error
+
+	// RunCheckpoint is like Run, but restores the processes saved in dir
+	// instead of starting the command of the container.
+	RunCheckpoint(c *Command, pipes *Pipes, hooks Hooks, dir string) (ExitStatus, error)
 }
 
 // Ipc settings of the container
@@ -155,6 +191,13 @@ type Resources struct {
 	Rlimits           []*ulimit.Rlimit `json:"rlimits"`
 	OomKillDisable    bool             `json:"oom_kill_disable"`
 	MemorySwappiness  int64            `json:"memory_swappiness"`
+
+	BlkioWeightDevice            []*configs.WeightDevice   `json:"blkio_weight_device"`
+	BlkioThrottleReadBpsDevice   []*configs.ThrottleDevice `json:"blkio_throttle_read_bps_device"`
+	BlkioThrottleWriteBpsDevice  []*configs.ThrottleDevice `json:"blkio_throttle_write_bps_device"`
+	BlkioThrottleReadIOpsDevice  []*configs.ThrottleDevice `json:"blkio_throttle_read_iops_device"`
+	BlkioThrottleWriteIOpsDevice []*configs.ThrottleDevice `json:"blkio_throttle_write_iops_device"`
+	PidsLimit                    int64                     `json:"pids_limit"`
 }
 
 // ResourceStats contains information about resource usage by a container.
@@ -222,4 +265,5 @@ type Command struct {
 	LayerPaths         []string          `json:"layer_paths"` // Windows needs to know the layer paths and folder for a command
 	LayerFolder        string            `json:"layer_folder"`
 	Hostname           string            `json:"hostname"` // Windows sets the hostname in the execdriver
+	LiveRestore        bool              `json:"live_restore"`
 }
@@ -899,6 +899,44 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	return execdriver.Stats(d.containerDir(id), d.activeContainers[id].container.Cgroups.Memory, d.machineMemory)
 }
 
+// Update implements the exec driver Driver interface,
+// it executes lxc-cgroup to change the resources of a running container.
+func (d *Driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	_, ok := d.activeContainers[c.ID]
+	d.Unlock()
+	if !ok || c.Resources == nil {
+		return execdriver.ErrNotRunning
+	}
+
+	r := c.Resources
+	settings := []struct {
+		key   string
+		value string
+		set   bool
+	}{
+		{"memory.soft_limit_in_bytes", strconv.FormatInt(r.MemoryReservation, 10), r.MemoryReservation > 0},
+		{"memory.limit_in_bytes", strconv.FormatInt(r.Memory, 10), r.Memory > 0},
+		{"memory.memsw.limit_in_bytes", strconv.FormatInt(r.MemorySwap, 10), r.MemorySwap > 0},
+		{"memory.kmem.limit_in_bytes", strconv.FormatInt(r.KernelMemory, 10), r.KernelMemory > 0},
+		{"cpu.shares", strconv.FormatInt(r.CPUShares, 10), r.CPUShares > 0},
+		{"cpu.cfs_period_us", strconv.FormatInt(r.CPUPeriod, 10), r.CPUPeriod > 0},
+		{"cpu.cfs_quota_us", strconv.FormatInt(r.CPUQuota, 10), r.CPUQuota != 0},
+		{"cpuset.cpus", r.CpusetCpus, r.CpusetCpus != ""},
+		{"cpuset.mems", r.CpusetMems, r.CpusetMems != ""},
+		{"blkio.weight", strconv.Itoa(int(r.BlkioWeight)), r.BlkioWeight > 0},
+	}
+	for _, s := range settings {
+		if !s.set {
+			continue
+		}
+		if output, err := exec.Command("lxc-cgroup", "-n", c.ID, s.key, s.value).CombinedOutput(); err != nil {
+			return fmt.Errorf("Err: %s Output: %s", err, output)
+		}
+	}
+	return nil
+}
+
 // SupportsHooks implements the execdriver Driver interface.
 // The LXC execdriver does not support the hook mechanism, which is currently unique to runC/libcontainer.
 func (d *Driver) SupportsHooks() bool {
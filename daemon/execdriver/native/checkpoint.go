@@ -0,0 +1,114 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// Checkpoint implements the execdriver Checkpointer interface, it saves the
+// processes of the container with CRIU.
+func (d *Driver) Checkpoint(c *execdriver.Command, dir string, leaveRunning bool) error {
+	d.Lock()
+	active := d.activeContainers[c.ID]
+	d.Unlock()
+	if active == nil {
+		return fmt.Errorf("active container for %s does not exist", c.ID)
+	}
+
+	return active.Checkpoint(&libcontainer.CriuOpts{
+		ImagesDirectory: dir,
+		LeaveRunning:    leaveRunning,
+		FileLocks:       true,
+	})
+}
+
+// RunCheckpoint implements the execdriver Checkpointer interface, it
+// restores the processes saved in dir with CRIU.
+func (d *Driver) RunCheckpoint(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, dir string) (execdriver.ExitStatus, error) {
+	return d.run(c, pipes, hooks, func(cont libcontainer.Container, p *libcontainer.Process) error {
+		if err := cont.Restore(p, &libcontainer.CriuOpts{ImagesDirectory: dir, FileLocks: true}); err != nil {
+			return err
+		}
+		pid, err := p.Pid()
+		if err != nil {
+			return err
+		}
+		config := cont.Config()
+		// The resources are only applied when the container starts
+		if err := cont.Set(config); err != nil {
+			return err
+		}
+		return attachRestoredNetwork(&config, c.ID, pid)
+	})
+}
+
+// attachRestoredNetwork connects the restored processes to the network of
+// the container. CRIU restores the network namespace of the container with
+// the interfaces it had when it was checkpointed, which are replaced by the
+// ones of its new endpoints by the prestart hooks, which only run when a
+// container starts.
+func attachRestoredNetwork(config *configs.Config, id string, pid int) error {
+	if config.Hooks == nil || len(config.Hooks.Prestart) == 0 {
+		return nil
+	}
+	if err := removeVeths(pid); err != nil {
+		return err
+	}
+	state := configs.HookState{
+		Version: config.Version,
+		ID:      id,
+		Pid:     pid,
+		Root:    config.Rootfs,
+	}
+	for _, hook := range config.Hooks.Prestart {
+		if err := hook.Run(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeVeths removes the veth interfaces from the network namespace of the
+// process with the given pid, which removes their peers on the host.
+func removeVeths(pid int) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origns, err := netns.Get()
+	if err != nil {
+		return err
+	}
+	defer origns.Close()
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	if err := netns.Set(ns); err != nil {
+		return err
+	}
+	defer netns.Set(origns)
+
+	links, err := netlink.LinkList()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		if link.Type() != "veth" {
+			continue
+		}
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to remove the restored interface %s: %v", link.Attrs().Name, err)
+		}
+	}
+	return nil
+}
@@ -40,6 +40,7 @@ type Driver struct {
 	root             string
 	initPath         string
 	activeContainers map[string]libcontainer.Container
+	liveContainers   map[string]*liveContainer
 	machineMemory    int64
 	factory          libcontainer.Factory
 	sync.Mutex
@@ -115,13 +116,16 @@ func NewDriver(root, initPath string, options []string) (*Driver, error) {
 		return nil, err
 	}
 
-	return &Driver{
+	d := &Driver{
 		root:             root,
 		initPath:         initPath,
 		activeContainers: make(map[string]libcontainer.Container),
+		liveContainers:   make(map[string]*liveContainer),
 		machineMemory:    meminfo.MemTotal,
 		factory:          f,
-	}, nil
+	}
+	d.restoreLiveContainers()
+	return d, nil
 }
 
 type execOutput struct {
@@ -132,6 +136,13 @@ type execOutput struct {
 // Run implements the exec driver Driver interface,
 // it calls libcontainer APIs to run a container.
 func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	return d.run(c, pipes, hooks, func(cont libcontainer.Container, p *libcontainer.Process) error {
+		return cont.Start(p)
+	})
+}
+
+// run runs a container whose processes are started by the start function.
+func (d *Driver) run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks, start func(libcontainer.Container, *libcontainer.Process) error) (execdriver.ExitStatus, error) {
 	// take the Command and populate the libcontainer.Config from it
 	container, err := d.createContainer(c, hooks)
 	if err != nil {
@@ -145,8 +156,10 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		User: c.ProcessConfig.User,
 	}
 
-	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
-		return execdriver.ExitStatus{ExitCode: -1}, err
+	if !c.LiveRestore {
+		if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
 	}
 
 	cont, err := d.factory.Create(c.ID, container)
@@ -161,7 +174,17 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		d.cleanContainer(c.ID)
 	}()
 
-	if err := cont.Start(p); err != nil {
+	var processFiles []*os.File
+	if c.LiveRestore {
+		// The FIFOs are created in the directory of the container
+		if processFiles, err = d.setupLivePipes(c.ID, container, &c.ProcessConfig, p, pipes); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+	}
+
+	err = start(cont, p)
+	closeFiles(processFiles)
+	if err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
 
@@ -190,9 +213,14 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		}
 		ps = execErr.ProcessState
 	}
+	exitCode := utils.ExitStatus(ps.Sys().(syscall.WaitStatus))
+	// The processes of a checkpointed container are killed once saved
+	if status, err := cont.Status(); err == nil && status == libcontainer.Checkpointed {
+		exitCode = 0
+	}
 	cont.Destroy()
 	_, oomKill := <-oom
-	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill}, nil
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKill}, nil
 }
 
 // notifyOnOOM returns a channel that signals if the container received an OOM notification
@@ -389,6 +417,22 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 	}, nil
 }
 
+// Update implements the exec driver Driver interface,
+// it applies the command's resources to the cgroups of a running container.
+func (d *Driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	cont := d.activeContainers[c.ID]
+	d.Unlock()
+	if cont == nil {
+		return execdriver.ErrNotRunning
+	}
+	config := cont.Config()
+	if err := execdriver.SetupCgroups(&config, c); err != nil {
+		return err
+	}
+	return cont.Set(config)
+}
+
 // TtyConsole implements the exec driver Terminal interface.
 type TtyConsole struct {
 	console libcontainer.Console
@@ -0,0 +1,208 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink/nl"
+)
+
+// The process of a restored container is not a child of the daemon, which
+// cannot wait for it. Its exit status is read instead from the process events
+// connector of the kernel, which reports the exit of every process of the
+// host to the listeners of its netlink socket.
+
+const (
+	cnIdxProc         = 0x1
+	cnValProc         = 0x1
+	procCnMcastListen = 1
+	procEventExit     = 0x80000000
+
+	// The messages are a netlink header, a connector header and the
+	// process event, whose data follows its 16 bytes header.
+	nlMsgHdrLen     = 16
+	cnMsgHdrLen     = 20
+	procEventHdrLen = 16
+)
+
+// exitWatcher reports the exit status of the processes it watches.
+type exitWatcher struct {
+	fd int
+	sync.Mutex
+	waiters map[int]chan syscall.WaitStatus
+	stopped bool
+}
+
+// newExitWatcher subscribes to the process events of the kernel. The
+// watcher stops when the last process it watches exits.
+func newExitWatcher() (*exitWatcher, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, err
+	}
+	if err := subscribeProcEvents(fd); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	w := &exitWatcher{
+		fd:      fd,
+		waiters: make(map[int]chan syscall.WaitStatus),
+	}
+	go w.loop()
+	return w, nil
+}
+
+func subscribeProcEvents(fd int) error {
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		return err
+	}
+	// The receive timeout lets the loop notice the watcher was stopped
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &syscall.Timeval{Sec: 1}); err != nil {
+		return err
+	}
+	return syscall.Sendto(fd, procEventsListenMsg(), 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+}
+
+// procEventsListenMsg returns the message asking the connector for the
+// process events.
+func procEventsListenMsg() []byte {
+	native := nl.NativeEndian()
+	b := make([]byte, nlMsgHdrLen+cnMsgHdrLen+4)
+	native.PutUint32(b[0:], uint32(len(b)))
+	native.PutUint16(b[4:], syscall.NLMSG_DONE)
+	native.PutUint32(b[12:], uint32(os.Getpid()))
+	native.PutUint32(b[nlMsgHdrLen:], cnIdxProc)
+	native.PutUint32(b[nlMsgHdrLen+4:], cnValProc)
+	native.PutUint16(b[nlMsgHdrLen+16:], 4)
+	native.PutUint32(b[nlMsgHdrLen+cnMsgHdrLen:], procCnMcastListen)
+	return b
+}
+
+// parseExitEvent returns the pid and wait status of the process whose exit
+// the data of a connector message reports. It returns false for the other
+// events and for the exit of the threads which are not the main thread of
+// their process.
+func parseExitEvent(data []byte) (int, syscall.WaitStatus, bool) {
+	native := nl.NativeEndian()
+	const exitData = cnMsgHdrLen + procEventHdrLen
+	if len(data) < exitData+12 {
+		return 0, 0, false
+	}
+	if native.Uint32(data[0:]) != cnIdxProc || native.Uint32(data[4:]) != cnValProc {
+		return 0, 0, false
+	}
+	if native.Uint32(data[cnMsgHdrLen:]) != procEventExit {
+		return 0, 0, false
+	}
+	pid := native.Uint32(data[exitData:])
+	tgid := native.Uint32(data[exitData+4:])
+	if pid != tgid {
+		return 0, 0, false
+	}
+	return int(pid), syscall.WaitStatus(native.Uint32(data[exitData+8:])), true
+}
+
+// watch returns a channel receiving the exit status of the process with the
+// given pid. The channel is closed without a value if the status is lost.
+// The process may have exited before it is watched, which the caller checks
+// after calling watch.
+func (w *exitWatcher) watch(pid int) <-chan syscall.WaitStatus {
+	w.Lock()
+	defer w.Unlock()
+	ch := make(chan syscall.WaitStatus, 1)
+	if w.stopped {
+		close(ch)
+		return ch
+	}
+	w.waiters[pid] = ch
+	return ch
+}
+
+// unwatch stops watching the process with the given pid.
+func (w *exitWatcher) unwatch(pid int) {
+	w.Lock()
+	defer w.Unlock()
+	if ch, ok := w.waiters[pid]; ok {
+		delete(w.waiters, pid)
+		close(ch)
+		w.stopped = len(w.waiters) == 0
+	}
+}
+
+func (w *exitWatcher) exited(pid int, status syscall.WaitStatus) {
+	w.Lock()
+	defer w.Unlock()
+	if ch, ok := w.waiters[pid]; ok {
+		delete(w.waiters, pid)
+		ch <- status
+		close(ch)
+		w.stopped = len(w.waiters) == 0
+	}
+}
+
+// checkLost closes the channels of the processes which exited while events
+// were lost.
+func (w *exitWatcher) checkLost() {
+	w.Lock()
+	defer w.Unlock()
+	for pid, ch := range w.waiters {
+		if err := syscall.Kill(pid, 0); err == syscall.ESRCH {
+			logrus.Warnf("The exit status of process %d was lost", pid)
+			delete(w.waiters, pid)
+			close(ch)
+		}
+	}
+	w.stopped = len(w.waiters) == 0
+}
+
+func (w *exitWatcher) isStopped() bool {
+	w.Lock()
+	defer w.Unlock()
+	return w.stopped
+}
+
+func (w *exitWatcher) loop() {
+	defer syscall.Close(w.fd)
+
+	buf := make([]byte, os.Getpagesize())
+	for !w.isStopped() {
+		n, _, err := syscall.Recvfrom(w.fd, buf, 0)
+		if err != nil {
+			switch err {
+			case syscall.EAGAIN, syscall.EINTR:
+			case syscall.ENOBUFS:
+				w.checkLost()
+			default:
+				logrus.Errorf("Error receiving the process events: %v", err)
+				w.stopAll()
+				return
+			}
+			continue
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, m := range msgs {
+			if pid, status, ok := parseExitEvent(m.Data); ok {
+				w.exited(pid, status)
+			}
+		}
+	}
+}
+
+// stopAll stops the watcher, closing the channels of all the processes.
+func (w *exitWatcher) stopAll() {
+	w.Lock()
+	defer w.Unlock()
+	for pid, ch := range w.waiters {
+		delete(w.waiters, pid)
+		close(ch)
+	}
+	w.stopped = true
+}
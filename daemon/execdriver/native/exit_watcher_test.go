@@ -0,0 +1,75 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/runc/libcontainer/utils"
+	"github.com/vishvananda/netlink/nl"
+)
+
+func exitEventData(what uint32, pid, tgid int, status syscall.WaitStatus) []byte {
+	native := nl.NativeEndian()
+	data := make([]byte, cnMsgHdrLen+procEventHdrLen+16)
+	native.PutUint32(data[0:], cnIdxProc)
+	native.PutUint32(data[4:], cnValProc)
+	native.PutUint32(data[cnMsgHdrLen:], what)
+	native.PutUint32(data[cnMsgHdrLen+procEventHdrLen:], uint32(pid))
+	native.PutUint32(data[cnMsgHdrLen+procEventHdrLen+4:], uint32(tgid))
+	native.PutUint32(data[cnMsgHdrLen+procEventHdrLen+8:], uint32(status))
+	return data
+}
+
+func TestParseExitEvent(t *testing.T) {
+	pid, status, ok := parseExitEvent(exitEventData(procEventExit, 42, 42, 3<<8))
+	if !ok || pid != 42 || utils.ExitStatus(status) != 3 {
+		t.Fatalf("Expected the exit of process 42 with code 3, got %d, %d and %v", pid, utils.ExitStatus(status), ok)
+	}
+
+	// The exit of a thread, a fork event and a truncated message are ignored
+	for _, data := range [][]byte{
+		exitEventData(procEventExit, 43, 42, 0),
+		exitEventData(0x1, 42, 42, 0),
+		exitEventData(procEventExit, 42, 42, 0)[:cnMsgHdrLen+procEventHdrLen],
+	} {
+		if _, _, ok := parseExitEvent(data); ok {
+			t.Fatalf("Expected no exit event in %v", data)
+		}
+	}
+}
+
+func TestExitWatcher(t *testing.T) {
+	w, err := newExitWatcher()
+	if err != nil {
+		t.Skipf("Cannot subscribe to the process events: %v", err)
+	}
+	defer w.stopAll()
+
+	cmd := exec.Command("sh", "-c", "read x; exit 3")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := w.watch(cmd.Process.Pid)
+	stdin.Close()
+	cmd.Wait()
+
+	select {
+	case status, ok := <-exited:
+		if !ok || utils.ExitStatus(status) != 3 {
+			t.Fatalf("Expected the exit code 3, got %d (%v)", utils.ExitStatus(status), ok)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timeout waiting for the exit of the process")
+	}
+	if !w.isStopped() {
+		t.Fatal("Expected the watcher to stop once no process is watched")
+	}
+}
@@ -0,0 +1,475 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/system"
+	"github.com/opencontainers/runc/libcontainer/utils"
+)
+
+// The output of the containers kept running when the daemon exits goes
+// through FIFOs in their directory, which their processes keep open, instead
+// of pipes to the daemon. When the daemon exits leaving containers running, it
+// starts a helper process recording the exit status of their processes until
+// the next daemon watches them itself.
+
+const (
+	exitWatcherName    = "docker-exit-watcher"
+	exitWatcherPidFile = "exit-watcher.pid"
+	exitStatusFile     = "exit-status"
+)
+
+// liveStreams are the names of the FIFOs of the output of a container.
+var liveStreams = []string{"stdout", "stderr"}
+
+func init() {
+	reexec.Register(exitWatcherName, recordExits)
+}
+
+// liveContainer is a container left running by a previous daemon.
+type liveContainer struct {
+	// exited receives the exit status of the process of the container. It
+	// is nil if the process exited while the daemon was down.
+	exited <-chan syscall.WaitStatus
+	// status is the exit status of a process which exited while the daemon
+	// was down, if it was recorded.
+	status      syscall.WaitStatus
+	statusKnown bool
+}
+
+// setupLivePipes connects the output of the process to the FIFOs of the
+// container. It returns the files of the process side of the FIFOs, which
+// the caller closes once the process started.
+func (d *Driver) setupLivePipes(id string, container *configs.Config, processConfig *execdriver.ProcessConfig, p *libcontainer.Process, pipes *execdriver.Pipes) ([]*os.File, error) {
+	rootuid, err := container.HostUID()
+	if err != nil {
+		return nil, err
+	}
+	rootgid, err := container.HostGID()
+	if err != nil {
+		return nil, err
+	}
+
+	term := &execdriver.StdConsole{}
+	processConfig.Terminal = term
+
+	var files []*os.File
+	for _, name := range liveStreams {
+		path := filepath.Join(d.root, id, name)
+		if err := syscall.Mkfifo(path, 0600); err != nil {
+			closeFiles(files)
+			return nil, fmt.Errorf("Failed to create FIFO %s: %v", path, err)
+		}
+		if err := os.Chown(path, rootuid, rootgid); err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		r, err := openFifoReader(path)
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		term.Closers = append(term.Closers, r)
+		// The process opens the FIFO for reading as well, so that it does
+		// not get a broken pipe when no daemon reads it.
+		w, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		files = append(files, w)
+		copyStream(pipes, name, r)
+	}
+	p.Stdout, p.Stderr = files[0], files[1]
+
+	if pipes.Stdin != nil {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		if err := syscall.Fchown(int(r.Fd()), rootuid, rootgid); err != nil {
+			closeFiles(append(files, r, w))
+			return nil, fmt.Errorf("Failed to chown pipes fd: %v", err)
+		}
+		go func() {
+			io.Copy(w, pipes.Stdin)
+			w.Close()
+		}()
+		p.Stdin = r
+		files = append(files, r)
+	}
+	return files, nil
+}
+
+// attachLivePipes opens the FIFOs of the output of a restored container
+// again. The input of the container is not restored.
+func (d *Driver) attachLivePipes(c *execdriver.Command, pipes *execdriver.Pipes) error {
+	term := &execdriver.StdConsole{}
+	c.ProcessConfig.Terminal = term
+	for _, name := range liveStreams {
+		r, err := openFifoReader(filepath.Join(d.root, c.ID, name))
+		if err != nil {
+			term.Close()
+			return err
+		}
+		term.Closers = append(term.Closers, r)
+		copyStream(pipes, name, r)
+	}
+	return nil
+}
+
+// openFifoReader opens a FIFO for reading without waiting for a writer.
+func openFifoReader(path string) (*os.File, error) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open FIFO %s: %v", path, err)
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+func copyStream(pipes *execdriver.Pipes, name string, r io.Reader) {
+	w := pipes.Stdout
+	if name == "stderr" {
+		w = pipes.Stderr
+	}
+	if w != nil {
+		go io.Copy(w, r)
+	}
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
+
+// Restorable implements the execdriver Restorer interface.
+func (d *Driver) Restorable(id string) bool {
+	d.Lock()
+	defer d.Unlock()
+	_, ok := d.liveContainers[id]
+	return ok
+}
+
+// Restore implements the execdriver Restorer interface.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	d.Lock()
+	live := d.liveContainers[c.ID]
+	delete(d.liveContainers, c.ID)
+	d.Unlock()
+	if live == nil {
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("container %s was not left running", c.ID)
+	}
+
+	cont, err := d.factory.Load(c.ID)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	defer func() {
+		cont.Destroy()
+		d.cleanContainer(c.ID)
+	}()
+
+	if live.exited == nil {
+		if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+			killCgroupProcs(cont)
+		}
+		return execdriver.ExitStatus{ExitCode: live.exitCode()}, nil
+	}
+
+	state, err := cont.State()
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	d.Lock()
+	d.activeContainers[c.ID] = cont
+	d.Unlock()
+
+	if err := d.attachLivePipes(c, pipes); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	oom := notifyOnOOM(cont)
+	if hooks.Start != nil {
+		hooks.Start(&c.ProcessConfig, state.InitProcessPid, oom)
+	}
+
+	status, ok := <-live.exited
+	live.status, live.statusKnown = status, ok
+	if nss := cont.Config().Namespaces; !nss.Contains(configs.NEWPID) {
+		killCgroupProcs(cont)
+	}
+	cont.Destroy()
+	_, oomKill := <-oom
+	return execdriver.ExitStatus{ExitCode: live.exitCode(), OOMKilled: oomKill}, nil
+}
+
+// exitCode returns the exit code of the container, or 137 like for the
+// containers the daemon kills when it starts if its status was lost.
+func (l *liveContainer) exitCode() int {
+	if !l.statusKnown {
+		return 137
+	}
+	return utils.ExitStatus(l.status)
+}
+
+// Detach implements the execdriver Restorer interface. It starts the helper
+// recording the exit status of the containers left running, and waits for
+// it to watch them.
+func (d *Driver) Detach() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cmd := reexec.Command(exitWatcherName, d.root)
+	// The helper outlives the daemon
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.ExtraFiles = []*os.File{w}
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return err
+	}
+	defer cmd.Process.Release()
+
+	// The helper writes to the pipe once it watches the containers. The pipe
+	// is closed without being written to if it exits before.
+	if n, err := r.Read(make([]byte, 1)); n == 0 {
+		return fmt.Errorf("the exit watcher did not start: %v", err)
+	}
+	return nil
+}
+
+// restoreLiveContainers loads the containers left running by the previous
+// daemon, watches their processes and stops the helper which recorded the
+// exit status of those which exited while the daemon was down.
+func (d *Driver) restoreLiveContainers() {
+	states := loadLiveStates(d.root)
+	if len(states) == 0 {
+		stopExitWatcher(d.root)
+		return
+	}
+
+	w, err := newExitWatcher()
+	if err != nil {
+		stopExitWatcher(d.root)
+		logrus.Errorf("Cannot restore the containers left running by the previous daemon: %v", err)
+		return
+	}
+
+	// The processes are watched before checking they are still running,
+	// and the helper is stopped once they are watched.
+	exited := make(map[string]<-chan syscall.WaitStatus)
+	for id, state := range states {
+		exited[id] = w.watch(state.InitProcessPid)
+	}
+	for id, state := range states {
+		live := &liveContainer{exited: exited[id]}
+		if !processRunning(state) {
+			w.unwatch(state.InitProcessPid)
+			live.exited = nil
+		}
+		d.liveContainers[id] = live
+	}
+	stopExitWatcher(d.root)
+
+	for id, live := range d.liveContainers {
+		if live.exited == nil {
+			live.status, live.statusKnown = readExitStatus(filepath.Join(d.root, id, exitStatusFile))
+		}
+	}
+}
+
+// loadLiveStates returns the states of the containers of the driver root
+// which have FIFOs, by container id.
+func loadLiveStates(root string) map[string]*libcontainer.State {
+	dirs, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	states := make(map[string]*libcontainer.State)
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, dir.Name(), liveStreams[0])); err != nil {
+			continue
+		}
+		state, err := loadState(filepath.Join(root, dir.Name()))
+		if err != nil {
+			logrus.Warnf("Failed to load the state of container %s: %v", dir.Name(), err)
+			continue
+		}
+		states[dir.Name()] = state
+	}
+	return states
+}
+
+func loadState(dir string) (*libcontainer.State, error) {
+	f, err := os.Open(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var state libcontainer.State
+	if err := json.NewDecoder(f).Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// processRunning returns whether the init process of the state is running,
+// and was not replaced by another process with the same pid.
+func processRunning(state *libcontainer.State) bool {
+	startTime, err := system.GetProcessStartTime(state.InitProcessPid)
+	return err == nil && startTime == state.InitProcessStartTime
+}
+
+func readExitStatus(path string) (syscall.WaitStatus, bool) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	status, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return syscall.WaitStatus(status), true
+}
+
+func readPidFile(path string) (int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// stopExitWatcher stops the helper of the previous daemon, if it is still
+// running, and waits for it to exit.
+func stopExitWatcher(root string) {
+	pidFile := filepath.Join(root, exitWatcherPidFile)
+	pid, err := readPidFile(pidFile)
+	if err != nil {
+		return
+	}
+	defer os.Remove(pidFile)
+
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil || !strings.HasPrefix(string(cmdline), exitWatcherName+"\x00") {
+		return
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return
+	}
+	for i := 0; i < 100; i++ {
+		if err := syscall.Kill(pid, 0); err == syscall.ESRCH {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	logrus.Warnf("The exit watcher of the previous daemon (pid %d) did not exit", pid)
+}
+
+// recordExits is the main function of the helper started when the daemon
+// exits leaving containers running. It writes the exit status of their
+// processes to their directory, until they all exited or the next daemon
+// stops it.
+func recordExits() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <root>\n", exitWatcherName)
+		os.Exit(1)
+	}
+	root := os.Args[1]
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGTERM, syscall.SIGINT)
+
+	w, err := newExitWatcher()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		pids = make(map[string]int)
+	)
+	states := loadLiveStates(root)
+	exited := make(map[string]<-chan syscall.WaitStatus)
+	for id, state := range states {
+		exited[id] = w.watch(state.InitProcessPid)
+	}
+	for id, state := range states {
+		if !processRunning(state) {
+			w.unwatch(state.InitProcessPid)
+			continue
+		}
+		pids[id] = state.InitProcessPid
+		wg.Add(1)
+		go func(id string, exited <-chan syscall.WaitStatus) {
+			defer wg.Done()
+			status, ok := <-exited
+			if !ok {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ioutil.WriteFile(filepath.Join(root, id, exitStatusFile), []byte(strconv.FormatUint(uint64(status), 10)), 0600)
+		}(id, exited[id])
+	}
+	if len(pids) == 0 {
+		w.stopAll()
+	}
+
+	pidFile := filepath.Join(root, exitWatcherPidFile)
+	if err := ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Tell the daemon waiting in Detach that the containers are watched
+	ready := os.NewFile(3, "ready")
+	ready.Write([]byte{0})
+	ready.Close()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		os.Remove(pidFile)
+	case <-sigc:
+		// Wait for the status being written, if any
+		mu.Lock()
+	}
+	os.Exit(0)
+}
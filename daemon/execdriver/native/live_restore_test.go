@@ -0,0 +1,89 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+func TestMain(m *testing.M) {
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestDetach(t *testing.T) {
+	w, err := newExitWatcher()
+	if err != nil {
+		t.Skipf("Cannot subscribe to the process events: %v", err)
+	}
+	w.stopAll()
+
+	root, err := ioutil.TempDir("", "docker-live-restore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	startTime, err := system.GetProcessStartTime(cmd.Process.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A container left running, whose process is the sleep
+	dir := filepath.Join(root, "live")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, liveStreams[0]), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var state libcontainer.State
+	state.InitProcessPid = cmd.Process.Pid
+	state.InitProcessStartTime = startTime
+	data, err := json.Marshal(&state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "state.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Driver{root: root}
+	if err := d.Detach(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The helper watches the container once Detach returns
+	pid, err := readPidFile(filepath.Join(root, exitWatcherPidFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status syscall.WaitStatus
+	if wpid, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil); err != nil || wpid != 0 {
+		t.Fatalf("Expected the exit watcher (pid %d) to be running: %v", pid, err)
+	}
+	syscall.Kill(pid, syscall.SIGTERM)
+	if _, err := syscall.Wait4(pid, &status, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,17 +4,19 @@ import (
 	"io"
 
 	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/archive"
 )
 
 // ContainerExport writes the contents of the container to the given
-// writer. An error is returned if the container cannot be found.
-func (daemon *Daemon) ContainerExport(name string, out io.Writer) error {
+// writer, as a tar archive compressed with the given compression. An
+// error is returned if the container cannot be found.
+func (daemon *Daemon) ContainerExport(name string, compression archive.Compression, out io.Writer) error {
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
 	}
 
-	data, err := container.export()
+	data, err := container.export(compression)
 	if err != nil {
 		return derr.ErrorCodeExportFailed.WithArgs(name, err)
 	}
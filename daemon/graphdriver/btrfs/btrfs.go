@@ -15,6 +15,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sync"
 	"syscall"
 	"unsafe"
 
@@ -68,6 +69,11 @@ type Driver struct {
 	home    string
 	uidMaps []idtools.IDMap
 	gidMaps []idtools.IDMap
+
+	// quotaEnabled is whether quota groups were enabled on the filesystem,
+	// which is only done once a layer is created with a size.
+	quotaEnabled bool
+	quotaLock    sync.Mutex
 }
 
 // String prints the name of the driver (btrfs).
@@ -224,6 +230,53 @@ func subvolDelete(dirpath, name string) error {
 	return nil
 }
 
+// subvolEnableQuota enables the quota groups of the filesystem of the driver.
+func (d *Driver) subvolEnableQuota() error {
+	d.quotaLock.Lock()
+	defer d.quotaLock.Unlock()
+	if d.quotaEnabled {
+		return nil
+	}
+
+	dir, err := openDir(d.home)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	var args C.struct_btrfs_ioctl_quota_ctl_args
+	args.cmd = C.BTRFS_QUOTA_CTL_ENABLE
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QUOTA_CTL,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to enable btrfs quota for %s: %v", d.home, errno.Error())
+	}
+
+	d.quotaEnabled = true
+	return nil
+}
+
+// subvolLimitQgroup limits the data referenced by the subvolume at path to
+// size bytes.
+func subvolLimitQgroup(path string, size uint64) error {
+	dir, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	defer closeDir(dir)
+
+	// The quota group 0 is the one of the subvolume itself
+	var args C.struct_btrfs_ioctl_qgroup_limit_args
+	args.lim.max_referenced = C.__u64(size)
+	args.lim.flags = C.BTRFS_QGROUP_LIMIT_MAX_RFER
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, getDirFd(dir), C.BTRFS_IOC_QGROUP_LIMIT,
+		uintptr(unsafe.Pointer(&args)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to limit qgroup for %s: %v", path, errno.Error())
+	}
+	return nil
+}
+
 func (d *Driver) subvolumesDir() string {
 	return path.Join(d.home, "subvolumes")
 }
@@ -232,8 +285,14 @@ func (d *Driver) subvolumesDirID(id string) string {
 	return path.Join(d.subvolumesDir(), id)
 }
 
-// Create the filesystem with given id.
-func (d *Driver) Create(id string, parent string) error {
+// Create the filesystem with given id. The "size" storage option limits the
+// data referenced by the subvolume with a quota group.
+func (d *Driver) Create(id string, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize(storageOpt)
+	if err != nil {
+		return err
+	}
+
 	subvolumes := path.Join(d.home, "subvolumes")
 	rootUID, rootGID, err := idtools.GetRootUIDGID(d.uidMaps, d.gidMaps)
 	if err != nil {
@@ -255,6 +314,17 @@ func (d *Driver) Create(id string, parent string) error {
 			return err
 		}
 	}
+
+	if size > 0 {
+		if err := d.subvolEnableQuota(); err != nil {
+			subvolDelete(subvolumes, id)
+			return err
+		}
+		if err := subvolLimitQgroup(path.Join(subvolumes, id), size); err != nil {
+			subvolDelete(subvolumes, id)
+			return err
+		}
+	}
 	return nil
 }
 
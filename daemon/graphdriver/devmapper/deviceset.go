@@ -752,7 +752,7 @@ func (devices *DeviceSet) createRegisterDevice(hash string) (*devInfo, error) {
 	return info, nil
 }
 
-func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInfo) error {
+func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInfo, size uint64) error {
 	deviceID, err := devices.getNextFreeDeviceID()
 	if err != nil {
 		return err
@@ -787,7 +787,7 @@ func (devices *DeviceSet) createRegisterSnapDevice(hash string, baseInfo *devInf
 		break
 	}
 
-	if _, err := devices.registerDevice(deviceID, hash, baseInfo.Size, devices.OpenTransactionID); err != nil {
+	if _, err := devices.registerDevice(deviceID, hash, size, devices.OpenTransactionID); err != nil {
 		devicemapper.DeleteDevice(devices.getPoolDevName(), deviceID)
 		devices.markDeviceIDFree(deviceID)
 		logrus.Debugf("Error registering device: %s", err)
@@ -1635,8 +1635,10 @@ func (devices *DeviceSet) initDevmapper(doInit bool) error {
 	return nil
 }
 
-// AddDevice adds a device and registers in the hash.
-func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
+// AddDevice adds a device and registers in the hash. The device has the
+// size of its base device, or size bytes if size is greater than 0, in which
+// case its filesystem is grown to the new size.
+func (devices *DeviceSet) AddDevice(hash, baseHash string, size uint64) error {
 	logrus.Debugf("[deviceset] AddDevice(hash=%s basehash=%s)", hash, baseHash)
 	defer logrus.Debugf("[deviceset] AddDevice(hash=%s basehash=%s) END", hash, baseHash)
 
@@ -1650,6 +1652,13 @@ func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
 		return fmt.Errorf("devmapper: Base device %v has been marked for deferred deletion", baseInfo.Hash)
 	}
 
+	if size == 0 {
+		size = baseInfo.Size
+	}
+	if size < baseInfo.Size {
+		return fmt.Errorf("devmapper: Container size cannot be smaller than %s", units.HumanSize(float64(baseInfo.Size)))
+	}
+
 	baseInfo.lock.Lock()
 	defer baseInfo.lock.Unlock()
 
@@ -1662,10 +1671,70 @@ func (devices *DeviceSet) AddDevice(hash, baseHash string) error {
 		return fmt.Errorf("device %s already exists. Deleted=%v", hash, info.Deleted)
 	}
 
-	if err := devices.createRegisterSnapDevice(hash, baseInfo); err != nil {
+	if err := devices.createRegisterSnapDevice(hash, baseInfo, size); err != nil {
 		return err
 	}
 
+	if size > baseInfo.Size {
+		info, err := devices.lookupDevice(hash)
+		if err != nil {
+			return err
+		}
+		if err := devices.growFS(info); err != nil {
+			if err := devices.deleteDevice(info, true); err != nil {
+				logrus.Errorf("devmapper: Error removing device %s: %v", hash, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// growFS grows the filesystem of a device to the size of the device. Should
+// be called with devices.Lock() held.
+func (devices *DeviceSet) growFS(info *devInfo) error {
+	if err := devices.activateDeviceIfNeeded(info, false); err != nil {
+		return fmt.Errorf("Error activating devmapper device for '%s': %s", info.Hash, err)
+	}
+	defer devices.deactivateDevice(info)
+
+	fstype, err := ProbeFsType(info.DevName())
+	if err != nil {
+		return err
+	}
+
+	// The filesystems are grown online
+	mountPoint, err := ioutil.TempDir(devices.root, "growfs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(mountPoint)
+
+	options := ""
+	if fstype == "xfs" {
+		// XFS needs nouuid or it can't mount filesystems with the same fs
+		options = joinMountOptions(options, "nouuid")
+	}
+	options = joinMountOptions(options, devices.mountOptions)
+
+	if err := mount.Mount(info.DevName(), mountPoint, fstype, options); err != nil {
+		return fmt.Errorf("Error mounting '%s' on '%s': %s", info.DevName(), mountPoint, err)
+	}
+	defer syscall.Unmount(mountPoint, syscall.MNT_DETACH)
+
+	var out []byte
+	switch fstype {
+	case "ext4":
+		out, err = exec.Command("resize2fs", info.DevName()).CombinedOutput()
+	case "xfs":
+		out, err = exec.Command("xfs_growfs", mountPoint).CombinedOutput()
+	default:
+		return fmt.Errorf("devmapper: Growing the %s filesystem of a device is not supported", fstype)
+	}
+	if err != nil {
+		return fmt.Errorf("devmapper: Failed to grow the filesystem of '%s': %v: %s", info.DevName(), err, out)
+	}
 	return nil
 }
 
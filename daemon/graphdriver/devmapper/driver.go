@@ -132,9 +132,16 @@ func (d *Driver) Cleanup() error {
 	return err
 }
 
-// Create adds a device with a given id and the parent.
-func (d *Driver) Create(id, parent string) error {
-	if err := d.DeviceSet.AddDevice(id, parent); err != nil {
+// Create adds a device with a given id and the parent. The "size" storage
+// option sets the size of the device, which cannot be smaller than the size
+// of the parent device.
+func (d *Driver) Create(id, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize(storageOpt)
+	if err != nil {
+		return err
+	}
+
+	if err := d.DeviceSet.AddDevice(id, parent, size); err != nil {
 		return err
 	}
 
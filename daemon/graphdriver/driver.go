@@ -48,8 +48,10 @@ type ProtoDriver interface {
 	// String returns a string representation of this driver.
 	String() string
 	// Create creates a new, empty, filesystem layer with the
-	// specified id and parent. Parent may be "".
-	Create(id, parent string) error
+	// specified id and parent. Parent may be "". storageOpt holds the
+	// options of the layer, such as its "size", and may be nil.
+	// Drivers return an error for the options they do not support.
+	Create(id, parent string, storageOpt map[string]string) error
 	// Remove attempts to remove the filesystem layer with this id.
 	Remove(id string) error
 	// Get returns the mountpoint for the layered filesystem referred
@@ -113,10 +115,11 @@ func GetDriver(name, home string, options []string, uidMaps, gidMaps []idtools.I
 	if initFunc, exists := drivers[name]; exists {
 		return initFunc(filepath.Join(home, name), options, uidMaps, gidMaps)
 	}
-	if pluginDriver, err := lookupPlugin(name, home, options); err == nil {
+	pluginDriver, err := lookupPlugin(name, home, options, uidMaps, gidMaps)
+	if err == nil {
 		return pluginDriver, nil
 	}
-	logrus.Errorf("Failed to GetDriver graph %s %s", name, home)
+	logrus.Errorf("Failed to GetDriver graph %s %s: %v", name, home, err)
 	return nil, ErrNotSupported
 }
 
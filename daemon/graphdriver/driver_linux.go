@@ -49,6 +49,7 @@ var (
 		"btrfs",
 		"zfs",
 		"devicemapper",
+		"overlay2",
 		"overlay",
 		"vfs",
 	}
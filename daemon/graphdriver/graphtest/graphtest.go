@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 )
 
 var (
@@ -64,7 +66,7 @@ func getBaseLoopStats() (*syscall.Stat_t, error) {
 	return loop0.Sys().(*syscall.Stat_t), nil
 }
 
-func newDriver(t *testing.T, name string) *Driver {
+func newDriver(t *testing.T, name string, uidMaps, gidMaps []idtools.IDMap) *Driver {
 	root, err := ioutil.TempDir("/var/tmp", "docker-graphtest-")
 	if err != nil {
 		t.Fatal(err)
@@ -74,7 +76,7 @@ func newDriver(t *testing.T, name string) *Driver {
 		t.Fatal(err)
 	}
 
-	d, err := graphdriver.GetDriver(name, root, nil, nil, nil)
+	d, err := graphdriver.GetDriver(name, root, nil, uidMaps, gidMaps)
 	if err != nil {
 		t.Logf("graphdriver: %v\n", err)
 		if err == graphdriver.ErrNotSupported || err == graphdriver.ErrPrerequisites || err == graphdriver.ErrIncompatibleFS {
@@ -95,7 +97,7 @@ func cleanup(t *testing.T, d *Driver) {
 // GetDriver create a new driver with given name or return a existing driver with the name updating the reference count.
 func GetDriver(t *testing.T, name string) graphdriver.Driver {
 	if drv == nil {
-		drv = newDriver(t, name)
+		drv = newDriver(t, name, nil, nil)
 	} else {
 		drv.refCount++
 	}
@@ -175,7 +177,7 @@ func DriverTestCreateEmpty(t *testing.T, drivername string) {
 	driver := GetDriver(t, drivername)
 	defer PutDriver(t)
 
-	if err := driver.Create("empty", ""); err != nil {
+	if err := driver.Create("empty", "", nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -213,7 +215,7 @@ func createBase(t *testing.T, driver graphdriver.Driver, name string) {
 	oldmask := syscall.Umask(0)
 	defer syscall.Umask(oldmask)
 
-	if err := driver.Create(name, ""); err != nil {
+	if err := driver.Create(name, "", nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -281,7 +283,7 @@ func DriverTestCreateSnap(t *testing.T, drivername string) {
 
 	createBase(t, driver, "Base")
 
-	if err := driver.Create("Snap", "Base"); err != nil {
+	if err := driver.Create("Snap", "Base", nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -295,3 +297,226 @@ func DriverTestCreateSnap(t *testing.T, drivername string) {
 		t.Fatal(err)
 	}
 }
+
+func createSnapChanges(t *testing.T, driver graphdriver.Driver, name string) {
+	dir, err := driver.Get(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put(name)
+
+	// Remove a file of the base, replace one of its directories and add a file
+	if err := os.Remove(path.Join(dir, "a file")); err != nil {
+		t.Fatal(err)
+	}
+	subdir := path.Join(dir, "a subdir")
+	if err := os.RemoveAll(subdir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(subdir, "a new file"), []byte("Some other data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func verifySnapChanges(t *testing.T, driver graphdriver.Driver, name string) {
+	dir, err := driver.Get(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put(name)
+
+	if _, err := os.Lstat(path.Join(dir, "a file")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the removed file to be missing, got %v", err)
+	}
+
+	subdir := path.Join(dir, "a subdir")
+	verifyFile(t, subdir, 0755|os.ModeDir, 0, 0)
+	fis, err := readDir(subdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 1 || fis[0].Name() != "a new file" {
+		t.Fatal("Unexpected files in the replaced directory")
+	}
+	content, err := ioutil.ReadFile(path.Join(subdir, "a new file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "Some other data" {
+		t.Fatalf("Unexpected content of the new file: %q", content)
+	}
+
+	fis, err = readDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 1 {
+		t.Fatal("Unexpected files in snap image")
+	}
+}
+
+// DriverTestDiffApply creates a snap with changes from a base, and verifies
+// that applying the diff of the snap to another layer on the base reproduces
+// the changes.
+func DriverTestDiffApply(t *testing.T, drivername string) {
+	driver := GetDriver(t, drivername)
+	defer PutDriver(t)
+
+	createBase(t, driver, "Base")
+	defer driver.Remove("Base")
+
+	// The replaced directory of the base must not be merged in the snap
+	dir, err := driver.Get("Base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(dir, "a subdir", "an old file"), []byte("Some data"), 0644)
+	driver.Put("Base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := driver.Create("Snap", "Base", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Remove("Snap")
+	createSnapChanges(t, driver, "Snap")
+	verifySnapChanges(t, driver, "Snap")
+
+	diffSize, err := driver.DiffSize("Snap", "Base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := driver.Diff("Snap", "Base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diff.Close()
+
+	if err := driver.Create("Apply", "Base", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Remove("Apply")
+
+	applySize, err := driver.ApplyDiff("Apply", "Base", diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applySize != diffSize {
+		t.Fatalf("Expected the applied diff to have the size %d, got %d", diffSize, applySize)
+	}
+	verifySnapChanges(t, driver, "Apply")
+
+	changes, err := driver.Changes("Apply", "Base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]archive.ChangeType)
+	for _, c := range changes {
+		kinds[c.Path] = c.Kind
+	}
+	if kind, ok := kinds["/a file"]; !ok || kind != archive.ChangeDelete {
+		t.Fatalf("Expected the deletion of the removed file in the changes %v", changes)
+	}
+	if kind, ok := kinds["/a subdir/a new file"]; !ok || kind != archive.ChangeAdd {
+		t.Fatalf("Expected the addition of the new file in the changes %v", changes)
+	}
+}
+
+// DriverTestDeepLayers creates a chain of count layers, each adding a file,
+// and verifies that the top layer has the files of all the layers.
+func DriverTestDeepLayers(t *testing.T, drivername string, count int) {
+	driver := GetDriver(t, drivername)
+	defer PutDriver(t)
+
+	var parent string
+	for i := 0; i < count; i++ {
+		layer := fmt.Sprintf("Layer%d", i)
+		if err := driver.Create(layer, parent, nil); err != nil {
+			t.Fatal(err)
+		}
+		defer driver.Remove(layer)
+
+		dir, err := driver.Get(layer, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(dir, layer), []byte(layer), 0644); err != nil {
+			driver.Put(layer)
+			t.Fatal(err)
+		}
+		driver.Put(layer)
+		parent = layer
+	}
+
+	dir, err := driver.Get(parent, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put(parent)
+
+	for i := 0; i < count; i++ {
+		layer := fmt.Sprintf("Layer%d", i)
+		content, err := ioutil.ReadFile(path.Join(dir, layer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != layer {
+			t.Fatalf("Unexpected content of the file of %s: %q", layer, content)
+		}
+	}
+}
+
+// DriverTestRemappedOwnership creates a driver with the given UID and GID
+// maps, and verifies that its layers and the files of the diffs applied to
+// them are owned by the remapped root.
+func DriverTestRemappedOwnership(t *testing.T, drivername string, uidMaps, gidMaps []idtools.IDMap) {
+	rootUID, rootGID, err := idtools.GetRootUIDGID(uidMaps, gidMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driver := newDriver(t, drivername, uidMaps, gidMaps)
+	defer func() {
+		if err := driver.Cleanup(); err != nil {
+			t.Fatal(err)
+		}
+		os.RemoveAll(driver.root)
+	}()
+
+	if err := driver.Create("Base", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Remove("Base")
+
+	dir, err := driver.Get("Base", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyFile(t, dir, 0755|os.ModeDir, uint32(rootUID), uint32(rootGID))
+	driver.Put("Base")
+
+	if err := driver.Create("Apply", "Base", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Remove("Apply")
+
+	diff, err := archive.Generate("a file", "Some data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := driver.ApplyDiff("Apply", "Base", diff); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err = driver.Get("Apply", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Put("Apply")
+	verifyFile(t, path.Join(dir, "a file"), 0, uint32(rootUID), uint32(rootGID))
+}
@@ -0,0 +1,255 @@
+// +build experimental
+// +build daemon
+
+package graphtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/tlsconfig"
+)
+
+// testPluginProtocolVersion is the version of the graph driver plugin
+// protocol implemented by TestPlugin.
+const testPluginProtocolVersion = 1
+
+var errPluginNotInitialized = errors.New("the plugin is not initialized")
+
+type pluginInitRequest struct {
+	Home    string
+	Opts    []string
+	UIDMaps []idtools.IDMap
+	GIDMaps []idtools.IDMap
+	Version int
+}
+
+type pluginInitResponse struct {
+	Err          string `json:",omitempty"`
+	Version      int
+	Capabilities graphdriver.PluginCapabilities
+}
+
+type pluginRequest struct {
+	ID         string
+	Parent     string
+	MountLabel string
+	StorageOpt map[string]string
+}
+
+type pluginResponse struct {
+	Err      string            `json:",omitempty"`
+	Dir      string            `json:",omitempty"`
+	Exists   bool              `json:",omitempty"`
+	Status   [][2]string       `json:",omitempty"`
+	Changes  []archive.Change  `json:",omitempty"`
+	Size     int64             `json:",omitempty"`
+	Metadata map[string]string `json:",omitempty"`
+}
+
+// TestPlugin is a reference graph driver plugin running in the test process.
+// It serves a built-in driver over the graph driver plugin protocol, so that
+// the tests of graphtest run end-to-end through the plugin proxy of the
+// daemon.
+type TestPlugin struct {
+	backing      string
+	capabilities graphdriver.PluginCapabilities
+	server       *httptest.Server
+
+	mu     sync.Mutex
+	driver graphdriver.Driver
+}
+
+// RegisterTestPlugin starts a TestPlugin serving the built-in driver named
+// backing with the given capabilities, and registers it as the driver name.
+// The requests to the plugin which are not part of its capabilities are not
+// served.
+func RegisterTestPlugin(name, backing string, capabilities graphdriver.PluginCapabilities) (*TestPlugin, error) {
+	p := &TestPlugin{
+		backing:      backing,
+		capabilities: capabilities,
+	}
+	p.server = httptest.NewServer(p.handler())
+
+	client, err := plugins.NewClient("tcp://"+p.server.Listener.Addr().String(), tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		p.server.Close()
+		return nil, err
+	}
+	initFunc := func(root string, options []string, uidMaps, gidMaps []idtools.IDMap) (graphdriver.Driver, error) {
+		return graphdriver.NewPluginDriver(name, root, options, uidMaps, gidMaps, client)
+	}
+	if err := graphdriver.Register(name, initFunc); err != nil {
+		p.server.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close stops serving the plugin.
+func (p *TestPlugin) Close() {
+	p.server.Close()
+}
+
+func (p *TestPlugin) getDriver() (graphdriver.Driver, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.driver == nil {
+		return nil, errPluginNotInitialized
+	}
+	return p.driver, nil
+}
+
+func (p *TestPlugin) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, &plugins.Manifest{Implements: []string{"GraphDriver"}})
+	})
+
+	mux.HandleFunc("/GraphDriver.Init", func(w http.ResponseWriter, r *http.Request) {
+		var req pluginInitRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		driver, err := graphdriver.GetDriver(p.backing, req.Home, req.Opts, req.UIDMaps, req.GIDMaps)
+		if err != nil {
+			respond(w, &pluginInitResponse{Err: err.Error()})
+			return
+		}
+		p.mu.Lock()
+		p.driver = driver
+		p.mu.Unlock()
+		respond(w, &pluginInitResponse{
+			Version:      testPluginProtocolVersion,
+			Capabilities: p.capabilities,
+		})
+	})
+
+	// handle serves the requests of the protocol taking a pluginRequest.
+	handle := func(method string, fn func(graphdriver.Driver, *pluginRequest) (*pluginResponse, error)) {
+		mux.HandleFunc("/GraphDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			var req pluginRequest
+			if !decodeRequest(w, r, &req) {
+				return
+			}
+			driver, err := p.getDriver()
+			if err != nil {
+				respond(w, &pluginResponse{Err: err.Error()})
+				return
+			}
+			resp, err := fn(driver, &req)
+			if err != nil {
+				respond(w, &pluginResponse{Err: err.Error()})
+				return
+			}
+			respond(w, resp)
+		})
+	}
+
+	handle("Create", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{}, d.Create(req.ID, req.Parent, req.StorageOpt)
+	})
+	handle("Remove", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{}, d.Remove(req.ID)
+	})
+	handle("Get", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		dir, err := d.Get(req.ID, req.MountLabel)
+		return &pluginResponse{Dir: dir}, err
+	})
+	handle("Put", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{}, d.Put(req.ID)
+	})
+	handle("Exists", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{Exists: d.Exists(req.ID)}, nil
+	})
+	handle("Status", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{Status: d.Status()}, nil
+	})
+	handle("GetMetadata", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		metadata, err := d.GetMetadata(req.ID)
+		return &pluginResponse{Metadata: metadata}, err
+	})
+	handle("Cleanup", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		return &pluginResponse{}, d.Cleanup()
+	})
+
+	if !p.capabilities.Diff {
+		return mux
+	}
+
+	mux.HandleFunc("/GraphDriver.Diff", func(w http.ResponseWriter, r *http.Request) {
+		var req pluginRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		driver, err := p.getDriver()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		diff, err := driver.Diff(req.ID, req.Parent)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer diff.Close()
+		if _, err := io.Copy(w, diff); err != nil {
+			// Abort the response, so that the daemon doesn't mistake
+			// the truncated stream for a complete diff
+			panic(http.ErrAbortHandler)
+		}
+	})
+	handle("Changes", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		changes, err := d.Changes(req.ID, req.Parent)
+		return &pluginResponse{Changes: changes}, err
+	})
+	mux.HandleFunc("/GraphDriver.ApplyDiff", func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		driver, err := p.getDriver()
+		if err != nil {
+			respond(w, &pluginResponse{Err: err.Error()})
+			return
+		}
+		size, err := driver.ApplyDiff(id, r.URL.Query().Get("parent"), r.Body)
+		if err != nil {
+			respond(w, &pluginResponse{Err: err.Error()})
+			return
+		}
+		respond(w, &pluginResponse{Size: size})
+	})
+	handle("DiffSize", func(d graphdriver.Driver, req *pluginRequest) (*pluginResponse, error) {
+		size, err := d.DiffSize(req.ID, req.Parent)
+		return &pluginResponse{Size: size}, err
+	})
+
+	return mux
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("error decoding json: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func respond(w http.ResponseWriter, resp interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1.1+json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,86 @@
+package graphdriver
+
+import (
+	"time"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "engine",
+	Subsystem: "graphdriver",
+	Name:      "operation_seconds",
+	Help:      "The number of seconds taken by the operations of the storage driver",
+}, []string{"driver", "operation"})
+
+func init() {
+	prometheus.MustRegister(operationDuration)
+}
+
+// meteredDriver records the latencies of the operations of a driver on its
+// layers.
+type meteredDriver struct {
+	Driver
+}
+
+// NewMeteredDriver returns a driver recording the latencies of the
+// operations of the given driver.
+func NewMeteredDriver(driver Driver) Driver {
+	return &meteredDriver{Driver: driver}
+}
+
+// Unwrap returns the driver whose operations are recorded by the given
+// driver, or the given driver itself if it is not a metered driver. The
+// concrete type and the optional interfaces of a driver must be checked on
+// the driver it returns.
+func Unwrap(driver Driver) Driver {
+	if d, ok := driver.(*meteredDriver); ok {
+		return d.Driver
+	}
+	return driver
+}
+
+func (d *meteredDriver) observe(operation string, start time.Time) {
+	operationDuration.WithLabelValues(d.String(), operation).Observe(time.Since(start).Seconds())
+}
+
+func (d *meteredDriver) Create(id, parent string, storageOpt map[string]string) error {
+	defer d.observe("create", time.Now())
+	return d.Driver.Create(id, parent, storageOpt)
+}
+
+func (d *meteredDriver) Remove(id string) error {
+	defer d.observe("remove", time.Now())
+	return d.Driver.Remove(id)
+}
+
+func (d *meteredDriver) Get(id, mountLabel string) (string, error) {
+	defer d.observe("get", time.Now())
+	return d.Driver.Get(id, mountLabel)
+}
+
+func (d *meteredDriver) Put(id string) error {
+	defer d.observe("put", time.Now())
+	return d.Driver.Put(id)
+}
+
+func (d *meteredDriver) Diff(id, parent string) (archive.Archive, error) {
+	defer d.observe("diff", time.Now())
+	return d.Driver.Diff(id, parent)
+}
+
+func (d *meteredDriver) Changes(id, parent string) ([]archive.Change, error) {
+	defer d.observe("changes", time.Now())
+	return d.Driver.Changes(id, parent)
+}
+
+func (d *meteredDriver) ApplyDiff(id, parent string, diff archive.Reader) (int64, error) {
+	defer d.observe("applydiff", time.Now())
+	return d.Driver.ApplyDiff(id, parent, diff)
+}
+
+func (d *meteredDriver) DiffSize(id, parent string) (int64, error) {
+	defer d.observe("diffsize", time.Now())
+	return d.Driver.DiffSize(id, parent)
+}
@@ -0,0 +1,19 @@
+package graphdriver
+
+import (
+	"testing"
+)
+
+type unwrapTestDriver struct {
+	Driver
+}
+
+func TestUnwrap(t *testing.T) {
+	driver := &unwrapTestDriver{}
+	if d := Unwrap(NewMeteredDriver(driver)); d != driver {
+		t.Fatalf("expected the metered driver to unwrap to %v, got %v", driver, d)
+	}
+	if d := Unwrap(driver); d != driver {
+		t.Fatalf("expected %v to unwrap to itself, got %v", driver, d)
+	}
+}
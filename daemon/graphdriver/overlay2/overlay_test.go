@@ -0,0 +1,46 @@
+// +build linux
+
+package overlay2
+
+import (
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver/graphtest"
+	"github.com/docker/docker/pkg/reexec"
+)
+
+func init() {
+	reexec.Init()
+}
+
+// This avoids creating a new driver for each test if all tests are run
+// Make sure to put new tests between TestOverlaySetup and TestOverlayTeardown
+func TestOverlaySetup(t *testing.T) {
+	graphtest.GetDriver(t, "overlay2")
+}
+
+func TestOverlayCreateEmpty(t *testing.T) {
+	graphtest.DriverTestCreateEmpty(t, "overlay2")
+}
+
+func TestOverlayCreateBase(t *testing.T) {
+	graphtest.DriverTestCreateBase(t, "overlay2")
+}
+
+func TestOverlayCreateSnap(t *testing.T) {
+	graphtest.DriverTestCreateSnap(t, "overlay2")
+}
+
+func TestOverlayDiffApply(t *testing.T) {
+	graphtest.DriverTestDiffApply(t, "overlay2")
+}
+
+// The mount data of the top layer exceeds the page size with the absolute
+// paths of its lower directories.
+func TestOverlayDeepLayers(t *testing.T) {
+	graphtest.DriverTestDeepLayers(t, "overlay2", maxDepth+1)
+}
+
+func TestOverlayTeardown(t *testing.T) {
+	graphtest.PutDriver(t)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/plugins"
 )
 
@@ -19,15 +20,29 @@ type pluginClient interface {
 	SendFile(string, io.Reader, interface{}) error
 }
 
-func lookupPlugin(name, home string, opts []string) (Driver, error) {
+func lookupPlugin(name, home string, opts []string, uidMaps, gidMaps []idtools.IDMap) (Driver, error) {
 	pl, err := plugins.Get(name, "GraphDriver")
 	if err != nil {
 		return nil, fmt.Errorf("Error looking up graphdriver plugin %s: %v", name, err)
 	}
-	return newPluginDriver(name, home, opts, pl.Client)
+	return newPluginDriver(name, home, opts, uidMaps, gidMaps, pl.Client)
 }
 
-func newPluginDriver(name, home string, opts []string, c pluginClient) (Driver, error) {
-	proxy := &graphDriverProxy{name, c}
-	return proxy, proxy.Init(home, opts)
+// NewPluginDriver returns the driver of the graph driver plugin name,
+// reached with the client c, once initialized at home. It is used to run
+// plugins which are not discovered in the plugin directories, such as the
+// test plugins of graphtest.
+func NewPluginDriver(name, home string, opts []string, uidMaps, gidMaps []idtools.IDMap, c *plugins.Client) (Driver, error) {
+	return newPluginDriver(name, home, opts, uidMaps, gidMaps, c)
+}
+
+func newPluginDriver(name, home string, opts []string, uidMaps, gidMaps []idtools.IDMap, c pluginClient) (Driver, error) {
+	proxy := &graphDriverProxy{name: name, client: c}
+	if err := proxy.Init(home, opts, uidMaps, gidMaps); err != nil {
+		return nil, err
+	}
+	if !proxy.capabilities.Diff {
+		return NewNaiveDiffDriver(proxy, uidMaps, gidMaps), nil
+	}
+	return proxy, nil
 }
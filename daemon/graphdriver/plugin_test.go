@@ -0,0 +1,81 @@
+// +build experimental
+// +build daemon
+// +build linux
+
+package graphdriver_test
+
+import (
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/graphtest"
+	_ "github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/pkg/reexec"
+)
+
+func init() {
+	reexec.Init()
+
+	// A plugin computing the diffs of its layers, and a plugin leaving it
+	// to the daemon
+	capabilities := map[string]graphdriver.PluginCapabilities{
+		"test-plugin":       {UIDGIDMaps: true, Diff: true},
+		"test-plugin-naive": {UIDGIDMaps: true},
+	}
+	for name, c := range capabilities {
+		if _, err := graphtest.RegisterTestPlugin(name, "vfs", c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// This avoids creating a new driver for each test if all tests are run
+// Make sure to put new tests between TestPluginSetup and TestPluginTeardown
+func TestPluginSetup(t *testing.T) {
+	graphtest.GetDriver(t, "test-plugin")
+}
+
+func TestPluginCreateEmpty(t *testing.T) {
+	graphtest.DriverTestCreateEmpty(t, "test-plugin")
+}
+
+func TestPluginCreateBase(t *testing.T) {
+	graphtest.DriverTestCreateBase(t, "test-plugin")
+}
+
+func TestPluginCreateSnap(t *testing.T) {
+	graphtest.DriverTestCreateSnap(t, "test-plugin")
+}
+
+func TestPluginDiffApply(t *testing.T) {
+	graphtest.DriverTestDiffApply(t, "test-plugin")
+}
+
+func TestPluginTeardown(t *testing.T) {
+	graphtest.PutDriver(t)
+}
+
+func TestNaivePluginSetup(t *testing.T) {
+	graphtest.GetDriver(t, "test-plugin-naive")
+}
+
+func TestNaivePluginCreateSnap(t *testing.T) {
+	graphtest.DriverTestCreateSnap(t, "test-plugin-naive")
+}
+
+func TestNaivePluginDiffApply(t *testing.T) {
+	graphtest.DriverTestDiffApply(t, "test-plugin-naive")
+}
+
+func TestNaivePluginTeardown(t *testing.T) {
+	graphtest.PutDriver(t)
+}
+
+func TestPluginRemappedOwnership(t *testing.T) {
+	for _, name := range []string{"test-plugin", "test-plugin-naive"} {
+		uidMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+		gidMaps := []idtools.IDMap{{ContainerID: 0, HostID: 200000, Size: 65536}}
+		graphtest.DriverTestRemappedOwnership(t, name, uidMaps, gidMaps)
+	}
+}
@@ -2,6 +2,8 @@
 
 package graphdriver
 
-func lookupPlugin(name, home string, opts []string) (Driver, error) {
+import "github.com/docker/docker/pkg/idtools"
+
+func lookupPlugin(name, home string, opts []string, uidMaps, gidMaps []idtools.IDMap) (Driver, error) {
 	return nil, ErrNotSupported
 }
@@ -6,19 +6,48 @@ package graphdriver
 import (
 	"errors"
 	"fmt"
+	"net/url"
 
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 )
 
+// pluginProtocolVersion is the latest version of the graph driver plugin
+// protocol supported by the daemon. The daemon sends it in the Init request,
+// and uses the lowest of its version and the version of the plugin in the
+// Init response. Plugins which don't send their version use the version 0.
+const pluginProtocolVersion = 1
+
+// PluginCapabilities are the capabilities of a graph driver plugin, sent in
+// the response to the Init request from the protocol version 1.
+type PluginCapabilities struct {
+	// UIDGIDMaps is true if the plugin creates the layers owned by the
+	// root of the user namespace given with the UID and GID maps of the
+	// Init request. The daemon refuses the plugins which don't when it
+	// is started with user namespaces.
+	UIDGIDMaps bool
+	// Diff is true if the plugin implements the Diff, Changes, ApplyDiff
+	// and DiffSize requests. Otherwise, the daemon computes the diffs of
+	// the layers from their directories returned by the Get requests.
+	Diff bool
+}
+
+// legacyPluginCapabilities are the capabilities of the plugins using the
+// protocol version 0, which implement all the requests.
+var legacyPluginCapabilities = PluginCapabilities{Diff: true}
+
 type graphDriverProxy struct {
-	name   string
-	client pluginClient
+	name         string
+	client       pluginClient
+	version      int
+	capabilities PluginCapabilities
 }
 
 type graphDriverRequest struct {
-	ID         string `json:",omitempty"`
-	Parent     string `json:",omitempty"`
-	MountLabel string `json:",omitempty"`
+	ID         string            `json:",omitempty"`
+	Parent     string            `json:",omitempty"`
+	MountLabel string            `json:",omitempty"`
+	StorageOpt map[string]string `json:",omitempty"`
 }
 
 type graphDriverResponse struct {
@@ -32,22 +61,49 @@ type graphDriverResponse struct {
 }
 
 type graphDriverInitRequest struct {
-	Home string
-	Opts []string
+	Home    string
+	Opts    []string
+	UIDMaps []idtools.IDMap `json:",omitempty"`
+	GIDMaps []idtools.IDMap `json:",omitempty"`
+	Version int
 }
 
-func (d *graphDriverProxy) Init(home string, opts []string) error {
+type graphDriverInitResponse struct {
+	Err          string `json:",omitempty"`
+	Version      int    `json:",omitempty"`
+	Capabilities PluginCapabilities
+}
+
+// Init initializes the plugin, and negotiates the protocol version and the
+// capabilities of the plugin.
+func (d *graphDriverProxy) Init(home string, opts []string, uidMaps, gidMaps []idtools.IDMap) error {
 	args := &graphDriverInitRequest{
-		Home: home,
-		Opts: opts,
+		Home:    home,
+		Opts:    opts,
+		UIDMaps: uidMaps,
+		GIDMaps: gidMaps,
+		Version: pluginProtocolVersion,
 	}
-	var ret graphDriverResponse
+	var ret graphDriverInitResponse
 	if err := d.client.Call("GraphDriver.Init", args, &ret); err != nil {
 		return err
 	}
 	if ret.Err != "" {
 		return errors.New(ret.Err)
 	}
+
+	d.version = ret.Version
+	if d.version > pluginProtocolVersion {
+		d.version = pluginProtocolVersion
+	}
+	d.capabilities = ret.Capabilities
+	if d.version == 0 {
+		d.capabilities = legacyPluginCapabilities
+	}
+
+	if (len(uidMaps) > 0 || len(gidMaps) > 0) && !d.capabilities.UIDGIDMaps {
+		return fmt.Errorf("graphdriver plugin %s does not support user namespaces", d.name)
+	}
 	return nil
 }
 
@@ -55,10 +111,11 @@ func (d *graphDriverProxy) String() string {
 	return d.name
 }
 
-func (d *graphDriverProxy) Create(id, parent string) error {
+func (d *graphDriverProxy) Create(id, parent string, storageOpt map[string]string) error {
 	args := &graphDriverRequest{
-		ID:     id,
-		Parent: parent,
+		ID:         id,
+		Parent:     parent,
+		StorageOpt: storageOpt,
 	}
 	var ret graphDriverResponse
 	if err := d.client.Call("GraphDriver.Create", args, &ret); err != nil {
@@ -161,7 +218,6 @@ func (d *graphDriverProxy) Diff(id, parent string) (archive.Archive, error) {
 	}
 	body, err := d.client.Stream("GraphDriver.Diff", args)
 	if err != nil {
-		body.Close()
 		return nil, err
 	}
 	return archive.Archive(body), nil
@@ -184,8 +240,11 @@ func (d *graphDriverProxy) Changes(id, parent string) ([]archive.Change, error)
 }
 
 func (d *graphDriverProxy) ApplyDiff(id, parent string, diff archive.Reader) (int64, error) {
+	query := url.Values{}
+	query.Set("id", id)
+	query.Set("parent", parent)
 	var ret graphDriverResponse
-	if err := d.client.SendFile(fmt.Sprintf("GraphDriver.ApplyDiff?id=%s&parent=%s", id, parent), diff, &ret); err != nil {
+	if err := d.client.SendFile("GraphDriver.ApplyDiff?"+query.Encode(), diff, &ret); err != nil {
 		return -1, err
 	}
 	if ret.Err != "" {
@@ -0,0 +1,103 @@
+// +build experimental
+// +build daemon
+
+package graphdriver
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/idtools"
+)
+
+// initClient answers the Init requests with a given response, and records
+// the last Init request.
+type initClient struct {
+	resp string
+	req  graphDriverInitRequest
+}
+
+func (c *initClient) Call(method string, args interface{}, ret interface{}) error {
+	c.req = *args.(*graphDriverInitRequest)
+	return json.Unmarshal([]byte(c.resp), ret)
+}
+
+func (c *initClient) Stream(string, interface{}) (io.ReadCloser, error) {
+	panic("unexpected call to Stream")
+}
+
+func (c *initClient) SendFile(string, io.Reader, interface{}) error {
+	panic("unexpected call to SendFile")
+}
+
+func TestPluginInitNegotiation(t *testing.T) {
+	tests := []struct {
+		resp         string
+		version      int
+		capabilities PluginCapabilities
+	}{
+		// Plugins which don't send their version implement all the requests
+		{`{}`, 0, PluginCapabilities{Diff: true}},
+		{`{"Capabilities": {"UIDGIDMaps": true}}`, 0, PluginCapabilities{Diff: true}},
+		{`{"Version": 1}`, 1, PluginCapabilities{}},
+		{`{"Version": 1, "Capabilities": {"UIDGIDMaps": true, "Diff": true}}`, 1, PluginCapabilities{UIDGIDMaps: true, Diff: true}},
+		{`{"Version": 2, "Capabilities": {"Diff": true}}`, 1, PluginCapabilities{Diff: true}},
+	}
+	for _, test := range tests {
+		c := &initClient{resp: test.resp}
+		proxy := &graphDriverProxy{name: "test", client: c}
+		if err := proxy.Init("/home", []string{"opt"}, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if c.req.Version != pluginProtocolVersion || c.req.Home != "/home" || len(c.req.Opts) != 1 {
+			t.Fatalf("Unexpected Init request %+v", c.req)
+		}
+		if proxy.version != test.version {
+			t.Fatalf("Expected the version %d for %s, got %d", test.version, test.resp, proxy.version)
+		}
+		if proxy.capabilities != test.capabilities {
+			t.Fatalf("Expected the capabilities %+v for %s, got %+v", test.capabilities, test.resp, proxy.capabilities)
+		}
+	}
+}
+
+func TestPluginInitUIDGIDMaps(t *testing.T) {
+	uidMaps := []idtools.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	gidMaps := []idtools.IDMap{{ContainerID: 0, HostID: 200000, Size: 65536}}
+
+	c := &initClient{resp: `{"Version": 1, "Capabilities": {"UIDGIDMaps": true}}`}
+	proxy := &graphDriverProxy{name: "test", client: c}
+	if err := proxy.Init("/home", nil, uidMaps, gidMaps); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.req.UIDMaps, uidMaps) || !reflect.DeepEqual(c.req.GIDMaps, gidMaps) {
+		t.Fatalf("Expected the UID and GID maps to be sent, got %+v", c.req)
+	}
+
+	for _, resp := range []string{`{}`, `{"Version": 1, "Capabilities": {"Diff": true}}`} {
+		proxy := &graphDriverProxy{name: "test", client: &initClient{resp: resp}}
+		if err := proxy.Init("/home", nil, uidMaps, gidMaps); err == nil || err.Error() != "graphdriver plugin test does not support user namespaces" {
+			t.Fatalf("Expected the plugin to be refused with user namespaces for %s, got %v", resp, err)
+		}
+	}
+}
+
+func TestPluginDriverNaiveDiff(t *testing.T) {
+	d, err := newPluginDriver("test", "/home", nil, nil, nil, &initClient{resp: `{"Version": 1}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.(*NaiveDiffDriver); !ok {
+		t.Fatalf("Expected the diffs of the plugin to be computed by the daemon, got %T", d)
+	}
+
+	d, err = newPluginDriver("test", "/home", nil, nil, nil, &initClient{resp: `{"Version": 1, "Capabilities": {"Diff": true}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.(*graphDriverProxy); !ok {
+		t.Fatalf("Expected the diffs to be requested from the plugin, got %T", d)
+	}
+}
@@ -0,0 +1,274 @@
+// +build linux
+
+// Package quota limits the size of directories on xfs with project quotas.
+// Each directory is assigned its own project id, and a block limit is set on
+// the project id.
+//
+// The xfs filesystem must be mounted with the "pquota" or "prjquota" option.
+package quota
+
+/*
+#include <stdlib.h>
+#include <linux/fs.h>
+#include <linux/quota.h>
+#include <linux/dqblk_xfs.h>
+
+#ifndef FS_XFLAG_PROJINHERIT
+struct fsxattr {
+	__u32		fsx_xflags;
+	__u32		fsx_extsize;
+	__u32		fsx_nextents;
+	__u32		fsx_projid;
+	unsigned char	fsx_pad[12];
+};
+#define FS_XFLAG_PROJINHERIT	0x00000200
+#endif
+#ifndef FS_IOC_FSGETXATTR
+#define FS_IOC_FSGETXATTR		_IOR ('X', 31, struct fsxattr)
+#endif
+#ifndef FS_IOC_FSSETXATTR
+#define FS_IOC_FSSETXATTR		_IOW ('X', 32, struct fsxattr)
+#endif
+
+#ifndef PRJQUOTA
+#define PRJQUOTA	2
+#endif
+#ifndef XFS_PROJ_QUOTA
+#define XFS_PROJ_QUOTA	2
+#endif
+#ifndef Q_XSETPQLIM
+#define Q_XSETPQLIM QCMD(Q_XSETQLIM, PRJQUOTA)
+#endif
+#ifndef Q_XGETPQUOTA
+#define Q_XGETPQUOTA QCMD(Q_XGETQUOTA, PRJQUOTA)
+#endif
+*/
+import "C"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Quota holds the limits of a directory. Only the size is limited.
+type Quota struct {
+	Size uint64
+}
+
+// Control sets the quotas of the directories under a home directory, such
+// as the layers of a storage driver.
+type Control struct {
+	sync.Mutex
+	backingFsBlockDev string
+	nextProjectID     uint32
+	quotas            map[string]uint32
+}
+
+// NewControl returns a Control for the directories under home, or an error
+// if project quotas are not supported by the filesystem of home.
+//
+// The project ids assigned to the directories are greater than the project
+// id of home. An administrator can assign a project id to home with
+// xfs_quota so that the project ids used by docker don't conflict with
+// their own, e.g. with:
+//
+//    echo 999:/var/lib/docker/overlay2 >> /etc/projects
+//    echo docker:999 >> /etc/projid
+//    xfs_quota -x -c 'project -s docker' /<xfs mount point>
+func NewControl(home string) (*Control, error) {
+	minProjectID, err := getProjectID(home)
+	if err != nil {
+		return nil, err
+	}
+	minProjectID++
+
+	backingFsBlockDev, err := makeBackingFsDev(home)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test if the filesystem supports project quotas by setting a quota on
+	// the first project id
+	if err := setProjectQuota(backingFsBlockDev, minProjectID, Quota{}); err != nil {
+		return nil, err
+	}
+
+	q := &Control{
+		backingFsBlockDev: backingFsBlockDev,
+		nextProjectID:     minProjectID + 1,
+		quotas:            make(map[string]uint32),
+	}
+
+	// Find the project ids already assigned to the directories of home
+	if err := q.findNextProjectID(home); err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("NewControl(%s): nextProjectID = %d", home, q.nextProjectID)
+	return q, nil
+}
+
+// SetQuota assigns a project id to the directory at targetPath, if it has
+// none yet, and sets the limits of the project id. The files and
+// directories created under targetPath afterwards inherit the project id.
+func (q *Control) SetQuota(targetPath string, quota Quota) error {
+	q.Lock()
+	defer q.Unlock()
+
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		projectID = q.nextProjectID
+		if err := setProjectID(targetPath, projectID); err != nil {
+			return err
+		}
+		q.quotas[targetPath] = projectID
+		q.nextProjectID++
+	}
+
+	logrus.Debugf("SetQuota(%s, %d): projectID=%d", targetPath, quota.Size, projectID)
+	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
+}
+
+// GetQuota returns the limits of the directory at targetPath set with
+// SetQuota.
+func (q *Control) GetQuota(targetPath string) (*Quota, error) {
+	q.Lock()
+	projectID, ok := q.quotas[targetPath]
+	q.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("quota not found for path: %s", targetPath)
+	}
+
+	var d C.fs_disk_quota_t
+
+	cs := C.CString(q.backingFsBlockDev)
+	defer C.free(unsafe.Pointer(cs))
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, C.Q_XGETPQUOTA,
+		uintptr(unsafe.Pointer(cs)), uintptr(C.__u32(projectID)),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("Failed to get quota limit for projid %d on %s: %v",
+			projectID, q.backingFsBlockDev, errno.Error())
+	}
+
+	return &Quota{Size: uint64(d.d_blk_hardlimit) * 512}, nil
+}
+
+// setProjectQuota sets the limits of a project id on the xfs block device.
+func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
+	var d C.fs_disk_quota_t
+	d.d_version = C.FS_DQUOT_VERSION
+	d.d_id = C.__u32(projectID)
+	d.d_flags = C.XFS_PROJ_QUOTA
+
+	// The limits are given in 512 bytes blocks
+	d.d_fieldmask = C.FS_DQ_BHARD | C.FS_DQ_BSOFT
+	d.d_blk_hardlimit = C.__u64(quota.Size / 512)
+	d.d_blk_softlimit = d.d_blk_hardlimit
+
+	cs := C.CString(backingFsBlockDev)
+	defer C.free(unsafe.Pointer(cs))
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, C.Q_XSETPQLIM,
+		uintptr(unsafe.Pointer(cs)), uintptr(d.d_id),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("Failed to set quota limit for projid %d on %s: %v",
+			projectID, backingFsBlockDev, errno.Error())
+	}
+	return nil
+}
+
+// getProjectID returns the project id of the directory at targetPath.
+func getProjectID(targetPath string) (uint32, error) {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return 0, fmt.Errorf("Failed to get projid for %s: %v", targetPath, errno.Error())
+	}
+	return uint32(fsx.fsx_projid), nil
+}
+
+// setProjectID assigns a project id to the directory at targetPath, to be
+// inherited by the files and directories created under it.
+func setProjectID(targetPath string, projectID uint32) error {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to get projid for %s: %v", targetPath, errno.Error())
+	}
+	fsx.fsx_projid = C.__u32(projectID)
+	fsx.fsx_xflags |= C.FS_XFLAG_PROJINHERIT
+	_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSSETXATTR,
+		uintptr(unsafe.Pointer(&fsx)))
+	if errno != 0 {
+		return fmt.Errorf("Failed to set projid for %s: %v", targetPath, errno.Error())
+	}
+	return nil
+}
+
+// findNextProjectID records the project ids of the directories of home, and
+// updates the next project id to assign accordingly.
+func (q *Control) findNextProjectID(home string) error {
+	files, err := ioutil.ReadDir(home)
+	if err != nil {
+		return fmt.Errorf("read directory failed: %s", home)
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		path := filepath.Join(home, file.Name())
+		projectID, err := getProjectID(path)
+		if err != nil {
+			return err
+		}
+		if projectID > 0 {
+			q.quotas[path] = projectID
+		}
+		if q.nextProjectID <= projectID {
+			q.nextProjectID = projectID + 1
+		}
+	}
+	return nil
+}
+
+// makeBackingFsDev creates a node for the block device of the filesystem of
+// home, under home, for the quotactl calls.
+func makeBackingFsDev(home string) (string, error) {
+	fileinfo, err := os.Stat(home)
+	if err != nil {
+		return "", err
+	}
+
+	backingFsBlockDev := filepath.Join(home, "backingFsBlockDev")
+	// Re-create it in case the home directory was moved to another device
+	syscall.Unlink(backingFsBlockDev)
+	stat := fileinfo.Sys().(*syscall.Stat_t)
+	if err := syscall.Mknod(backingFsBlockDev, syscall.S_IFBLK|0600, int(stat.Dev)); err != nil {
+		return "", fmt.Errorf("Failed to mknod %s: %v", backingFsBlockDev, err)
+	}
+	return backingFsBlockDev, nil
+}
@@ -0,0 +1,97 @@
+// +build linux
+
+package quota
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// setupXfs mounts a new xfs filesystem with project quotas.
+func setupXfs(t *testing.T) (string, func()) {
+	if _, err := exec.LookPath("mkfs.xfs"); err != nil {
+		t.Skip("mkfs.xfs not found in PATH")
+	}
+	if os.Getuid() != 0 {
+		t.Skip("Test requires root")
+	}
+
+	tmp, err := ioutil.TempDir("", "docker-test-quota-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := filepath.Join(tmp, "xfs.img")
+	mountPoint := filepath.Join(tmp, "mnt")
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	if err := os.Mkdir(mountPoint, 0700); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	f, err := os.Create(image)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	err = f.Truncate(300 * 1024 * 1024)
+	f.Close()
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("mkfs.xfs", "-q", image).CombinedOutput(); err != nil {
+		cleanup()
+		t.Fatalf("mkfs.xfs failed: %v: %s", err, out)
+	}
+	if out, err := exec.Command("mount", "-o", "loop,pquota", image, mountPoint).CombinedOutput(); err != nil {
+		cleanup()
+		t.Skipf("Cannot mount the xfs filesystem: %v: %s", err, out)
+	}
+	return mountPoint, func() {
+		syscall.Unmount(mountPoint, syscall.MNT_DETACH)
+		cleanup()
+	}
+}
+
+func TestSetQuota(t *testing.T) {
+	home, cleanup := setupXfs(t)
+	defer cleanup()
+
+	ctl, err := NewControl(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(home, "limited")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctl.SetQuota(dir, Quota{Size: 10 * 1024 * 1024}); err != nil {
+		t.Fatal(err)
+	}
+	quota, err := ctl.GetQuota(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Size != 10*1024*1024 {
+		t.Fatalf("Expected a quota of 10MB, got %d", quota.Size)
+	}
+
+	// Writing more than the quota fails
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), make([]byte, 20*1024*1024), 0644); err == nil {
+		t.Fatal("Expected the write to exceed the quota")
+	}
+
+	// The assigned project ids are found again
+	ctl2, err := NewControl(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctl2.GetQuota(dir); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package graphdriver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/pkg/units"
+)
+
+// ParseStorageOptSize returns the size in bytes given by the "size" option
+// of the storage options of a layer, or 0 if it is not set. An error is
+// returned for any other option, for drivers only supporting the size.
+func ParseStorageOptSize(storageOpt map[string]string) (uint64, error) {
+	var size uint64
+	for key, val := range storageOpt {
+		switch strings.ToLower(key) {
+		case "size":
+			s, err := units.RAMInBytes(val)
+			if err != nil {
+				return 0, err
+			}
+			if s <= 0 {
+				return 0, fmt.Errorf("Invalid size %s: the size must be positive", val)
+			}
+			size = uint64(s)
+		default:
+			return 0, fmt.Errorf("Unknown option %s", key)
+		}
+	}
+	return size, nil
+}
@@ -0,0 +1,39 @@
+package graphdriver
+
+import (
+	"testing"
+)
+
+func TestParseStorageOptSize(t *testing.T) {
+	valids := map[string]uint64{
+		"":      0,
+		"10G":   10 * 1024 * 1024 * 1024,
+		"512m":  512 * 1024 * 1024,
+		"20000": 20000,
+	}
+	for val, expected := range valids {
+		storageOpt := map[string]string{}
+		if val != "" {
+			storageOpt["size"] = val
+		}
+		size, err := ParseStorageOptSize(storageOpt)
+		if err != nil {
+			t.Fatalf("Expected the size %q to be valid, got %v", val, err)
+		}
+		if size != expected {
+			t.Fatalf("Expected the size %q to be %d bytes, got %d", val, expected, size)
+		}
+	}
+
+	invalids := []map[string]string{
+		{"size": "big"},
+		{"size": "0"},
+		{"size": "-1G"},
+		{"inodes": "1000"},
+	}
+	for _, storageOpt := range invalids {
+		if _, err := ParseStorageOptSize(storageOpt); err == nil {
+			t.Fatalf("Expected the storage options %v to be invalid", storageOpt)
+		}
+	}
+}
@@ -210,7 +210,7 @@ func (d *Driver) GetMetadata(id string) (map[string]string, error) {
 	return nil, nil
 }
 
-func (d *Driver) cloneFilesystem(name, parentName string) error {
+func (d *Driver) cloneFilesystem(name, parentName string, properties map[string]string) error {
 	snapshotName := fmt.Sprintf("%d", time.Now().Nanosecond())
 	parentDataset := zfs.Dataset{Name: parentName}
 	snapshot, err := parentDataset.Snapshot(snapshotName /*recursive */, false)
@@ -218,7 +218,7 @@ func (d *Driver) cloneFilesystem(name, parentName string) error {
 		return err
 	}
 
-	_, err = snapshot.Clone(name, map[string]string{"mountpoint": "legacy"})
+	_, err = snapshot.Clone(name, properties)
 	if err == nil {
 		d.Lock()
 		d.filesystemsCache[name] = true
@@ -241,8 +241,14 @@ func (d *Driver) mountPath(id string) string {
 }
 
 // Create prepares the dataset and filesystem for the ZFS driver for the given id under the parent.
-func (d *Driver) Create(id string, parent string) error {
-	err := d.create(id, parent)
+// The "size" storage option sets the quota of the dataset.
+func (d *Driver) Create(id string, parent string, storageOpt map[string]string) error {
+	size, err := graphdriver.ParseStorageOptSize(storageOpt)
+	if err != nil {
+		return err
+	}
+
+	err = d.create(id, parent, size)
 	if err == nil {
 		return nil
 	}
@@ -261,14 +267,17 @@ func (d *Driver) Create(id string, parent string) error {
 	}
 
 	// retry
-	return d.create(id, parent)
+	return d.create(id, parent, size)
 }
 
-func (d *Driver) create(id, parent string) error {
+func (d *Driver) create(id, parent string, size uint64) error {
 	name := d.zfsPath(id)
+	properties := map[string]string{"mountpoint": "legacy"}
+	if size > 0 {
+		properties["quota"] = strconv.FormatUint(size, 10)
+	}
 	if parent == "" {
-		mountoptions := map[string]string{"mountpoint": "legacy"}
-		fs, err := zfs.CreateFilesystem(name, mountoptions)
+		fs, err := zfs.CreateFilesystem(name, properties)
 		if err == nil {
 			d.Lock()
 			d.filesystemsCache[fs.Name] = true
@@ -276,7 +285,7 @@ func (d *Driver) create(id, parent string) error {
 		}
 		return err
 	}
-	return d.cloneFilesystem(name, d.zfsPath(parent))
+	return d.cloneFilesystem(name, d.zfsPath(parent), properties)
 }
 
 // Remove deletes the dataset, filesystem and the cache for the given id.
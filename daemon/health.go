@@ -0,0 +1,364 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/runconfig"
+)
+
+const (
+	// Longest healthcheck probe output message to store. Longer messages will be truncated.
+	maxOutputLen = 4096
+
+	// Default interval between probe runs (from the end of the first to the start of the second).
+	// Also the time before the first probe.
+	defaultProbeInterval = 30 * time.Second
+
+	// The maximum length of time a single probe run should take. If the probe takes longer
+	// than this, the check is considered to have failed.
+	defaultProbeTimeout = 30 * time.Second
+
+	// Default number of consecutive failures of the health check
+	// for the container to be considered unhealthy.
+	defaultProbeRetries = 3
+
+	// Maximum number of entries to record
+	maxLogEntries = 5
+)
+
+const (
+	// Exit status codes that can be returned by the probe command.
+	exitStatusHealthy   = 0 // Container is healthy
+	exitStatusUnhealthy = 1 // Container is unhealthy
+)
+
+// Health holds the current container health-check state
+type Health struct {
+	types.Health
+	stop chan struct{} // Write struct{} to stop the monitor
+}
+
+// String returns a human-readable description of the health-check state
+func (s *Health) String() string {
+	if s.stop == nil {
+		return "no healthcheck"
+	}
+	switch s.Status {
+	case types.Starting:
+		return "health: starting"
+	default: // Healthy and Unhealthy are clear on their own
+		return s.Status
+	}
+}
+
+// openMonitorChannel creates and returns a new monitor channel. If there already is one,
+// it returns nil.
+func (s *Health) openMonitorChannel() chan struct{} {
+	if s.stop != nil {
+		logrus.Debugf("healthcheck: monitor channel already open")
+		return nil
+	}
+	logrus.Debugf("healthcheck: opening monitor channel")
+	s.stop = make(chan struct{})
+	return s.stop
+}
+
+// closeMonitorChannel closes any existing monitor channel.
+func (s *Health) closeMonitorChannel() {
+	if s.stop != nil {
+		logrus.Debugf("healthcheck: closing monitor channel")
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// probe runs a single healthcheck against a container. A probe taking
+// longer than timeout is stopped.
+type probe interface {
+	run(d *Daemon, container *Container, timeout time.Duration) (*types.HealthcheckResult, error)
+}
+
+// cmdProbe implements the "CMD" and "CMD-SHELL" healthcheck types by
+// executing the test command inside the container.
+type cmdProbe struct {
+	// Run the command with the system's default shell instead of execing it directly.
+	shell bool
+}
+
+// exec the healthcheck command in the container.
+// Returns the exit code and probe output (if any)
+func (p *cmdProbe) run(d *Daemon, container *Container, timeout time.Duration) (*types.HealthcheckResult, error) {
+	cmdSlice := container.Config.Healthcheck.Test[1:]
+	if p.shell {
+		if runtime.GOOS != "windows" {
+			cmdSlice = append([]string{"/bin/sh", "-c"}, cmdSlice...)
+		} else {
+			cmdSlice = append([]string{"cmd", "/S", "/C"}, cmdSlice...)
+		}
+	}
+	execConfig := &runconfig.ExecConfig{
+		Container:    container.ID,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmdSlice,
+	}
+	// The probes don't log exec events, as they run every interval
+	ec, err := d.createExec(execConfig, true)
+	if err != nil {
+		return nil, err
+	}
+	defer d.unregisterExecCommand(ec)
+
+	output := &limitedBuffer{}
+	execErr := make(chan error, 1)
+	go func() {
+		execErr <- d.ContainerExecStart(ec.ID, nil, output, output)
+	}()
+	select {
+	case err := <-execErr:
+		if err != nil {
+			return nil, err
+		}
+	case <-time.After(timeout):
+		// Kill the probe once it is started, rather than leaving it
+		// running in the container
+		select {
+		case <-ec.waitStart:
+			if err := d.killExec(ec); err != nil {
+				logrus.Warnf("Error killing the health check of container %s: %v", container.ID, err)
+			}
+		case <-execErr:
+		}
+		return nil, fmt.Errorf("Health check exceeded timeout (%v)", timeout)
+	}
+
+	ec.Lock()
+	defer ec.Unlock()
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: ec.ExitCode,
+		Output:   output.String(),
+	}, nil
+}
+
+// handleProbeResult updates the container's status, and any log events, based on the results of a probe.
+func handleProbeResult(container *Container, result *types.HealthcheckResult) {
+	container.Lock()
+	defer container.Unlock()
+
+	retries := container.Config.Healthcheck.Retries
+	if retries <= 0 {
+		retries = defaultProbeRetries
+	}
+
+	h := container.State.Health
+	if h == nil {
+		// The container was stopped while the probe was running
+		return
+	}
+	oldStatus := h.Status
+
+	if len(h.Log) >= maxLogEntries {
+		h.Log = append(h.Log[len(h.Log)+1-maxLogEntries:], result)
+	} else {
+		h.Log = append(h.Log, result)
+	}
+
+	if result.ExitCode == exitStatusHealthy {
+		h.FailingStreak = 0
+		h.Status = types.Healthy
+	} else {
+		// Failure (including invalid exit code)
+		h.FailingStreak++
+		if h.FailingStreak >= retries {
+			h.Status = types.Unhealthy
+		}
+		// Else we're starting or healthy. Stay in that state.
+	}
+
+	if err := container.toDisk(); err != nil {
+		logrus.Errorf("Error saving container %s health state to disk: %v", container.ID, err)
+	}
+
+	if oldStatus != h.Status {
+		container.logEvent("health_status: " + h.Status)
+	}
+}
+
+// monitor runs the container's healthcheck probe every interval until stop
+// is closed. Probes are skipped while the container is paused.
+func monitor(d *Daemon, c *Container, stop chan struct{}, probe probe) {
+	probeTimeout := timeoutWithDefault(c.Config.Healthcheck.Timeout, defaultProbeTimeout)
+	probeInterval := timeoutWithDefault(c.Config.Healthcheck.Interval, defaultProbeInterval)
+	for {
+		select {
+		case <-stop:
+			logrus.Debugf("Stop healthcheck monitoring for container %s (received while idle)", c.ID)
+			return
+		case <-time.After(probeInterval):
+			if c.isPaused() {
+				continue
+			}
+			logrus.Debugf("Running health check for container %s ...", c.ID)
+			startTime := time.Now()
+			results := make(chan *types.HealthcheckResult, 1)
+			go func() {
+				result, err := probe.run(d, c, probeTimeout)
+				if err != nil {
+					logrus.Warnf("Health check for container %s error: %v", c.ID, err)
+					result = &types.HealthcheckResult{
+						ExitCode: -1,
+						Output:   err.Error(),
+						End:      time.Now(),
+					}
+				}
+				result.Start = startTime
+				logrus.Debugf("Health check for container %s done (exitCode=%d)", c.ID, result.ExitCode)
+				results <- result
+			}()
+			select {
+			case <-stop:
+				logrus.Debugf("Stop healthcheck monitoring for container %s (received while probing)", c.ID)
+				return
+			case result := <-results:
+				handleProbeResult(c, result)
+			}
+		}
+	}
+}
+
+// getProbe returns the probe for the container's healthcheck, or nil if
+// the container has no healthcheck (or it is explicitly disabled).
+func getProbe(c *Container) probe {
+	config := c.Config.Healthcheck
+	if config == nil || len(config.Test) == 0 {
+		return nil
+	}
+	switch config.Test[0] {
+	case "CMD":
+		return &cmdProbe{shell: false}
+	case "CMD-SHELL":
+		return &cmdProbe{shell: true}
+	case "NONE":
+		return nil
+	default:
+		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD') in container %s", config.Test[0], c.ID)
+		return nil
+	}
+}
+
+// initHealthMonitor is called from monitor.go when the container starts
+// running. If the container has a healthcheck configured, it resets the
+// health state to "starting" and starts a new monitor goroutine.
+func (d *Daemon) initHealthMonitor(c *Container) {
+	probe := getProbe(c)
+	if probe == nil {
+		return
+	}
+
+	// This is needed in case we're auto-restarting
+	d.stopHealthchecks(c)
+
+	h := c.State.Health
+	if h == nil {
+		h = &Health{}
+		c.State.Health = h
+	}
+	h.Status = types.Starting
+	h.FailingStreak = 0
+
+	stop := h.openMonitorChannel()
+	go monitor(d, c, stop, probe)
+}
+
+// restoreHealthMonitor is called from monitor.go when the process of a
+// container left running by the previous daemon is restored. If the container
+// has a healthcheck configured, it starts a new monitor goroutine, keeping the
+// health state of the container.
+func (d *Daemon) restoreHealthMonitor(c *Container) {
+	probe := getProbe(c)
+	if probe == nil {
+		return
+	}
+
+	h := c.State.Health
+	if h == nil || h.Status == "" {
+		d.initHealthMonitor(c)
+		return
+	}
+
+	if stop := h.openMonitorChannel(); stop != nil {
+		go monitor(d, c, stop, probe)
+	}
+}
+
+// stopHealthchecks is called when the container's main process exits, to
+// stop any running probe goroutine. The last results are kept so that they
+// can still be inspected.
+func (d *Daemon) stopHealthchecks(c *Container) {
+	h := c.State.Health
+	if h != nil {
+		h.closeMonitorChannel()
+	}
+}
+
+// timeoutWithDefault returns configuredValue if it is positive, or
+// defaultValue otherwise.
+func timeoutWithDefault(configuredValue time.Duration, defaultValue time.Duration) time.Duration {
+	if configuredValue <= 0 {
+		return defaultValue
+	}
+	return configuredValue
+}
+
+// limitedBuffer is a thread-safe buffer which holds at most maxOutputLen
+// bytes of output; anything beyond that is discarded.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	mu        sync.Mutex
+	truncated bool // indicates that data has been lost
+}
+
+// Write appends to the buffer, discarding data once the buffer is full.
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bufLen := b.buf.Len()
+	dataLen := len(data)
+	keep := min(maxOutputLen-bufLen, dataLen)
+	if keep > 0 {
+		b.buf.Write(data[:keep])
+	}
+	if keep < dataLen {
+		b.truncated = true
+	}
+	return dataLen, nil
+}
+
+// String returns the contents of the buffer, with "..." appended if it
+// overflowed.
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.buf.String()
+	if b.truncated {
+		out = out + "..."
+	}
+	return strings.TrimSpace(out)
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
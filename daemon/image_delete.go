@@ -81,7 +81,7 @@ func (daemon *Daemon) ImageDelete(imageRef string, force, prune bool) ([]types.I
 
 		untaggedRecord := types.ImageDelete{Untagged: parsedRef}
 
-		daemon.EventsService.Log("untag", img.ID, "")
+		daemon.LogImageEvent(img.ID, "untag")
 		records = append(records, untaggedRecord)
 
 		removedRepositoryRef = true
@@ -99,7 +99,7 @@ func (daemon *Daemon) ImageDelete(imageRef string, force, prune bool) ([]types.I
 
 			untaggedRecord := types.ImageDelete{Untagged: parsedRef}
 
-			daemon.EventsService.Log("untag", img.ID, "")
+			daemon.LogImageEvent(img.ID, "untag")
 			records = append(records, untaggedRecord)
 		}
 	}
@@ -166,7 +166,7 @@ func (daemon *Daemon) removeAllReferencesToImageID(imgID string, records *[]type
 
 		untaggedRecord := types.ImageDelete{Untagged: parsedRef}
 
-		daemon.EventsService.Log("untag", imgID, "")
+		daemon.LogImageEvent(imgID, "untag")
 		*records = append(*records, untaggedRecord)
 	}
 
@@ -227,7 +227,7 @@ func (daemon *Daemon) imageDeleteHelper(img *image.Image, records *[]types.Image
 		return err
 	}
 
-	daemon.EventsService.Log("delete", img.ID, "")
+	daemon.LogImageEvent(img.ID, "delete")
 	*records = append(*records, types.ImageDelete{Deleted: img.ID})
 
 	if !prune || img.Parent == "" {
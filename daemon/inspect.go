@@ -2,10 +2,13 @@ package daemon
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/versions/v1p20"
+	"github.com/docker/docker/api/types/versions/v1p21"
+	"github.com/docker/docker/daemon/network"
 )
 
 // ContainerInspect returns low-level information about a
@@ -30,6 +33,19 @@ func (daemon *Daemon) ContainerInspect(name string, size bool) (*types.Container
 	return &types.ContainerJSON{base, mountPoints, container.Config}, nil
 }
 
+// ContainerInspect121 serializes the master version of a container into a
+// json type, with the names of the networks of the container.
+func (daemon *Daemon) ContainerInspect121(name string, size bool) (*v1p21.ContainerJSON, error) {
+	json, err := daemon.ContainerInspect(name, size)
+	if err != nil {
+		return nil, err
+	}
+	return &v1p21.ContainerJSON{
+		ContainerJSON:   json,
+		NetworkSettings: networkSettingsPre122(json.NetworkSettings),
+	}, nil
+}
+
 // ContainerInspect120 serializes the master version of a container into a json type.
 func (daemon *Daemon) ContainerInspect120(name string) (*v1p20.ContainerJSON, error) {
 	container, err := daemon.Get(name)
@@ -54,7 +70,29 @@ func (daemon *Daemon) ContainerInspect120(name string) (*v1p20.ContainerJSON, er
 		container.hostConfig.VolumeDriver,
 	}
 
-	return &v1p20.ContainerJSON{base, mountPoints, config}, nil
+	return &v1p20.ContainerJSON{
+		ContainerJSONBase: base,
+		Mounts:            mountPoints,
+		Config:            config,
+		NetworkSettings:   networkSettingsPre122(base.NetworkSettings),
+	}, nil
+}
+
+// networkSettingsPre122 returns the network settings for APIs prior to 1.22,
+// which only list the names of the networks of the container.
+func networkSettingsPre122(settings *network.Settings) *v1p21.NetworkSettings {
+	if settings == nil {
+		return nil
+	}
+	var networks []string
+	for name := range settings.Networks {
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+	return &v1p21.NetworkSettings{
+		Settings: settings,
+		Networks: networks,
+	}
 }
 
 func (daemon *Daemon) getInspectData(container *Container, size bool) (*types.ContainerJSONBase, error) {
@@ -68,12 +106,13 @@ func (daemon *Daemon) getInspectData(container *Container, size bool) (*types.Co
 	}
 	// we need this trick to preserve empty log driver, so
 	// container will use daemon defaults even if daemon change them
+	defaultLogConfig := daemon.getDefaultLogConfig()
 	if hostConfig.LogConfig.Type == "" {
-		hostConfig.LogConfig.Type = daemon.defaultLogConfig.Type
+		hostConfig.LogConfig.Type = defaultLogConfig.Type
 	}
 
 	if len(hostConfig.LogConfig.Config) == 0 {
-		hostConfig.LogConfig.Config = daemon.defaultLogConfig.Config
+		hostConfig.LogConfig.Config = defaultLogConfig.Config
 	}
 
 	containerState := &types.ContainerState{
@@ -89,6 +128,10 @@ func (daemon *Daemon) getInspectData(container *Container, size bool) (*types.Co
 		StartedAt:  container.State.StartedAt.Format(time.RFC3339Nano),
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
 	}
+	if h := container.State.Health; h != nil {
+		health := h.Health
+		containerState.Health = &health
+	}
 
 	contJSONBase := &types.ContainerJSONBase{
 		ID:              container.ID,
@@ -51,7 +51,13 @@ func (daemon *Daemon) ContainerInspectPre120(name string) (*v1p19.ContainerJSON,
 		container.hostConfig.CpusetCpus,
 	}
 
-	return &v1p19.ContainerJSON{base, volumes, volumesRW, config}, nil
+	return &v1p19.ContainerJSON{
+		ContainerJSONBase: base,
+		Volumes:           volumes,
+		VolumesRW:         volumesRW,
+		Config:            config,
+		NetworkSettings:   networkSettingsPre122(base.NetworkSettings),
+	}, nil
 }
 
 func addMountPoints(container *Container) []types.MountPoint {
@@ -1,6 +1,9 @@
 package daemon
 
-import "github.com/docker/docker/api/types"
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/versions/v1p21"
+)
 
 // This sets platform-specific fields
 func setPlatformSpecificContainerFields(container *Container, contJSONBase *types.ContainerJSONBase) *types.ContainerJSONBase {
@@ -22,6 +25,6 @@ func addMountPoints(container *Container) []types.MountPoint {
 }
 
 // ContainerInspectPre120 get containers for pre 1.20 APIs.
-func (daemon *Daemon) ContainerInspectPre120(name string) (*types.ContainerJSON, error) {
-	return daemon.ContainerInspect(name, false)
+func (daemon *Daemon) ContainerInspectPre120(name string) (*v1p21.ContainerJSON, error) {
+	return daemon.ContainerInspect121(name, false)
 }
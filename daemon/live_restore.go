@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+// isRestorable returns whether the execution driver can restore the process
+// of the container with the given id, which was left running by the previous
+// daemon.
+func (daemon *Daemon) isRestorable(id string) bool {
+	restorer, ok := daemon.execDriver.(execdriver.Restorer)
+	return ok && restorer.Restorable(id)
+}
+
+// verifyLiveRestoreSettings rejects the containers with a TTY when the
+// daemon keeps the containers running when it exits, since their TTY cannot
+// outlive the daemon.
+func (daemon *Daemon) verifyLiveRestoreSettings(config *runconfig.Config) error {
+	if daemon.configStore.LiveRestore && config.Tty {
+		return derr.ErrorCodeLiveRestoreTty
+	}
+	return nil
+}
+
+// keepRunning returns whether the container is left running when the daemon
+// shuts down.
+func (daemon *Daemon) keepRunning(container *Container) bool {
+	if !daemon.configStore.LiveRestore || !container.IsRunning() {
+		return false
+	}
+	if _, ok := daemon.execDriver.(execdriver.Restorer); !ok {
+		return false
+	}
+	container.Lock()
+	defer container.Unlock()
+	return container.command != nil && container.command.LiveRestore
+}
+
+// restoreContainer monitors again the process of a container left running by
+// the previous daemon. It is connected to its networks again, and its IPC
+// mounts were kept.
+func (daemon *Daemon) restoreContainer(container *Container) (err error) {
+	container.Lock()
+	defer container.Unlock()
+
+	defer func() {
+		if err != nil {
+			container.setError(err)
+			daemon.execDriver.Terminate(&execdriver.Command{ID: container.ID})
+			// Like the running containers killed when the daemon starts
+			container.setStopped(&execdriver.ExitStatus{ExitCode: 137})
+			container.toDisk()
+			container.cleanup()
+			container.logEvent("die")
+		}
+	}()
+
+	if err := container.Mount(); err != nil {
+		return err
+	}
+	container.hostConfig = runconfig.SetDefaultNetModeIfBlank(container.hostConfig)
+	if err := container.reconnectNetwork(); err != nil {
+		return err
+	}
+
+	// The environment is only used if the container restarts
+	linkedEnv, err := container.setupLinkedContainers()
+	if err != nil {
+		logrus.Warnf("Failed to restore the links of container %s: %v", container.ID, err)
+	}
+	env := container.createDaemonEnvironment(linkedEnv)
+	if err := populateCommand(container, env); err != nil {
+		return err
+	}
+
+	mounts, err := container.setupMounts()
+	if err != nil {
+		return err
+	}
+	container.command.Mounts = append(mounts, container.ipcMounts()...)
+
+	container.monitor = newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	container.monitor.restore = true
+	go container.monitor.Start()
+	return nil
+}
+
+// restoreProcess waits for the exit of the process of a restored container.
+// The process is killed if it cannot be restored.
+func (daemon *Daemon) restoreProcess(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	restorer := daemon.execDriver.(execdriver.Restorer)
+	exitStatus, err := restorer.Restore(c.command, pipes, execdriver.Hooks{Start: startCallback})
+	if err != nil {
+		logrus.Errorf("Error restoring container %s: %v", c.ID, err)
+		daemon.execDriver.Terminate(c.command)
+		return execdriver.ExitStatus{ExitCode: 137}, nil
+	}
+	return exitStatus, nil
+}
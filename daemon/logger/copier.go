@@ -8,8 +8,21 @@ import (
 	"time"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// droppedMessages counts the messages the log drivers failed to log.
+var droppedMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "engine",
+	Subsystem: "logger",
+	Name:      "dropped_messages_total",
+	Help:      "The number of messages the log drivers failed to log",
+}, []string{"driver"})
+
+func init() {
+	prometheus.MustRegister(droppedMessages)
+}
+
 // Copier can copy logs from specified sources to Logger and attach
 // ContainerID and Timestamp.
 // Writes are concurrent, so you need implement some sync in your logger
@@ -52,6 +65,7 @@ func (c *Copier) copySrc(name string, src io.Reader) {
 		if err == nil || len(line) > 0 {
 			if logErr := c.dst.Log(&Message{ContainerID: c.cid, Line: line, Source: name, Timestamp: time.Now().UTC()}); logErr != nil {
 				logrus.Errorf("Failed to log msg %q for logger %s: %s", line, c.dst.Name(), logErr)
+				droppedMessages.WithLabelValues(c.dst.Name()).Inc()
 			}
 		}
 
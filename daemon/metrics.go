@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// containerActions records the duration of the create, start, stop and
+// delete actions on the containers, by result, and the time taken to clean
+// up a container after its process died.
+var containerActions = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "engine",
+	Subsystem: "daemon",
+	Name:      "container_actions_seconds",
+	Help:      "The number of seconds taken by the actions on the containers",
+}, []string{"action", "result"})
+
+func init() {
+	prometheus.MustRegister(containerActions)
+}
+
+// observeContainerAction records the number of seconds taken by an action
+// on a container since start. The action failed if *err is not nil once it
+// returns.
+func observeContainerAction(action string, start time.Time, err *error) {
+	result := "success"
+	if *err != nil {
+		result = "failure"
+	}
+	containerActions.WithLabelValues(action, result).Observe(time.Since(start).Seconds())
+}
@@ -53,6 +53,14 @@ type containerMonitor struct {
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// restore is true when the monitor first restores the process left
+	// running by the previous daemon instead of starting a new one
+	restore bool
+
+	// checkpointDir is the directory of the checkpoint from which the
+	// processes of the container are first restored, if any
+	checkpointDir string
 }
 
 // newContainerMonitor returns an initialized containerMonitor for the provided container
@@ -124,11 +132,15 @@ func (m *containerMonitor) Start() error {
 		m.container.HasBeenManuallyStopped = false
 	}
 
-	// reset the restart count
-	m.container.RestartCount = -1
+	// reset the restart count, unless the process is restored
+	if !m.restore {
+		m.container.RestartCount = -1
+	}
 
 	for {
-		m.container.RestartCount++
+		if !m.restore {
+			m.container.RestartCount++
+		}
 
 		if err := m.container.startLogging(); err != nil {
 			m.resetContainer(false)
@@ -138,11 +150,23 @@ func (m *containerMonitor) Start() error {
 
 		pipes := execdriver.NewPipes(m.container.stdin, m.container.stdout, m.container.stderr, m.container.Config.OpenStdin)
 
-		m.container.logEvent("start")
+		if m.restore {
+			m.lastStartTime = m.container.StartedAt
+			exitStatus, err = m.container.daemon.restoreProcess(m.container, pipes, m.callback)
+			m.restore = false
+		} else {
+			m.container.logEvent("start")
 
-		m.lastStartTime = time.Now()
+			m.lastStartTime = time.Now()
 
-		if exitStatus, err = m.container.daemon.run(m.container, pipes, m.callback); err != nil {
+			if m.checkpointDir != "" {
+				exitStatus, err = m.container.daemon.runCheckpoint(m.container, pipes, m.callback, m.checkpointDir)
+				m.checkpointDir = ""
+			} else {
+				exitStatus, err = m.container.daemon.run(m.container, pipes, m.callback)
+			}
+		}
+		if err != nil {
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
 			if m.container.RestartCount == 0 {
@@ -157,6 +181,11 @@ func (m *containerMonitor) Start() error {
 
 		// here container.Lock is already lost
 		afterRun = true
+		diedAt := time.Now()
+
+		m.container.Lock()
+		m.container.daemon.stopHealthchecks(m.container)
+		m.container.Unlock()
 
 		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
 
@@ -164,6 +193,7 @@ func (m *containerMonitor) Start() error {
 			m.container.setRestarting(&exitStatus)
 			m.container.logEvent("die")
 			m.resetContainer(true)
+			containerActions.WithLabelValues("die", "success").Observe(time.Since(diedAt).Seconds())
 
 			// sleep with a small time increment between each restart to help avoid issues cased by quickly
 			// restarting the container because of some types of errors ( networking cut out, etc... )
@@ -179,6 +209,7 @@ func (m *containerMonitor) Start() error {
 
 		m.container.logEvent("die")
 		m.resetContainer(true)
+		containerActions.WithLabelValues("die", "success").Observe(time.Since(diedAt).Seconds())
 		return err
 	}
 }
@@ -262,7 +293,13 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 		}
 	}
 
-	m.container.setRunning(pid)
+	// The state of a restored process is already running
+	if !m.restore {
+		m.container.setRunning(pid)
+		m.container.daemon.initHealthMonitor(m.container)
+	} else {
+		m.container.daemon.restoreHealthMonitor(m.container)
+	}
 
 	// signal that the process has started
 	// close channel only if not closed
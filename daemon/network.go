@@ -99,9 +99,15 @@ func (daemon *Daemon) CreateNetwork(name, driver string, ipam network.IPAM, opti
 		return nil, err
 	}
 
-	nwOptions = append(nwOptions, libnetwork.NetworkOptionIpam(ipam.Driver, "", v4Conf, v6Conf))
+	nwOptions = append(nwOptions, libnetwork.NetworkOptionIpam(ipam.Driver, "", v4Conf, v6Conf, nil))
 	nwOptions = append(nwOptions, libnetwork.NetworkOptionDriverOpts(options))
-	return c.NewNetwork(driver, name, nwOptions...)
+	n, err := c.NewNetwork(driver, name, nwOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	daemon.LogNetworkEvent(n, "create")
+	return n, nil
 }
 
 func getIpamConfig(data []network.IPAMConfig) ([]*libnetwork.IpamConf, []*libnetwork.IpamConf, error) {
@@ -127,14 +133,14 @@ func getIpamConfig(data []network.IPAMConfig) ([]*libnetwork.IpamConf, []*libnet
 }
 
 // ConnectContainerToNetwork connects the given container to the given
-// network. If either cannot be found, an err is returned. If the
-// network cannot be set up, an err is returned.
-func (daemon *Daemon) ConnectContainerToNetwork(containerName, networkName string) error {
+// network, with the given endpoint settings. If either cannot be found,
+// an err is returned. If the network cannot be set up, an err is returned.
+func (daemon *Daemon) ConnectContainerToNetwork(containerName, networkName string, endpointConfig *network.EndpointSettings) error {
 	container, err := daemon.Get(containerName)
 	if err != nil {
 		return err
 	}
-	return container.ConnectToNetwork(networkName)
+	return container.ConnectToNetwork(networkName, endpointConfig)
 }
 
 // DisconnectContainerFromNetwork disconnects the given container from
@@ -146,3 +152,12 @@ func (daemon *Daemon) DisconnectContainerFromNetwork(containerName string, netwo
 	}
 	return container.DisconnectFromNetwork(network)
 }
+
+// DeleteNetwork removes the given network.
+func (daemon *Daemon) DeleteNetwork(nw libnetwork.Network) error {
+	if err := nw.Delete(); err != nil {
+		return err
+	}
+	daemon.LogNetworkEvent(nw, "destroy")
+	return nil
+}
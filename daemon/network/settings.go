@@ -1,6 +1,10 @@
 package network
 
-import "github.com/docker/docker/pkg/nat"
+import (
+	"encoding/json"
+
+	"github.com/docker/docker/pkg/nat"
+)
 
 // Address represents an IP address
 type Address struct {
@@ -38,9 +42,70 @@ type Settings struct {
 	LinkLocalIPv6Address   string
 	LinkLocalIPv6PrefixLen int
 	MacAddress             string
-	Networks               []string
+	Networks               map[string]*EndpointSettings
 	Ports                  nat.PortMap
 	SandboxKey             string
 	SecondaryIPAddresses   []Address
 	SecondaryIPv6Addresses []Address
 }
+
+// UnmarshalJSON decodes the settings, converting the list of network names
+// saved by older daemons into endpoint settings.
+func (s *Settings) UnmarshalJSON(b []byte) error {
+	type settings Settings
+	var v struct {
+		*settings
+		Networks json.RawMessage
+	}
+	v.settings = (*settings)(s)
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	s.Networks = nil
+	if len(v.Networks) == 0 || string(v.Networks) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(v.Networks, &s.Networks); err == nil {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(v.Networks, &names); err != nil {
+		return err
+	}
+	s.Networks = make(map[string]*EndpointSettings, len(names))
+	for _, name := range names {
+		s.Networks[name] = &EndpointSettings{}
+	}
+	return nil
+}
+
+// EndpointIPAMConfig represents the IP addresses requested for an endpoint
+type EndpointIPAMConfig struct {
+	IPv4Address string `json:",omitempty"`
+	IPv6Address string `json:",omitempty"`
+}
+
+// EndpointSettings stores the configuration and the operational data of
+// the endpoint of a container in a network
+type EndpointSettings struct {
+	// Configurations
+	IPAMConfig *EndpointIPAMConfig
+	Links      []string
+	Aliases    []string
+	// Operational data
+	NetworkID           string
+	EndpointID          string
+	Gateway             string
+	IPAddress           string
+	IPPrefixLen         int
+	IPv6Gateway         string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+	MacAddress          string
+}
+
+// NetworkingConfig represents the endpoint settings requested for each
+// network a container is connected to on create
+type NetworkingConfig struct {
+	EndpointsConfig map[string]*EndpointSettings
+}
@@ -0,0 +1,33 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSettingsUnmarshalNetworks(t *testing.T) {
+	var s Settings
+	if err := json.Unmarshal([]byte(`{"IPAddress":"172.17.0.2","Networks":{"n1":{"Aliases":["db"],"IPAddress":"172.28.0.5"}}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.IPAddress != "172.17.0.2" {
+		t.Fatalf("Expected IPAddress 172.17.0.2, got %s", s.IPAddress)
+	}
+	ep, ok := s.Networks["n1"]
+	if !ok || ep.IPAddress != "172.28.0.5" || len(ep.Aliases) != 1 || ep.Aliases[0] != "db" {
+		t.Fatalf("Unexpected endpoint settings for n1: %+v", ep)
+	}
+}
+
+func TestSettingsUnmarshalLegacyNetworks(t *testing.T) {
+	var s Settings
+	if err := json.Unmarshal([]byte(`{"Bridge":"docker0","Networks":["bridge","n1"]}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Bridge != "docker0" {
+		t.Fatalf("Expected Bridge docker0, got %s", s.Bridge)
+	}
+	if len(s.Networks) != 2 || s.Networks["bridge"] == nil || s.Networks["n1"] == nil {
+		t.Fatalf("Expected endpoint settings for bridge and n1, got %v", s.Networks)
+	}
+}
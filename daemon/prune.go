@@ -0,0 +1,234 @@
+package daemon
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/pkg/parsers/filters"
+	"github.com/docker/docker/pkg/timeutils"
+	"github.com/docker/docker/volume"
+	"github.com/docker/docker/volume/store"
+	"github.com/docker/libnetwork"
+)
+
+// predefinedNetworks are the networks created by the daemon itself, which
+// are never pruned.
+var predefinedNetworks = map[string]bool{
+	"bridge": true,
+	"host":   true,
+	"none":   true,
+}
+
+// pruneFilter holds the filters of a prune operation: objects are only
+// pruned if they match all the given labels and were created before until.
+type pruneFilter struct {
+	filters filters.Args
+	until   time.Time
+}
+
+// newPruneFilter validates the filters of a prune request, of which only
+// the given ones are accepted.
+func newPruneFilter(filter string, accepted ...string) (*pruneFilter, error) {
+	pruneFilters, err := filters.FromParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedFilters := make(map[string]bool, len(accepted))
+	for _, name := range accepted {
+		acceptedFilters[name] = true
+	}
+	for name := range pruneFilters {
+		if !acceptedFilters[name] {
+			return nil, derr.ErrorCodeInvalidPruneFilter.WithArgs(name)
+		}
+	}
+
+	pf := &pruneFilter{filters: pruneFilters}
+	if values, ok := pruneFilters["until"]; ok {
+		if len(values) > 1 {
+			return nil, derr.ErrorCodeInvalidUntilFilter.WithArgs(strings.Join(values, ", "))
+		}
+		ts, err := strconv.ParseInt(timeutils.GetTimestamp(values[0], time.Now()), 10, 64)
+		if err != nil {
+			return nil, derr.ErrorCodeInvalidUntilFilter.WithArgs(values[0])
+		}
+		pf.until = time.Unix(ts, 0)
+	}
+	return pf, nil
+}
+
+// match returns whether an object with the given labels and creation time
+// matches the filter. A zero created time means the creation time of the
+// object is unknown, so that it never matches an until filter.
+func (pf *pruneFilter) match(labels map[string]string, created time.Time) bool {
+	if !pf.filters.MatchKVList("label", labels) {
+		return false
+	}
+	if !pf.until.IsZero() && (created.IsZero() || !created.Before(pf.until)) {
+		return false
+	}
+	return true
+}
+
+// ContainersPrune removes the stopped containers matching the given
+// filters, along with their writable layer.
+func (daemon *Daemon) ContainersPrune(filter string) (*types.ContainersPruneReport, error) {
+	pf, err := newPruneFilter(filter, "label", "until")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.ContainersPruneReport{}
+	for _, container := range daemon.List() {
+		if container.IsRunning() || !pf.match(container.Config.Labels, container.Created) {
+			continue
+		}
+		sizeRw, _ := container.getSize()
+		if err := daemon.ContainerRm(container.ID, &ContainerRmConfig{}); err != nil {
+			logrus.Warnf("failed to prune container %s: %v", container.ID, err)
+			continue
+		}
+		if sizeRw > 0 {
+			report.SpaceReclaimed += uint64(sizeRw)
+		}
+		report.ContainersDeleted = append(report.ContainersDeleted, container.ID)
+	}
+	return report, nil
+}
+
+// ImagesPrune removes the dangling images matching the given filters. If
+// the dangling filter is false, all the images which are not used by any
+// container are removed, including tagged ones. The parents of the removed
+// images are removed too, unless they are used.
+func (daemon *Daemon) ImagesPrune(filter string) (*types.ImagesPruneReport, error) {
+	pf, err := newPruneFilter(filter, "dangling", "label", "until")
+	if err != nil {
+		return nil, err
+	}
+
+	danglingOnly := true
+	if values, ok := pf.filters["dangling"]; ok {
+		if len(values) > 1 {
+			return nil, derr.ErrorCodeDanglingOne
+		}
+		switch strings.ToLower(values[0]) {
+		case "true", "1":
+		case "false", "0":
+			danglingOnly = false
+		default:
+			return nil, derr.ErrorCodeInvalidPruneFilter.WithArgs("dangling=" + values[0])
+		}
+	}
+
+	// Record the size of all the images up front, as the parents removed
+	// along with an image are only known from the delete records
+	allImages := daemon.graph.Map()
+	refs := daemon.repositories.ByID()
+
+	report := &types.ImagesPruneReport{}
+	for id, img := range daemon.graph.Heads() {
+		if danglingOnly && len(refs[id]) > 0 {
+			continue
+		}
+		var labels map[string]string
+		if img.Config != nil {
+			labels = img.Config.Labels
+		}
+		if !pf.match(labels, img.Created) || daemon.getContainerUsingImage(id) != nil {
+			continue
+		}
+
+		// Removing the last reference of a tagged image removes the image
+		toDelete := refs[id]
+		if len(toDelete) == 0 {
+			toDelete = []string{id}
+		}
+		for _, ref := range toDelete {
+			records, err := daemon.ImageDelete(ref, false, true)
+			if err != nil {
+				logrus.Warnf("failed to prune image %s: %v", ref, err)
+				break
+			}
+			report.ImagesDeleted = append(report.ImagesDeleted, records...)
+		}
+	}
+
+	for _, record := range report.ImagesDeleted {
+		if img, ok := allImages[record.Deleted]; ok && img.Size > 0 {
+			report.SpaceReclaimed += uint64(img.Size)
+		}
+	}
+	return report, nil
+}
+
+// VolumesPrune removes the volumes which are not referenced by any
+// container. Volumes have neither labels nor a creation time, so that no
+// filter is accepted.
+func (daemon *Daemon) VolumesPrune(filter string) (*types.VolumesPruneReport, error) {
+	if _, err := newPruneFilter(filter); err != nil {
+		return nil, err
+	}
+
+	report := &types.VolumesPruneReport{}
+	for _, v := range daemon.volumes.List() {
+		if daemon.volumes.Count(v) > 0 {
+			continue
+		}
+		var (
+			size int64
+			err  error
+		)
+		if v.DriverName() == volume.DefaultDriverName {
+			if size, err = directory.Size(v.Path()); err != nil {
+				logrus.Warnf("could not determine the size of volume %s: %v", v.Name(), err)
+			}
+		}
+		if err := daemon.volumes.Remove(v); err != nil {
+			// The volume may have been mounted since it was listed
+			if err != store.ErrVolumeInUse {
+				logrus.Warnf("failed to prune volume %s: %v", v.Name(), err)
+			}
+			continue
+		}
+		if size > 0 {
+			report.SpaceReclaimed += uint64(size)
+		}
+		report.VolumesDeleted = append(report.VolumesDeleted, v.Name())
+	}
+	return report, nil
+}
+
+// NetworksPrune removes the networks which have no endpoint, except the
+// networks created by the daemon. Networks have neither labels nor a
+// creation time, so that no filter is accepted.
+func (daemon *Daemon) NetworksPrune(filter string) (*types.NetworksPruneReport, error) {
+	if _, err := newPruneFilter(filter); err != nil {
+		return nil, err
+	}
+
+	var unused []libnetwork.Network
+	defaultNetwork := daemon.netController.Config().Daemon.DefaultNetwork
+	daemon.netController.WalkNetworks(func(nw libnetwork.Network) bool {
+		if !predefinedNetworks[nw.Name()] && nw.Name() != defaultNetwork &&
+			len(nw.Endpoints()) == 0 {
+			unused = append(unused, nw)
+		}
+		return false
+	})
+
+	report := &types.NetworksPruneReport{}
+	for _, nw := range unused {
+		if err := daemon.DeleteNetwork(nw); err != nil {
+			logrus.Warnf("failed to prune network %s: %v", nw.Name(), err)
+			continue
+		}
+		report.NetworksDeleted = append(report.NetworksDeleted, nw.Name())
+	}
+	return report, nil
+}
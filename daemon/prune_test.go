@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPruneFilterInvalid(t *testing.T) {
+	invalid := []string{
+		`{"name":["foo"]}`,
+		`{"dangling":["true"]}`,
+		`{"until":["yesterday"]}`,
+		`{"until":["1h","2h"]}`,
+	}
+	for _, filter := range invalid {
+		if _, err := newPruneFilter(filter, "label", "until"); err == nil {
+			t.Fatalf("Expected an error for filter %s", filter)
+		}
+	}
+
+	if _, err := newPruneFilter(`{"dangling":["true"]}`, "dangling", "label", "until"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewPruneFilterNoneAccepted(t *testing.T) {
+	for _, filter := range []string{`{"label":["foo=bar"]}`, `{"until":["1h"]}`} {
+		if _, err := newPruneFilter(filter); err == nil {
+			t.Fatalf("Expected an error for filter %s", filter)
+		}
+	}
+
+	if _, err := newPruneFilter(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPruneFilterMatch(t *testing.T) {
+	pf, err := newPruneFilter("", "label", "until")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pf.match(nil, time.Time{}) {
+		t.Fatal("Expected an empty filter to match everything")
+	}
+
+	pf, err = newPruneFilter(`{"label":["foo=bar"],"until":["1h"]}`, "label", "until")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	labels := map[string]string{"foo": "bar"}
+	if !pf.match(labels, old) {
+		t.Fatal("Expected an old object with the label to match")
+	}
+	if pf.match(labels, time.Now()) {
+		t.Fatal("Expected a recent object not to match")
+	}
+	if pf.match(map[string]string{"foo": "baz"}, old) {
+		t.Fatal("Expected an object with another label value not to match")
+	}
+	if pf.match(labels, time.Time{}) {
+		t.Fatal("Expected an object with an unknown creation time not to match")
+	}
+}
@@ -0,0 +1,208 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/runconfig"
+)
+
+// ReloadConfig holds the options of the configuration file of the daemon
+// when it is reloaded. Only the labels, the debug mode, the default log
+// configuration of the containers, the registry mirrors, the insecure
+// registries, the registry configuration file and the cluster store options
+// are applied; the other options take effect when the daemon is restarted.
+type ReloadConfig struct {
+	Config
+	Debug              bool
+	LogLevel           string
+	Mirrors            []string
+	InsecureRegistries []string
+	Registries         registry.RegistriesOpt
+
+	// options holds the values of the options of the configuration file.
+	options map[string][]string
+}
+
+// NewReloadConfig returns the configuration to reload from the values of
+// the options of the configuration file of the daemon. Like when the daemon
+// starts, it fails if an option is also set in the flags of the daemon.
+func NewReloadConfig(options map[string][]string, daemonFlags *flag.FlagSet) (*ReloadConfig, error) {
+	if err := findConfigConflicts(daemonFlags, options); err != nil {
+		return nil, err
+	}
+
+	config := &ReloadConfig{options: options}
+	config.LogConfig.Config = make(map[string]string)
+	config.ClusterOpts = make(map[string]string)
+
+	flags := flag.NewFlagSet("reload", flag.ContinueOnError)
+	config.InstallFlags(flags, func(string) string { return "" })
+	flags.BoolVar(&config.Debug, []string{"D", "-debug"}, false, "")
+	flags.StringVar(&config.LogLevel, []string{"l", "-log-level"}, "info", "")
+	flags.Var(opts.NewListOptsRef(&config.Mirrors, registry.ValidateMirror), []string{"-registry-mirror"}, "")
+	flags.Var(opts.NewListOptsRef(&config.InsecureRegistries, registry.ValidateIndexName), []string{"-insecure-registry"}, "")
+	flags.Var(&config.Registries, []string{"-registry-config"}, "")
+
+	// The options of the API server are only used when the daemon starts.
+	if err := setConfigOptions(flags, options, true); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// isSet returns whether the option with the given name is set in the
+// configuration file, possibly to an empty array or object.
+func (config *ReloadConfig) isSet(name string) bool {
+	_, ok := config.options[name]
+	return ok
+}
+
+// Reload applies the options of the configuration file which can be changed
+// while the daemon is running, and logs a daemon reload event with the
+// options which changed. The options absent from the file are left
+// unchanged.
+func (daemon *Daemon) Reload(config *ReloadConfig) error {
+	daemon.reloadLock.Lock()
+	defer daemon.reloadLock.Unlock()
+
+	// Validate everything before changing anything
+	logConfig := daemon.defaultLogConfig
+	if config.isSet("log-driver") {
+		logConfig.Type = config.LogConfig.Type
+	}
+	if config.isSet("log-opt") {
+		logConfig.Config = config.LogConfig.Config
+	}
+	if logConfig.Type != "none" {
+		if _, err := logger.GetLogDriver(logConfig.Type); err != nil {
+			return fmt.Errorf("error finding the logging driver: %v", err)
+		}
+	}
+	if len(logConfig.Config) > 0 {
+		if err := logger.ValidateLogOpts(logConfig.Type, logConfig.Config); err != nil {
+			return err
+		}
+	}
+	var level logrus.Level
+	if config.isSet("debug") && !config.Debug {
+		var err error
+		if level, err = logrus.ParseLevel(config.LogLevel); err != nil {
+			return err
+		}
+	}
+	mirrors := daemon.RegistryService.Mirrors()
+	if config.isSet("registry-mirror") {
+		mirrors = config.Mirrors
+	}
+	insecureRegistries := daemon.RegistryService.InsecureRegistries()
+	if config.isSet("insecure-registry") {
+		insecureRegistries = config.InsecureRegistries
+	}
+	if err := registry.ValidateReload(mirrors, insecureRegistries); err != nil {
+		return err
+	}
+
+	attributes := make(map[string]string)
+
+	// Registering the daemon again in the discovery backend is the only
+	// change which can fail, so it is done first.
+	if config.isSet("cluster-store-opt") && !reflect.DeepEqual(config.ClusterOpts, daemon.configStore.ClusterOpts) {
+		if err := daemon.reloadClusterDiscovery(config.ClusterOpts); err != nil {
+			return err
+		}
+		attributes["cluster-store-opt"] = optionNames(config.ClusterOpts)
+	}
+
+	if config.isSet("label") && !reflect.DeepEqual(config.Labels, daemon.configStore.Labels) {
+		daemon.configStore.Labels = config.Labels
+		attributes["label"] = strings.Join(config.Labels, ",")
+	}
+
+	if debug := os.Getenv("DEBUG") != ""; config.isSet("debug") && config.Debug != debug {
+		if config.Debug {
+			os.Setenv("DEBUG", "1")
+			logrus.SetLevel(logrus.DebugLevel)
+		} else {
+			os.Unsetenv("DEBUG")
+			logrus.SetLevel(level)
+		}
+		attributes["debug"] = fmt.Sprint(config.Debug)
+	}
+
+	if logConfig.Type != daemon.defaultLogConfig.Type {
+		attributes["log-driver"] = logConfig.Type
+	}
+	if !reflect.DeepEqual(logConfig.Config, daemon.defaultLogConfig.Config) {
+		attributes["log-opt"] = optionNames(logConfig.Config)
+	}
+	daemon.defaultLogConfig = logConfig
+	daemon.configStore.LogConfig = logConfig
+
+	// The mirrors, the insecure registries and the registry configuration
+	// file are replaced together, once they were all validated
+	registries := daemon.RegistryService.Registries()
+	if config.isSet("registry-mirror") && !reflect.DeepEqual(config.Mirrors, daemon.RegistryService.Mirrors()) {
+		attributes["registry-mirror"] = strings.Join(config.Mirrors, ",")
+	}
+	if config.isSet("insecure-registry") && !reflect.DeepEqual(config.InsecureRegistries, daemon.RegistryService.InsecureRegistries()) {
+		attributes["insecure-registry"] = strings.Join(config.InsecureRegistries, ",")
+	}
+	if config.isSet("registry-config") {
+		registries = config.Registries
+		attributes["registry-config"] = config.Registries.Path()
+	}
+	if config.isSet("registry-mirror") || config.isSet("insecure-registry") || config.isSet("registry-config") {
+		if err := daemon.RegistryService.Reload(mirrors, insecureRegistries, registries); err != nil {
+			logrus.Errorf("Error reloading the registry configuration: %v", err)
+		}
+	}
+
+	daemon.LogDaemonEventWithAttributes("reload", attributes)
+	return nil
+}
+
+// reloadClusterDiscovery registers the daemon again in the discovery
+// backend with the given cluster store options, such as new TLS
+// certificates.
+func (daemon *Daemon) reloadClusterDiscovery(clusterOpts map[string]string) error {
+	config := daemon.configStore
+	if daemon.discoveryStop != nil {
+		stopCh := make(chan struct{})
+		if _, err := initDiscovery(config.ClusterStore, config.ClusterAdvertise, clusterOpts, stopCh); err != nil {
+			close(stopCh)
+			return fmt.Errorf("discovery initialization failed (%v)", err)
+		}
+		close(daemon.discoveryStop)
+		daemon.discoveryStop = stopCh
+	}
+	config.ClusterOpts = clusterOpts
+	return nil
+}
+
+// getDefaultLogConfig returns the log configuration of the containers which
+// do not set their own.
+func (daemon *Daemon) getDefaultLogConfig() runconfig.LogConfig {
+	daemon.reloadLock.Lock()
+	defer daemon.reloadLock.Unlock()
+	return daemon.defaultLogConfig
+}
+
+// optionNames returns the sorted names of key=value options, without their
+// values which may be secrets.
+func optionNames(options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
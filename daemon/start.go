@@ -2,14 +2,19 @@ package daemon
 
 import (
 	"runtime"
+	"time"
 
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
 )
 
-// ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConfig) error {
+// ContainerStart starts a container. If checkpoint is not empty, the
+// processes of the container are restored from this checkpoint instead of
+// being started.
+func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConfig, checkpoint string) (retErr error) {
+	defer observeContainerAction("start", time.Now(), &retErr)
+
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
@@ -43,8 +48,21 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *runconfig.HostConf
 	if _, err = daemon.verifyContainerSettings(container.hostConfig, nil); err != nil {
 		return err
 	}
+	if err = daemon.verifyLiveRestoreSettings(container.Config); err != nil {
+		return err
+	}
+
+	var checkpointDir string
+	if checkpoint != "" {
+		if _, err := daemon.checkpointer(); err != nil {
+			return err
+		}
+		if checkpointDir, err = container.checkpointDir(checkpoint); err != nil {
+			return err
+		}
+	}
 
-	if err := container.Start(); err != nil {
+	if err := container.start(checkpointDir); err != nil {
 		return derr.ErrorCodeCantStart.WithArgs(name, utils.GetErrorMessage(err))
 	}
 
@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"time"
+
 	derr "github.com/docker/docker/errors"
 )
 
@@ -10,7 +12,9 @@ import (
 // will wait for a graceful termination. An error is returned if the
 // container is not found, is already stopped, or if there is a
 // problem stopping the container.
-func (daemon *Daemon) ContainerStop(name string, seconds int) error {
+func (daemon *Daemon) ContainerStop(name string, seconds int) (retErr error) {
+	defer observeContainerAction("stop", time.Now(), &retErr)
+
 	container, err := daemon.Get(name)
 	if err != nil {
 		return err
@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate updates the resource limits of a container. Only the
+// resource fields which are set in hostConfig are changed. If the container
+// is running, the new limits are applied to it straight away, otherwise they
+// take effect the next time it is started.
+func (daemon *Daemon) ContainerUpdate(name string, hostConfig *runconfig.HostConfig) ([]string, error) {
+	container, err := daemon.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if container.removalInProgress || container.Dead {
+		return nil, derr.ErrorCodeCantUpdate.WithArgs(container.ID, "container is marked for removal")
+	}
+
+	// Work on a copy, so a failed validation or driver update leaves the
+	// container untouched.
+	newConfig := *container.hostConfig
+	mergeResources(&newConfig, hostConfig)
+
+	if container.Running && newConfig.KernelMemory != container.hostConfig.KernelMemory {
+		return nil, derr.ErrorCodeCantUpdate.WithArgs(container.ID, "kernel memory can not be updated on a running container, please stop it first")
+	}
+
+	warnings, err := daemon.verifyContainerSettings(&newConfig, nil)
+	if err != nil {
+		return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+	}
+
+	if container.Running && container.command != nil && container.command.Resources != nil {
+		oldResources := container.command.Resources
+		resources := *oldResources
+		updateCommandResources(&resources, &newConfig)
+		container.command.Resources = &resources
+		if err := daemon.execDriver.Update(container.command); err != nil {
+			container.command.Resources = oldResources
+			return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+		}
+	}
+
+	container.hostConfig = &newConfig
+	if err := container.writeHostConfig(); err != nil {
+		return warnings, derr.ErrorCodeCantUpdate.WithArgs(container.ID, err)
+	}
+
+	container.logEvent("update")
+	return warnings, nil
+}
+
+// mergeResources copies the resource limits which are set in src over
+// the ones of dst.
+func mergeResources(dst, src *runconfig.HostConfig) {
+	if src.BlkioWeight != 0 {
+		dst.BlkioWeight = src.BlkioWeight
+	}
+	if src.CPUShares != 0 {
+		dst.CPUShares = src.CPUShares
+	}
+	if src.CPUPeriod != 0 {
+		dst.CPUPeriod = src.CPUPeriod
+	}
+	if src.CPUQuota != 0 {
+		dst.CPUQuota = src.CPUQuota
+	}
+	if src.CpusetCpus != "" {
+		dst.CpusetCpus = src.CpusetCpus
+	}
+	if src.CpusetMems != "" {
+		dst.CpusetMems = src.CpusetMems
+	}
+	if src.Memory != 0 {
+		dst.Memory = src.Memory
+	}
+	if src.MemorySwap != 0 {
+		dst.MemorySwap = src.MemorySwap
+	}
+	if src.MemoryReservation != 0 {
+		dst.MemoryReservation = src.MemoryReservation
+	}
+	if src.KernelMemory != 0 {
+		dst.KernelMemory = src.KernelMemory
+	}
+}
+
+// updateCommandResources sets the resource limits of hostConfig on the
+// execdriver resources of a running container.
+func updateCommandResources(resources *execdriver.Resources, hostConfig *runconfig.HostConfig) {
+	resources.BlkioWeight = hostConfig.BlkioWeight
+	resources.CPUShares = hostConfig.CPUShares
+	resources.CPUPeriod = hostConfig.CPUPeriod
+	resources.CPUQuota = hostConfig.CPUQuota
+	resources.CpusetCpus = hostConfig.CpusetCpus
+	resources.CpusetMems = hostConfig.CpusetMems
+	resources.Memory = hostConfig.Memory
+	resources.MemorySwap = hostConfig.MemorySwap
+	resources.MemoryReservation = hostConfig.MemoryReservation
+	resources.KernelMemory = hostConfig.KernelMemory
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/types"
@@ -153,3 +154,15 @@ func (daemon *Daemon) registerMountPoints(container *Container, hostConfig *runc
 
 	return nil
 }
+
+// logVolumeMountEvent generates an event for the volume of the mount point
+// m of the container.
+func (container *Container) logVolumeMountEvent(m *volume.MountPoint, action string) {
+	attributes := map[string]string{
+		"driver":      m.Volume.DriverName(),
+		"container":   container.ID,
+		"destination": m.Destination,
+		"read/write":  strconv.FormatBool(m.RW),
+	}
+	container.daemon.LogVolumeEvent(m.Volume.Name(), action, attributes)
+}
@@ -65,6 +65,9 @@ func (container *Container) setupMounts() ([]execdriver.Mount, error) {
 		if err != nil {
 			return nil, err
 		}
+		if m.Volume != nil {
+			container.logVolumeMountEvent(m, "mount")
+		}
 		if !container.trySetNetworkMount(m.Destination, path) {
 			mounts = append(mounts, execdriver.Mount{
 				Source:      path,
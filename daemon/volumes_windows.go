@@ -24,6 +24,9 @@ func (container *Container) setupMounts() ([]execdriver.Mount, error) {
 		if s == "" {
 			return nil, derr.ErrorCodeVolumeNoSourceForMount.WithArgs(mount.Name, mount.Driver, mount.Destination)
 		}
+		if mount.Volume != nil {
+			container.logVolumeMountEvent(mount, "mount")
+		}
 		mnts = append(mnts, execdriver.Mount{
 			Source:      s,
 			Destination: mount.Destination,
@@ -32,6 +32,10 @@ import (
 
 const daemonUsage = "       docker daemon [ --help | ... ]\n"
 
+// defaultDaemonConfigFile is the name of the configuration file of the
+// daemon in its configuration directory.
+const defaultDaemonConfigFile = "daemon.json"
+
 var (
 	flDaemon              = flag.Bool([]string{"#d", "#-daemon"}, false, "Enable daemon mode (deprecated; use docker daemon)")
 	daemonCli cli.Handler = NewDaemonCli()
@@ -79,10 +83,14 @@ func NewDaemonCli() *DaemonCli {
 	registryOptions.InstallFlags(flag.CommandLine, absentFromHelp)
 	daemonFlags.Require(flag.Exact, 0)
 
-	return &DaemonCli{
+	cli := &DaemonCli{
 		Config:          daemonConfig,
 		registryOptions: registryOptions,
 	}
+	defaultConfigFile := filepath.Join(getDaemonConfDir(), defaultDaemonConfigFile)
+	daemonFlags.StringVar(&cli.configFile, []string{"-config-file"}, defaultConfigFile, presentInHelp("Daemon configuration file"))
+	flag.CommandLine.StringVar(&cli.configFile, []string{"-config-file"}, defaultConfigFile, absentFromHelp("Daemon configuration file"))
+	return cli
 }
 
 func migrateKey() (err error) {
@@ -130,6 +138,40 @@ func migrateKey() (err error) {
 type DaemonCli struct {
 	*daemon.Config
 	registryOptions *registry.Options
+	configFile      string
+}
+
+// mergeConfigFile sets the options of the daemon which are not set on the
+// command line to the values of its configuration file. The default
+// configuration file is optional.
+func (cli *DaemonCli) mergeConfigFile() error {
+	options, err := daemon.ReadConfigFile(cli.configFile)
+	if err != nil {
+		if os.IsNotExist(err) && !daemonFlags.IsSet("-config-file") {
+			return nil
+		}
+		return err
+	}
+	return daemon.MergeConfigFile(daemonFlags, options)
+}
+
+// reloadConfig reads the configuration file of the daemon again and applies
+// the options which can be changed while it is running.
+func (cli *DaemonCli) reloadConfig(d *daemon.Daemon) {
+	options, err := daemon.ReadConfigFile(cli.configFile)
+	if err != nil {
+		logrus.Errorf("Error reloading the configuration file %s: %v", cli.configFile, err)
+		return
+	}
+	config, err := daemon.NewReloadConfig(options, daemonFlags)
+	if err == nil {
+		err = d.Reload(config)
+	}
+	if err != nil {
+		logrus.Errorf("Error reloading the configuration file %s: %v", cli.configFile, err)
+		return
+	}
+	logrus.Infof("Reloaded the configuration file %s", cli.configFile)
 }
 
 func getGlobalFlag() (globalFlag *flag.Flag) {
@@ -168,6 +210,10 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	daemonFlags.ParseFlags(args, true)
+	if err := cli.mergeConfigFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to configure the daemon with file %s: %v\n", cli.configFile, err)
+		os.Exit(1)
+	}
 	commonFlags.PostParse()
 
 	if commonFlags.TrustKey == "" {
@@ -205,8 +251,9 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	serverConfig := &apiserver.Config{
-		Logging: true,
-		Version: dockerversion.VERSION,
+		AuthZPluginNames: cli.Config.AuthZPlugins,
+		Logging:          true,
+		Version:          dockerversion.VERSION,
 	}
 	serverConfig = setPlatformServerConfig(serverConfig, cli.Config)
 
@@ -265,6 +312,15 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 	cli.TrustKeyPath = commonFlags.TrustKey
 
+	if err := startMetricsServer(cli.Config.MetricsAddress); err != nil {
+		if pfile != nil {
+			if err := pfile.Remove(); err != nil {
+				logrus.Error(err)
+			}
+		}
+		logrus.Fatalf("Error starting metrics server: %v", err)
+	}
+
 	registryService := registry.NewService(cli.registryOptions)
 	d, err := daemon.NewDaemon(cli.Config, registryService)
 	if err != nil {
@@ -287,6 +343,8 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 
 	api.InitRouters(d)
 
+	cli.setupConfigReloadTrap(d)
+
 	signal.Trap(func() {
 		api.Close()
 		<-serveAPIWait
@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"syscall"
 
 	apiserver "github.com/docker/docker/api/server"
@@ -48,3 +49,15 @@ func setDefaultUmask() error {
 func getDaemonConfDir() string {
 	return "/etc/docker"
 }
+
+// setupConfigReloadTrap reloads the configuration file of the daemon when
+// it receives SIGHUP.
+func (cli *DaemonCli) setupConfigReloadTrap(d *daemon.Daemon) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			cli.reloadConfig(d)
+		}
+	}()
+}
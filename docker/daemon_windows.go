@@ -31,3 +31,7 @@ func getDaemonConfDir() string {
 // notifySystem sends a message to the host when the server is ready to be used
 func notifySystem() {
 }
+
+// setupConfigReloadTrap does nothing on windows, which has no SIGHUP.
+func (cli *DaemonCli) setupConfigReloadTrap(d *daemon.Daemon) {
+}
@@ -0,0 +1,34 @@
+// +build daemon
+
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// startMetricsServer serves the metrics of the daemon in the Prometheus
+// text format on the given address, under /metrics.
+func startMetricsServer(addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler())
+	go func() {
+		logrus.Infof("Serving metrics on %s", l.Addr())
+		if err := http.Serve(l, mux); err != nil {
+			logrus.Errorf("Metrics server error: %v", err)
+		}
+	}()
+	return nil
+}
@@ -922,4 +922,121 @@ var (
 		Description:    "An attempt to create a volume using a driver but the volume already exists with a different driver",
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
+
+	// ErrorCodeCantUpdate is generated when resources of a container
+	// could not be updated.
+	ErrorCodeCantUpdate = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTUPDATE",
+		Message:        "Cannot update container %s: %s",
+		Description:    "There was an error trying to update the resources of the specified container",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeInvalidPruneFilter is generated when a prune request is
+	// given a filter it does not support.
+	ErrorCodeInvalidPruneFilter = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDPRUNEFILTER",
+		Message:        "Invalid filter '%s'",
+		Description:    "The specified filter is not supported when pruning",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeInvalidUntilFilter is generated when the 'until' filter of
+	// a prune request is neither a timestamp nor a duration.
+	ErrorCodeInvalidUntilFilter = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDUNTILFILTER",
+		Message:        "Invalid value for filter 'until': %s",
+		Description:    "The 'until' filter must be a Unix timestamp, a date formatted timestamp or a duration",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeDiskUsageCancelled is generated when the computation of the
+	// disk usage is abandoned, as the client went away.
+	ErrorCodeDiskUsageCancelled = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "DISKUSAGECANCELLED",
+		Message:        "Computation of the disk usage was cancelled",
+		Description:    "The client stopped waiting for the disk usage before it was computed",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeMultipleNetworkConnect is generated when a container is
+	// given the endpoint settings of more than one network on create.
+	ErrorCodeMultipleNetworkConnect = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "MULTIPLENETWORKCONNECT",
+		Message:        "Container cannot be connected to network endpoints: %s",
+		Description:    "A container can only be given the endpoint settings of the network it is created in",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeEndpointNetworkMismatch is generated when a container is
+	// given on create the endpoint settings of a network other than its own.
+	ErrorCodeEndpointNetworkMismatch = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "ENDPOINTNETWORKMISMATCH",
+		Message:        "Endpoint settings given for network %s, but the container is created in network %s",
+		Description:    "A container can only be given the endpoint settings of the network it is created in",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeInvalidEndpointIP is generated when the IP address requested
+	// for the endpoint of a container is not valid.
+	ErrorCodeInvalidEndpointIP = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDENDPOINTIP",
+		Message:        "%s is not a valid IPv%d address",
+		Description:    "The IP address requested for the endpoint of a container is not valid",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeCheckpointNotSupported is generated when the execution
+	// driver cannot checkpoint containers.
+	ErrorCodeCheckpointNotSupported = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTNOTSUPPORTED",
+		Message:        "The %s execution driver does not support checkpoints",
+		Description:    "The execution driver of the daemon cannot checkpoint and restore containers",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeCantCheckpoint is generated when a container cannot be
+	// checkpointed because of its configuration.
+	ErrorCodeCantCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTCHECKPOINT",
+		Message:        "Cannot checkpoint container %s: %s",
+		Description:    "Containers with a TTY or sharing the namespaces of another container cannot be checkpointed",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeInvalidCheckpointName is generated when the name of a
+	// checkpoint is not valid.
+	ErrorCodeInvalidCheckpointName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDCHECKPOINTNAME",
+		Message:        "Invalid checkpoint name (%s), only %s are allowed",
+		Description:    "The name of a checkpoint must be valid as a directory name",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeCheckpointExists is generated when a checkpoint is created
+	// with the name of an existing checkpoint of the container.
+	ErrorCodeCheckpointExists = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CHECKPOINTEXISTS",
+		Message:        "Checkpoint %s already exists for container %s",
+		Description:    "The container already has a checkpoint with this name",
+		HTTPStatusCode: http.StatusConflict,
+	})
+
+	// ErrorCodeNoSuchCheckpoint is generated when a checkpoint is not found.
+	ErrorCodeNoSuchCheckpoint = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOSUCHCHECKPOINT",
+		Message:        "No such checkpoint %s for container %s",
+		Description:    "The container has no checkpoint with this name",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeLiveRestoreTty is generated when a container with a TTY is
+	// created or started while the daemon keeps the containers running
+	// when it exits.
+	ErrorCodeLiveRestoreTty = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "LIVERESTORETTY",
+		Message:        "Containers with a TTY cannot be kept running when the daemon exits, which --live-restore requires",
+		Description:    "The TTY of a container cannot outlive the daemon, so containers with a TTY cannot run on a daemon started with --live-restore",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
 )
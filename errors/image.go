@@ -17,4 +17,47 @@ var (
 		Description:    "The specified image id is incorrectly formatted",
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
+	// ErrorCodeTrustServer is generated when the trust server of a
+	// repository cannot be reached.
+	ErrorCodeTrustServer = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TRUSTSERVER",
+		Message:        "Error contacting the trust server of %s: %v",
+		Description:    "The trust server of the registry of the image could not be reached",
+		HTTPStatusCode: http.StatusBadGateway,
+	})
+
+	// ErrorCodeNoTrustData is generated when a repository has no trust data.
+	ErrorCodeNoTrustData = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "NOTRUSTDATA",
+		Message:        "No trust data for %s",
+		Description:    "The trust server has no trust data for the repository of the image",
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeTrustExpired is generated when the trust data of a repository
+	// has expired.
+	ErrorCodeTrustExpired = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TRUSTEXPIRED",
+		Message:        "Trust data of %s is out-of-date: %v",
+		Description:    "The trust data of the repository of the image has expired",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeTrustVerification is generated when the trust data of a
+	// repository cannot be verified, or does not sign the requested tag.
+	ErrorCodeTrustVerification = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TRUSTVERIFICATION",
+		Message:        "Cannot verify %s: %v",
+		Description:    "The trust data of the repository of the image could not be verified, or does not sign the image",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeUntrustedTag is generated when a container is created from a
+	// tag which was not verified against the trust data of its repository.
+	ErrorCodeUntrustedTag = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "UNTRUSTEDTAG",
+		Message:        "%s is not a trusted tag, pull it from its registry to verify it",
+		Description:    "The content trust policy of the daemon requires the tags of the images of a registry to be verified when they are pulled",
+		HTTPStatusCode: http.StatusForbidden,
+	})
 )
@@ -33,4 +33,31 @@ var (
 		Description:    "Docker's networking stack is disabled for this platform",
 		HTTPStatusCode: http.StatusNotFound,
 	})
+
+	// ErrorCodeAuthorizationDenied is generated when an authorization plugin
+	// denies a request or a response.
+	ErrorCodeAuthorizationDenied = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "AUTHORIZATIONDENIED",
+		Message:        "authorization denied by plugin %s: %s",
+		Description:    "An authorization plugin denied the request",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	// ErrorCodeAuthorizationPluginFailed is generated when an authorization
+	// plugin cannot be reached or returns an error.
+	ErrorCodeAuthorizationPluginFailed = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "AUTHORIZATIONPLUGINFAILED",
+		Message:        "plugin %s failed with error: %s",
+		Description:    "An authorization plugin failed to process the request",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeInvalidCompression is generated when the compression
+	// requested for an export or a save is not supported.
+	ErrorCodeInvalidCompression = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDCOMPRESSION",
+		Message:        "invalid compression %q: the supported compressions are none and gzip",
+		Description:    "The compression requested for an export or a save is not supported",
+		HTTPStatusCode: http.StatusBadRequest,
+	})
 )
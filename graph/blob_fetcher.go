@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/registry/api/v2"
+	"golang.org/x/net/context"
+)
+
+// blobFetcher fetches the blobs of a repository of a v2 registry from a given
+// offset, so that an interrupted download can be resumed.
+type blobFetcher struct {
+	client *http.Client
+	urls   *v2.URLBuilder
+	name   string
+}
+
+// newBlobFetcher returns a fetcher of the blobs of the repository name of the
+// registry at baseURL, using an HTTP client authenticated to the registry.
+func newBlobFetcher(client *http.Client, baseURL, name string) (*blobFetcher, error) {
+	urls, err := v2.NewURLBuilderFromString(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &blobFetcher{client: client, urls: urls, name: name}, nil
+}
+
+// open returns the content of a blob from offset. A range request is only
+// sent if useRange is true; otherwise, the first offset bytes of the blob are
+// downloaded again and discarded. open also returns whether the registry
+// advertises the support of range requests for the blob.
+func (f *blobFetcher) open(ctx context.Context, dgst digest.Digest, offset int64, useRange bool) (io.ReadCloser, bool, error) {
+	blobURL, err := f.urls.BuildBlobURL(f.name, dgst)
+	if err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequest("GET", blobURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Cancel = ctx.Done()
+	if offset > 0 && useRange {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	rangeSupported := resp.Header.Get("Accept-Ranges") == "bytes"
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err == nil && start == offset && useRange {
+			return resp.Body, rangeSupported, nil
+		}
+	case http.StatusOK:
+		// The registry ignored the range, if any
+		if offset > 0 {
+			if _, err := io.CopyN(ioutil.Discard, resp.Body, offset); err != nil {
+				resp.Body.Close()
+				return nil, rangeSupported, err
+			}
+		}
+		return resp.Body, rangeSupported, nil
+	}
+	resp.Body.Close()
+	return nil, rangeSupported, fmt.Errorf("error fetching blob %s: unexpected status %s", dgst, resp.Status)
+}
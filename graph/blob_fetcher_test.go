@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/streamformatter"
+	"golang.org/x/net/context"
+)
+
+// interruptedBlobServer serves a blob, dropping the connection in the
+// middle of the first response.
+type interruptedBlobServer struct {
+	blob   []byte
+	ranges bool
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *interruptedBlobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Header.Get("Range"))
+	first := len(s.requests) == 1
+	s.mu.Unlock()
+
+	if first {
+		w.Header().Set("Content-Length", strconv.Itoa(len(s.blob)))
+		if s.ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		w.Write(s.blob[:len(s.blob)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	if s.ranges {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.blob))
+		return
+	}
+	w.Write(s.blob)
+}
+
+func TestFetchBlobResume(t *testing.T) {
+	blob := bytes.Repeat([]byte("layer data "), 100000)
+	dgst, err := digest.FromBytes(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ranges := range []bool{true, false} {
+		s := &interruptedBlobServer{blob: blob, ranges: ranges}
+		server := httptest.NewServer(s)
+		fetcher, err := newBlobFetcher(http.DefaultClient, server.URL, "test/image")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &v2Puller{
+			TagStore: &TagStore{maxDownloadAttempts: 3, downloadRetryDelay: time.Millisecond},
+			sf:       streamformatter.NewJSONStreamFormatter(),
+			blobs:    fetcher,
+		}
+
+		var progress, out bytes.Buffer
+		verifier, err := digest.NewDigestVerifier(dgst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.fetchBlob(context.Background(), &progress, dgst, "layer", int64(len(blob)), &out); err != nil {
+			t.Fatal(err)
+		}
+		server.Close()
+		verifier.Write(out.Bytes())
+		if !verifier.Verified() {
+			t.Fatalf("Expected the blob to be verified over the resumed download (ranges: %v)", ranges)
+		}
+
+		if len(s.requests) != 2 {
+			t.Fatalf("Expected a single retry, got the requests %v", s.requests)
+		}
+		expectedRange := ""
+		expectedStatus := "Retrying in"
+		if ranges {
+			expectedRange = "bytes=" + strconv.Itoa(len(blob)/2) + "-"
+			expectedStatus = "Resuming from"
+		}
+		if s.requests[1] != expectedRange {
+			t.Fatalf("Expected the range %q for the retry, got %q", expectedRange, s.requests[1])
+		}
+		if !bytes.Contains(progress.Bytes(), []byte(expectedStatus)) {
+			t.Fatalf("Expected the retry to be reported, got %s", progress.String())
+		}
+	}
+}
+
+func TestFetchBlobMaxAttempts(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher, err := newBlobFetcher(http.DefaultClient, server.URL, "test/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &v2Puller{
+		TagStore: &TagStore{maxDownloadAttempts: 3, downloadRetryDelay: time.Millisecond},
+		sf:       streamformatter.NewJSONStreamFormatter(),
+		blobs:    fetcher,
+	}
+	var progress, out bytes.Buffer
+	if err := p.fetchBlob(context.Background(), &progress, digest.Digest("sha256:"+string(bytes.Repeat([]byte("a"), 64))), "layer", 10, &out); err == nil {
+		t.Fatal("Expected the download to fail")
+	}
+	if requests != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", requests)
+	}
+}
@@ -1,33 +1,52 @@
 package graph
 
 import (
+	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
-	"path/filepath"
+	"path"
+	"runtime"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/registry"
 )
 
 // ImageExport exports list of images to a output stream specified in the
-// config. The exported images are archived into a tar when written to the
-// output stream. All images with the given tag and all versions containing the
-// same tag are exported. names is the set of tags to export, and outStream
-// is the writer which the images are written to.
-func (s *TagStore) ImageExport(names []string, outStream io.Writer) error {
-	// get image json
-	tempdir, err := ioutil.TempDir("", "docker-export-")
-	if err != nil {
-		return err
+// config. The exported images are archived into a tar, compressed with
+// compression, which is streamed to the output stream as the layers are
+// read. All images with the given tag and all versions containing the same
+// tag are exported. names is the set of tags to export, and outStream is the
+// writer which the images are written to.
+func (s *TagStore) ImageExport(names []string, compression archive.Compression, outStream io.Writer) error {
+	var (
+		images   []*image.Image
+		exported = map[string]bool{}
+	)
+	// addImage adds the image with the given name and its parents to the
+	// images to export
+	addImage := func(name string) error {
+		for n := name; n != "" && !exported[n]; {
+			img, err := s.LookupImage(n)
+			if err != nil || img == nil {
+				return fmt.Errorf("No such image %s", n)
+			}
+			exported[n] = true
+			images = append(images, img)
+			// try again with parent
+			n = img.Parent
+		}
+		return nil
 	}
-	defer os.RemoveAll(tempdir)
 
 	rootRepoMap := map[string]Repository{}
 	addKey := func(name string, tag string, id string) {
@@ -46,7 +65,7 @@ func (s *TagStore) ImageExport(names []string, outStream io.Writer) error {
 			// this is a base repo name, like 'busybox'
 			for tag, id := range rootRepo {
 				addKey(name, tag, id)
-				if err := s.exportImage(id, tempdir); err != nil {
+				if err := addImage(id); err != nil {
 					return err
 				}
 			}
@@ -70,111 +89,161 @@ func (s *TagStore) ImageExport(names []string, outStream io.Writer) error {
 				if len(repoTag) > 0 {
 					addKey(repoName, repoTag, img.ID)
 				}
-				if err := s.exportImage(img.ID, tempdir); err != nil {
+				if err := addImage(img.ID); err != nil {
 					return err
 				}
 
 			} else {
 				// this must be an ID that didn't get looked up just right?
-				if err := s.exportImage(name, tempdir); err != nil {
+				if err := addImage(name); err != nil {
 					return err
 				}
 			}
 		}
 		logrus.Debugf("End Serializing %s", name)
 	}
+
+	compressed, err := archive.CompressStream(ioutils.NopWriteCloser(outStream), compression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(compressed)
+
+	for _, img := range images {
+		if err := s.exportImage(img, tw); err != nil {
+			return err
+		}
+	}
+
 	// write repositories, if there is something to write
 	if len(rootRepoMap) > 0 {
-		f, err := os.OpenFile(filepath.Join(tempdir, "repositories"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		reposJSON, err := json.Marshal(rootRepoMap)
 		if err != nil {
-			f.Close()
-			return err
-		}
-		if err := json.NewEncoder(f).Encode(rootRepoMap); err != nil {
 			return err
 		}
-		if err := f.Close(); err != nil {
-			return err
-		}
-		if err := os.Chtimes(filepath.Join(tempdir, "repositories"), time.Unix(0, 0), time.Unix(0, 0)); err != nil {
+		if err := writeTarFile(tw, "repositories", time.Unix(0, 0), reposJSON); err != nil {
 			return err
 		}
 	} else {
 		logrus.Debugf("There were no repositories to write")
 	}
 
-	fs, err := archive.Tar(tempdir, archive.Uncompressed)
-	if err != nil {
+	if err := tw.Close(); err != nil {
 		return err
 	}
-	defer fs.Close()
-
-	if _, err := io.Copy(outStream, fs); err != nil {
+	if err := compressed.Close(); err != nil {
 		return err
 	}
 	logrus.Debugf("End export image")
 	return nil
 }
 
-func (s *TagStore) exportImage(name, tempdir string) error {
-	for n := name; n != ""; {
-		img, err := s.LookupImage(n)
-		if err != nil || img == nil {
-			return fmt.Errorf("No such image %s", n)
-		}
-
-		// temporary directory
-		tmpImageDir := filepath.Join(tempdir, n)
-		if err := os.Mkdir(tmpImageDir, os.FileMode(0755)); err != nil {
-			if os.IsExist(err) {
-				return nil
-			}
-			return err
-		}
+// exportImage writes the directory of an image to the tar archive: its
+// VERSION, its json and its layer.tar, which is streamed from the graph.
+func (s *TagStore) exportImage(img *image.Image, tw *tar.Writer) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     img.ID + "/",
+		Mode:     0755,
+		Typeflag: tar.TypeDir,
+		ModTime:  img.Created,
+	}); err != nil {
+		return err
+	}
 
-		var version = "1.0"
-		var versionBuf = []byte(version)
+	if err := writeTarFile(tw, path.Join(img.ID, "VERSION"), img.Created, []byte("1.0")); err != nil {
+		return err
+	}
 
-		if err := ioutil.WriteFile(filepath.Join(tmpImageDir, "VERSION"), versionBuf, os.FileMode(0644)); err != nil {
-			return err
-		}
+	imageInspectRaw, err := json.Marshal(img)
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, path.Join(img.ID, "json"), img.Created, imageInspectRaw); err != nil {
+		return err
+	}
 
-		imageInspectRaw, err := json.Marshal(img)
-		if err != nil {
-			return err
-		}
+	// serialize filesystem
+	layer, size, err := s.tarLayer(img)
+	if err != nil {
+		return err
+	}
+	defer layer.Close()
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     path.Join(img.ID, "layer.tar"),
+		Mode:     0644,
+		Size:     size,
+		Typeflag: tar.TypeReg,
+		ModTime:  img.Created,
+	}); err != nil {
+		return err
+	}
+	written, err := io.Copy(tw, layer)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("rendered layer for %s of [%d] size", img.ID, written)
+	return nil
+}
 
-		// serialize json
-		json, err := os.Create(filepath.Join(tmpImageDir, "json"))
-		if err != nil {
-			return err
-		}
+// tarLayer returns the tar archive of the layer of an image, along with its
+// size, which is needed before the archive can be written. The size is
+// computed from the metadata stored along with the layer. The layers stored
+// without this metadata are first copied to a temporary file to get their
+// size.
+func (s *TagStore) tarLayer(img *image.Image) (io.ReadCloser, int64, error) {
+	// On Windows, the base layer cannot be exported
+	if runtime.GOOS == "windows" && img.Parent == "" {
+		return ioutil.NopCloser(bytes.NewReader(nil)), 0, nil
+	}
 
-		written, err := json.Write(imageInspectRaw)
+	if size, err := s.graph.TarLayerSize(img); err == nil {
+		layer, err := s.graph.TarLayer(img)
 		if err != nil {
-			return err
-		}
-		if written != len(imageInspectRaw) {
-			logrus.Warnf("%d byes should have been written instead %d have been written", written, len(imageInspectRaw))
+			return nil, 0, err
 		}
+		return layer, size, nil
+	}
 
-		// serialize filesystem
-		fsTar, err := os.Create(filepath.Join(tmpImageDir, "layer.tar"))
-		if err != nil {
-			return err
-		}
-		if err := s.ImageTarLayer(n, fsTar); err != nil {
-			return err
-		}
+	tmpFile, err := ioutil.TempFile("", "docker-export-")
+	if err != nil {
+		return nil, 0, err
+	}
+	removeTmpFile := func() error {
+		tmpFile.Close()
+		return os.Remove(tmpFile.Name())
+	}
+	size, err := s.copyTarLayer(img, tmpFile)
+	if err == nil {
+		_, err = tmpFile.Seek(0, 0)
+	}
+	if err != nil {
+		removeTmpFile()
+		return nil, 0, err
+	}
+	return ioutils.NewReadCloserWrapper(tmpFile, removeTmpFile), size, nil
+}
 
-		for _, fname := range []string{"", "VERSION", "json", "layer.tar"} {
-			if err := os.Chtimes(filepath.Join(tmpImageDir, fname), img.Created, img.Created); err != nil {
-				return err
-			}
-		}
+func (s *TagStore) copyTarLayer(img *image.Image, dest io.Writer) (int64, error) {
+	layer, err := s.graph.TarLayer(img)
+	if err != nil {
+		return 0, err
+	}
+	defer layer.Close()
+	return io.Copy(dest, layer)
+}
 
-		// try again with parent
-		n = img.Parent
+// writeTarFile writes a regular file with the given content to the tar
+// archive.
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, content []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		Typeflag: tar.TypeReg,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
 	}
-	return nil
+	_, err := tw.Write(content)
+	return err
 }
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/utils"
+)
+
+func TestImageExport(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+	defer store.graph.driver.Cleanup()
+
+	img, err := store.LookupImage(testOfficialImageName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := store.graph.TarLayer(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ioutil.ReadAll(layer)
+	layer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := store.graph.TarLayerSize(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(expected)) {
+		t.Fatalf("Expected the size of the layer to be %d, got %d", len(expected), size)
+	}
+
+	assertExport := func(expected []byte) {
+		buf := new(bytes.Buffer)
+		if err := store.ImageExport([]string{testOfficialImageName}, archive.Gzip, buf); err != nil {
+			t.Fatal(err)
+		}
+		decompressed, err := archive.DecompressStream(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer decompressed.Close()
+
+		files := map[string][]byte{}
+		tr := tar.NewReader(decompressed)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[hdr.Name] = content
+		}
+
+		for _, name := range []string{testOfficialImageID + "/", testOfficialImageID + "/VERSION", testOfficialImageID + "/json", "repositories"} {
+			if _, ok := files[name]; !ok {
+				t.Fatalf("Expected %s in the archive, got %v", name, files)
+			}
+		}
+		if !bytes.Equal(files[testOfficialImageID+"/layer.tar"], expected) {
+			t.Fatalf("Expected the layer to be exported as it is stored, got %d bytes instead of %d", len(files[testOfficialImageID+"/layer.tar"]), len(expected))
+		}
+	}
+
+	assertExport(expected)
+
+	// Layers stored without the tar-split metadata are exported too
+	if err := os.Remove(filepath.Join(store.graph.imageRoot(img.ID), tarDataFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.graph.TarLayerSize(img); err == nil {
+		t.Fatal("Expected an error for the size of a layer without metadata")
+	}
+	layer, err = store.graph.TarLayer(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err = ioutil.ReadAll(layer)
+	layer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertExport(expected)
+}
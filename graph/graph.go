@@ -354,7 +354,7 @@ func (graph *Graph) register(im image.Descriptor, layerData io.Reader) (err erro
 }
 
 func createRootFilesystemInDriver(graph *Graph, id, parent string) error {
-	if err := graph.driver.Create(id, parent); err != nil {
+	if err := graph.driver.Create(id, parent, nil); err != nil {
 		return fmt.Errorf("Driver %s failed to create image rootfs %s: %s", graph.driver, id, err)
 	}
 	return nil
@@ -523,6 +523,41 @@ func (graph *Graph) TarLayer(img *image.Image) (arch io.ReadCloser, err error) {
 	return rdr, nil
 }
 
+// TarLayerSize returns the size of the tar archive of the image's filesystem
+// layer returned by TarLayer, computed from the metadata stored along with
+// the layer. An error is returned if the layer has no such metadata.
+func (graph *Graph) TarLayerSize(img *image.Image) (int64, error) {
+	mf, err := os.Open(filepath.Join(graph.imageRoot(img.ID), tarDataFileName))
+	if err != nil {
+		return 0, err
+	}
+	defer mf.Close()
+	mfz, err := gzip.NewReader(mf)
+	if err != nil {
+		return 0, err
+	}
+	defer mfz.Close()
+
+	// The archive is made of the raw segments and of the content of the files
+	var size int64
+	metaUnpacker := storage.NewJSONUnpacker(mfz)
+	for {
+		entry, err := metaUnpacker.Next()
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch entry.Type {
+		case storage.SegmentType:
+			size += int64(len(entry.Payload))
+		case storage.FileType:
+			size += entry.Size
+		}
+	}
+}
+
 func (graph *Graph) imageRoot(id string) string {
 	return filepath.Join(graph.root, id)
 }
@@ -87,6 +87,25 @@ func (s *TagStore) History(name string) ([]*types.ImageHistory, error) {
 	history := []*types.ImageHistory{}
 
 	err = s.graph.WalkHistory(foundImage, func(img image.Image) error {
+		if len(img.History) > 0 {
+			// The image is squashed: list the images squashed into it, the
+			// most recent one standing for the image itself
+			for i := len(img.History) - 1; i >= 0; i-- {
+				h := &types.ImageHistory{
+					ID:        "<missing>",
+					Created:   img.History[i].Created.Unix(),
+					CreatedBy: img.History[i].CreatedBy,
+					Comment:   img.History[i].Comment,
+				}
+				if i == len(img.History)-1 {
+					h.ID = img.ID
+					h.Tags = lookupMap[img.ID]
+					h.Size = img.Size
+				}
+				history = append(history, h)
+			}
+			return nil
+		}
 		history = append(history, &types.ImageHistory{
 			ID:        img.ID,
 			Created:   img.Created.Unix(),
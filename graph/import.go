@@ -74,6 +74,6 @@ func (s *TagStore) Import(src string, repo string, tag string, msg string, inCon
 		logID = utils.ImageReference(logID, tag)
 	}
 
-	s.eventsService.Log("import", logID, "")
+	s.logImageEvent(logID, "import")
 	return nil
 }
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	pullDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "engine",
+		Subsystem: "image",
+		Name:      "pull_seconds",
+		Help:      "The number of seconds taken by the successful image pulls",
+	})
+	pushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "engine",
+		Subsystem: "image",
+		Name:      "push_seconds",
+		Help:      "The number of seconds taken by the successful image pushes",
+	})
+	pulledBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "engine",
+		Subsystem: "image",
+		Name:      "pull_bytes_total",
+		Help:      "The number of bytes of the layers downloaded from the registries",
+	})
+	pushedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "engine",
+		Subsystem: "image",
+		Name:      "push_bytes_total",
+		Help:      "The number of bytes of the layers uploaded to the registries",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(pullDuration, pushDuration, pulledBytes, pushedBytes)
+}
+
+// meteredReader counts the bytes read through it.
+type meteredReader struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+func newMeteredReader(rc io.ReadCloser, counter prometheus.Counter) io.ReadCloser {
+	return &meteredReader{ReadCloser: rc, counter: counter}
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.counter.Add(float64(n))
+	}
+	return n, err
+}
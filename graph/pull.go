@@ -1,8 +1,10 @@
 package graph
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/cliconfig"
@@ -11,6 +13,10 @@ import (
 	"github.com/docker/docker/utils"
 )
 
+// errClientDisconnected is returned by the pulls and pushes whose client
+// disconnected.
+var errClientDisconnected = errors.New("client disconnected")
+
 // ImagePullConfig stores pull configuration.
 type ImagePullConfig struct {
 	// MetaHeaders stores HTTP headers with metadata about the image
@@ -22,6 +28,9 @@ type ImagePullConfig struct {
 	// OutStream is the output writer for showing the status of the pull
 	// operation.
 	OutStream io.Writer
+	// Stop is signaled when the client of the pull disconnects. The layer
+	// downloads are cancelled unless other pulls are waiting for them.
+	Stop <-chan bool
 }
 
 // Puller is an interface that abstracts pulling for different API versions.
@@ -76,6 +85,18 @@ func (s *TagStore) Pull(image string, tag string, imagePullConfig *ImagePullConf
 		return err
 	}
 
+	// The tags of the registries of the trust policy are pulled by the
+	// digests signed in their trust data
+	if server, ok := s.trustPolicy.server(repoInfo.Index); ok && !utils.DigestReference(tag) {
+		return s.pullTrusted(image, repoInfo, tag, server, imagePullConfig, sf)
+	}
+	return s.pullFromEndpoints(image, repoInfo, tag, imagePullConfig, sf)
+}
+
+// pullFromEndpoints pulls a tag, or a digest, of a repository from the first
+// endpoint of its registry which has it.
+func (s *TagStore) pullFromEndpoints(image string, repoInfo *registry.RepositoryInfo, tag string, imagePullConfig *ImagePullConfig, sf *streamformatter.StreamFormatter) error {
+	start := time.Now()
 	endpoints, err := s.registryService.LookupPullEndpoints(repoInfo.CanonicalName)
 	if err != nil {
 		return err
@@ -98,7 +119,7 @@ func (s *TagStore) Pull(image string, tag string, imagePullConfig *ImagePullConf
 		// error is the ones from v2 endpoints not v1.
 		discardNoSupportErrors bool
 	)
-	for _, endpoint := range endpoints {
+	for i, endpoint := range endpoints {
 		logrus.Debugf("Trying to pull %s from %s %s", repoInfo.LocalName, endpoint.URL, endpoint.Version)
 
 		puller, err := NewPuller(s, endpoint, repoInfo, imagePullConfig, sf)
@@ -113,6 +134,9 @@ func (s *TagStore) Pull(image string, tag string, imagePullConfig *ImagePullConf
 					discardNoSupportErrors = true
 					// save the current error
 					lastErr = err
+					if i < len(endpoints)-1 {
+						imagePullConfig.OutStream.Write(sf.FormatStatus("", "Error pulling from %s, trying the next endpoint: %v", endpoint.URL, err))
+					}
 				} else if !discardNoSupportErrors {
 					// Save the ErrNoSupport error, because it's either the first error or all encountered errors
 					// were also ErrNoSupport errors.
@@ -125,7 +149,11 @@ func (s *TagStore) Pull(image string, tag string, imagePullConfig *ImagePullConf
 
 		}
 
-		s.eventsService.Log("pull", logName, "")
+		if endpoint.Mirror {
+			imagePullConfig.OutStream.Write(sf.FormatStatus("", "Pulled from mirror %s", endpoint.URL))
+		}
+		pullDuration.Observe(time.Since(start).Seconds())
+		s.logImageEvent(logName, "pull")
 		return nil
 	}
 
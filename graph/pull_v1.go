@@ -324,7 +324,7 @@ func (p *v1Puller) pullImage(out io.Writer, imgID, endpoint string) (layersDownl
 
 				err = p.graph.Register(v1Descriptor{img},
 					progressreader.New(progressreader.Config{
-						In:        layer,
+						In:        newMeteredReader(layer, pulledBytes),
 						Out:       broadcaster,
 						Formatter: p.sf,
 						Size:      imgSize,
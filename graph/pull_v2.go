@@ -6,17 +6,20 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/digest"
 	"github.com/docker/distribution/manifest"
 	"github.com/docker/docker/image"
-	"github.com/docker/docker/pkg/broadcaster"
 	"github.com/docker/docker/pkg/progressreader"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/transfer"
+	"github.com/docker/docker/pkg/units"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/utils"
 	"golang.org/x/net/context"
@@ -29,21 +32,28 @@ type v2Puller struct {
 	sf        *streamformatter.StreamFormatter
 	repoInfo  *registry.RepositoryInfo
 	repo      distribution.Repository
+	blobs     *blobFetcher
 	sessionID string
 }
 
 func (p *v2Puller) Pull(tag string) (fallback bool, err error) {
 	// TODO(tiborvass): was ReceiveTimeout
-	p.repo, err = NewV2Repository(p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
+	var client *http.Client
+	p.repo, client, err = newV2Repository(p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
 	if err != nil {
 		logrus.Warnf("Error getting v2 registry: %v", err)
 		return true, err
 	}
+	p.blobs, err = newBlobFetcher(client, p.endpoint.URL, p.repo.Name())
+	if err != nil {
+		return false, err
+	}
 
 	p.sessionID = stringid.GenerateRandomID()
 
 	if err := p.pullV2Repository(tag); err != nil {
-		if registry.ContinueOnError(err) {
+		// The registry is tried next if a mirror fails
+		if p.endpoint.Mirror || registry.ContinueOnError(err) {
 			logrus.Debugf("Error trying v2 registry: %v", err)
 			return true, err
 		}
@@ -105,15 +115,21 @@ func (p *v2Puller) pullV2Repository(tag string) (err error) {
 
 // downloadInfo is used to pass information from download to extractor
 type downloadInfo struct {
-	img         contentAddressableDescriptor
-	imgIndex    int
-	tmpFile     *os.File
-	digest      digest.Digest
-	layer       distribution.ReadSeekCloser
-	size        int64
-	err         chan error
-	poolKey     string
-	broadcaster *broadcaster.Buffered
+	img      contentAddressableDescriptor
+	imgIndex int
+	digest   digest.Digest
+	watcher  *transfer.Watcher
+}
+
+// downloadedLayer is a layer downloaded to a temporary file. The file is
+// removed once all the pulls sharing the download released it.
+type downloadedLayer struct {
+	path string
+	size int64
+}
+
+func (l *downloadedLayer) Close() error {
+	return os.RemoveAll(l.path)
 }
 
 // contentAddressableDescriptor is used to pass image data from a manifest to the
@@ -174,59 +190,112 @@ type errVerification struct{}
 
 func (errVerification) Error() string { return "verification failed" }
 
-func (p *v2Puller) download(di *downloadInfo) {
-	logrus.Debugf("pulling blob %q to %s", di.digest, di.img.id)
+// download returns the transfer downloading a layer to a temporary file. The
+// download is shared by all the pulls of the layer, and its progress is
+// reported with the ID of the image of the first one.
+func (p *v2Puller) download(dgst digest.Digest, id string) transfer.DoFunc {
+	return func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		logrus.Debugf("pulling blob %q to %s", dgst, id)
 
-	blobs := p.repo.Blobs(context.Background())
+		desc, err := p.repo.Blobs(ctx).Stat(ctx, dgst)
+		if err != nil {
+			logrus.Debugf("Error statting layer: %v", err)
+			return nil, err
+		}
 
-	desc, err := blobs.Stat(context.Background(), di.digest)
-	if err != nil {
-		logrus.Debugf("Error statting layer: %v", err)
-		di.err <- err
-		return
-	}
-	di.size = desc.Size
+		verifier, err := digest.NewDigestVerifier(dgst)
+		if err != nil {
+			return nil, err
+		}
 
-	layerDownload, err := blobs.Open(context.Background(), di.digest)
-	if err != nil {
-		logrus.Debugf("Error fetching layer: %v", err)
-		di.err <- err
-		return
-	}
-	defer layerDownload.Close()
+		tmpFile, err := ioutil.TempFile("", "GetImageBlob")
+		if err != nil {
+			return nil, err
+		}
+		layer := &downloadedLayer{path: tmpFile.Name(), size: desc.Size}
 
-	verifier, err := digest.NewDigestVerifier(di.digest)
-	if err != nil {
-		di.err <- err
-		return
-	}
+		err = p.fetchBlob(ctx, progress, dgst, id, desc.Size, io.MultiWriter(tmpFile, verifier))
+		tmpFile.Close()
+		if err != nil {
+			layer.Close()
+			return nil, err
+		}
 
-	reader := progressreader.New(progressreader.Config{
-		In:        ioutil.NopCloser(io.TeeReader(layerDownload, verifier)),
-		Out:       di.broadcaster,
-		Formatter: p.sf,
-		Size:      di.size,
-		NewLines:  false,
-		ID:        stringid.TruncateID(di.img.id),
-		Action:    "Downloading",
-	})
-	io.Copy(di.tmpFile, reader)
+		progress.Write(p.sf.FormatProgress(stringid.TruncateID(id), "Verifying Checksum", nil))
+
+		if !verifier.Verified() {
+			layer.Close()
+			err = fmt.Errorf("filesystem layer verification failed for digest %s", dgst)
+			logrus.Error(err)
+			return nil, err
+		}
 
-	di.broadcaster.Write(p.sf.FormatProgress(stringid.TruncateID(di.img.id), "Verifying Checksum", nil))
+		progress.Write(p.sf.FormatProgress(stringid.TruncateID(id), "Download complete", nil))
 
-	if !verifier.Verified() {
-		err = fmt.Errorf("filesystem layer verification failed for digest %s", di.digest)
-		logrus.Error(err)
-		di.err <- err
-		return
+		logrus.Debugf("Downloaded %s to tempfile %s", id, layer.path)
+		return layer, nil
 	}
+}
 
-	di.broadcaster.Write(p.sf.FormatProgress(stringid.TruncateID(di.img.id), "Download complete", nil))
+// fetchBlob downloads a blob to w. A failed download is retried after a
+// delay doubled at each attempt. It resumes from the last received offset if
+// the registry supports range requests, so that the digest of the blob is
+// verified over the content received by all the attempts.
+func (p *v2Puller) fetchBlob(ctx context.Context, progress io.Writer, dgst digest.Digest, id string, size int64, w io.Writer) error {
+	var (
+		offset         int64
+		rangeSupported bool
+	)
+	delay := p.downloadRetryDelay
+	for attempt := 1; ; attempt++ {
+		body, ranges, err := p.blobs.open(ctx, dgst, offset, rangeSupported)
+		if err == nil {
+			if offset == 0 {
+				rangeSupported = ranges
+			}
+			reader := progressreader.New(progressreader.Config{
+				In:         newMeteredReader(body, pulledBytes),
+				Out:        progress,
+				Formatter:  p.sf,
+				Size:       size,
+				Current:    offset,
+				LastUpdate: offset,
+				NewLines:   false,
+				ID:         stringid.TruncateID(id),
+				Action:     "Downloading",
+			})
+			var n int64
+			n, err = io.Copy(w, reader)
+			body.Close()
+			offset += n
+			if err == nil && offset < size {
+				err = io.ErrUnexpectedEOF
+			}
+			if err == nil {
+				return nil
+			}
+		}
 
-	logrus.Debugf("Downloaded %s to tempfile %s", di.img.id, di.tmpFile.Name())
-	di.layer = layerDownload
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= p.maxDownloadAttempts {
+			return err
+		}
+		logrus.Debugf("Error downloading blob %s at offset %d (attempt %d of %d): %v", dgst, offset, attempt, p.maxDownloadAttempts, err)
+		status := fmt.Sprintf("Retrying in %s", delay)
+		if offset > 0 && rangeSupported {
+			status = fmt.Sprintf("Resuming from %s in %s", units.HumanSize(float64(offset)), delay)
+		}
+		progress.Write(p.sf.FormatProgress(stringid.TruncateID(id), status, nil))
 
-	di.err <- nil
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
 }
 
 func (p *v2Puller) pullV2Tag(out io.Writer, tag, taggedName string) (tagUpdated bool, err error) {
@@ -270,13 +339,7 @@ func (p *v2Puller) pullV2Tag(out io.Writer, tag, taggedName string) (tagUpdated
 		p.graph.Release(p.sessionID, layerIDs...)
 
 		for _, d := range downloads {
-			p.poolRemoveWithError("pull", d.poolKey, err)
-			if d.tmpFile != nil {
-				d.tmpFile.Close()
-				if err := os.RemoveAll(d.tmpFile.Name()); err != nil {
-					logrus.Errorf("Failed to remove temp file: %s", d.tmpFile.Name())
-				}
-			}
+			d.watcher.Release()
 		}
 	}()
 
@@ -302,57 +365,41 @@ func (p *v2Puller) pullV2Tag(out io.Writer, tag, taggedName string) (tagUpdated
 
 		out.Write(p.sf.FormatProgress(stringid.TruncateID(img.id), "Pulling fs layer", nil))
 
-		d := &downloadInfo{
+		dgst := verifiedManifest.FSLayers[i].BlobSum
+		// The layers are downloaded concurrently, and a download is
+		// shared by all the pulls of the same layer.
+		downloads = append(downloads, &downloadInfo{
 			img:      img,
 			imgIndex: i,
-			poolKey:  "v2layer:" + img.id,
-			digest:   verifiedManifest.FSLayers[i].BlobSum,
-			// TODO: seems like this chan buffer solved hanging problem in go1.5,
-			// this can indicate some deeper problem that somehow we never take
-			// error from channel in loop below
-			err: make(chan error, 1),
-		}
-
-		tmpFile, err := ioutil.TempFile("", "GetImageBlob")
-		if err != nil {
-			return false, err
-		}
-		d.tmpFile = tmpFile
-
-		downloads = append(downloads, d)
-
-		broadcaster, found := p.poolAdd("pull", d.poolKey)
-		broadcaster.Add(out)
-		d.broadcaster = broadcaster
-		if found {
-			d.err <- nil
-		} else {
-			go p.download(d)
-		}
+			digest:   dgst,
+			watcher:  p.downloadManager.Transfer("download:"+dgst.String(), p.download(dgst, img.id), out),
+		})
 	}
 
 	for _, d := range downloads {
-		if err := <-d.err; err != nil {
+		select {
+		case <-d.watcher.Done():
+		case <-p.config.Stop:
+			return false, errClientDisconnected
+		}
+		result, err := d.watcher.Result()
+		if err != nil {
 			return false, err
 		}
+		layer := result.(*downloadedLayer)
 
-		if d.layer == nil {
-			// Wait for a different pull to download and extract
-			// this layer.
-			err = d.broadcaster.Wait()
+		err = func() error {
+			layerFile, err := os.Open(layer.path)
 			if err != nil {
-				return false, err
+				return err
 			}
-			continue
-		}
+			defer layerFile.Close()
 
-		d.tmpFile.Seek(0, 0)
-		err := func() error {
 			reader := progressreader.New(progressreader.Config{
-				In:        d.tmpFile,
-				Out:       d.broadcaster,
+				In:        layerFile,
+				Out:       out,
 				Formatter: p.sf,
-				Size:      d.size,
+				Size:      layer.size,
 				NewLines:  false,
 				ID:        stringid.TruncateID(d.img.id),
 				Action:    "Extracting",
@@ -392,8 +439,7 @@ func (p *v2Puller) pullV2Tag(out io.Writer, tag, taggedName string) (tagUpdated
 			return false, err
 		}
 
-		d.broadcaster.Write(p.sf.FormatProgress(stringid.TruncateID(d.img.id), "Pull complete", nil))
-		d.broadcaster.Close()
+		out.Write(p.sf.FormatProgress(stringid.TruncateID(d.img.id), "Pull complete", nil))
 		tagUpdated = true
 	}
 
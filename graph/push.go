@@ -3,6 +3,7 @@ package graph
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
@@ -25,6 +26,9 @@ type ImagePushConfig struct {
 	// OutStream is the output writer for showing the status of the push
 	// operation.
 	OutStream io.Writer
+	// Stop is signaled when the client of the push disconnects. The layer
+	// uploads are cancelled unless other pushes are waiting for them.
+	Stop <-chan bool
 }
 
 // Pusher is an interface that abstracts pushing for different API versions.
@@ -71,6 +75,7 @@ func (s *TagStore) Push(localName string, imagePushConfig *ImagePushConfig) erro
 	// FIXME: Allow to interrupt current push when new push of same image is done.
 
 	var sf = streamformatter.NewJSONStreamFormatter()
+	start := time.Now()
 
 	// Resolve the Repository name from fqn to RepositoryInfo
 	repoInfo, err := s.registryService.ResolveRepository(localName)
@@ -115,7 +120,8 @@ func (s *TagStore) Push(localName string, imagePushConfig *ImagePushConfig) erro
 
 		}
 
-		s.eventsService.Log("push", repoInfo.LocalName, "")
+		pushDuration.Observe(time.Since(start).Seconds())
+		s.logImageEvent(repoInfo.LocalName, "push")
 		return nil
 	}
 
@@ -307,7 +307,7 @@ func (p *v1Pusher) pushImage(imgID, ep string) (checksum string, err error) {
 
 	checksum, checksumPayload, err := p.session.PushImageLayerRegistry(imgData.ID,
 		progressreader.New(progressreader.Config{
-			In:        layerData,
+			In:        newMeteredReader(layerData, pushedBytes),
 			Out:       p.out,
 			Formatter: p.sf,
 			Size:      layerData.Size,
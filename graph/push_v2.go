@@ -15,6 +15,7 @@ import (
 	"github.com/docker/docker/pkg/progressreader"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/transfer"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
@@ -121,6 +122,15 @@ func (p *v2Pusher) pushV2Tag(tag string) error {
 
 	out := p.config.OutStream
 
+	// uploads are the layers being uploaded, indexed by their position in
+	// the manifest.
+	uploads := make(map[int]*layerUpload)
+	defer func() {
+		for _, u := range uploads {
+			u.watcher.Release()
+		}
+	}()
+
 	for ; layer != nil; layer, err = p.graph.GetParent(layer) {
 		if err != nil {
 			return err
@@ -171,19 +181,15 @@ func (p *v2Pusher) pushV2Tag(tag string) error {
 		}
 
 		// if digest was empty or not saved, or if blob does not exist on the remote repository,
-		// then fetch it.
+		// then upload it. The layers are uploaded concurrently, and an
+		// upload is shared by all the pushes of the layer to the repository.
 		if !exists {
-			var pushDigest digest.Digest
-			if pushDigest, err = p.pushV2Image(p.repo.Blobs(context.Background()), layer); err != nil {
-				return err
+			out.Write(p.sf.FormatProgress(stringid.TruncateID(layer.ID), "Preparing", nil))
+			key := fmt.Sprintf("upload:%s/%s:%s", p.endpoint.URL, p.repo.Name(), layer.ID)
+			uploads[len(m.FSLayers)] = &layerUpload{
+				id:      layer.ID,
+				watcher: p.uploadManager.Transfer(key, p.upload(layer), out),
 			}
-			if dgst == "" {
-				// Cache new checksum
-				if err := p.graph.SetLayerDigest(layer.ID, pushDigest); err != nil {
-					return err
-				}
-			}
-			dgst = pushDigest
 		}
 
 		// read v1Compatibility config, generate new if needed
@@ -192,11 +198,39 @@ func (p *v2Pusher) pushV2Tag(tag string) error {
 			return err
 		}
 
+		// The digest of the uploaded layers is set once they are pushed
 		m.FSLayers = append(m.FSLayers, manifest.FSLayer{BlobSum: dgst})
 		m.History = append(m.History, manifest.History{V1Compatibility: string(jsonData)})
 
 		layersSeen[layer.ID] = true
-		p.layersPushed[dgst] = true
+		if exists {
+			p.layersPushed[dgst] = true
+		}
+	}
+
+	for i := range m.FSLayers {
+		u, ok := uploads[i]
+		if !ok {
+			continue
+		}
+		select {
+		case <-u.watcher.Done():
+		case <-p.config.Stop:
+			return errClientDisconnected
+		}
+		result, err := u.watcher.Result()
+		if err != nil {
+			return err
+		}
+		pushDigest := result.(digest.Digest)
+		if m.FSLayers[i].BlobSum == "" {
+			// Cache new checksum
+			if err := p.graph.SetLayerDigest(u.id, pushDigest); err != nil {
+				return err
+			}
+		}
+		m.FSLayers[i].BlobSum = pushDigest
+		p.layersPushed[pushDigest] = true
 	}
 
 	logrus.Infof("Signed manifest for %s:%s using daemon's key: %s", p.repo.Name(), tag, p.trustKey.KeyID())
@@ -220,11 +254,21 @@ func (p *v2Pusher) pushV2Tag(tag string) error {
 	return manSvc.Put(signed)
 }
 
-func (p *v2Pusher) pushV2Image(bs distribution.BlobService, img *image.Image) (digest.Digest, error) {
-	out := p.config.OutStream
+// layerUpload is a layer uploaded by a push.
+type layerUpload struct {
+	id      string
+	watcher *transfer.Watcher
+}
 
-	out.Write(p.sf.FormatProgress(stringid.TruncateID(img.ID), "Preparing", nil))
+// upload returns the transfer uploading a layer, whose result is the digest
+// of the compressed layer.
+func (p *v2Pusher) upload(img *image.Image) transfer.DoFunc {
+	return func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		return p.pushV2Image(ctx, progress, p.repo.Blobs(ctx), img)
+	}
+}
 
+func (p *v2Pusher) pushV2Image(ctx context.Context, out io.Writer, bs distribution.BlobService, img *image.Image) (digest.Digest, error) {
 	image, err := p.graph.Get(img.ID)
 	if err != nil {
 		return "", err
@@ -236,14 +280,14 @@ func (p *v2Pusher) pushV2Image(bs distribution.BlobService, img *image.Image) (d
 	defer arch.Close()
 
 	// Send the layer
-	layerUpload, err := bs.Create(context.Background())
+	layerUpload, err := bs.Create(ctx)
 	if err != nil {
 		return "", err
 	}
 	defer layerUpload.Close()
 
 	reader := progressreader.New(progressreader.Config{
-		In:        ioutil.NopCloser(arch), // we'll take care of close here.
+		In:        newMeteredReader(ioutil.NopCloser(arch), pushedBytes), // we'll take care of close here.
 		Out:       out,
 		Formatter: p.sf,
 
@@ -281,15 +325,30 @@ func (p *v2Pusher) pushV2Image(bs distribution.BlobService, img *image.Image) (d
 		}
 	}()
 
+	// Interrupt the upload if all its pushes are cancelled
+	uploaded := make(chan struct{})
+	defer close(uploaded)
+	go func() {
+		select {
+		case <-ctx.Done():
+			pipeReader.CloseWithError(ctx.Err())
+		case <-uploaded:
+		}
+	}()
+
 	out.Write(p.sf.FormatProgress(stringid.TruncateID(img.ID), "Pushing", nil))
 	nn, err := layerUpload.ReadFrom(pipeReader)
 	pipeReader.Close()
 	if err != nil {
+		if ctx.Err() != nil {
+			layerUpload.Cancel(context.Background())
+			return "", ctx.Err()
+		}
 		return "", err
 	}
 
 	dgst := digester.Digest()
-	if _, err := layerUpload.Commit(context.Background(), distribution.Descriptor{Digest: dgst}); err != nil {
+	if _, err := layerUpload.Commit(ctx, distribution.Descriptor{Digest: dgst}); err != nil {
 		return "", err
 	}
 
@@ -32,6 +32,13 @@ func (dcs dumbCredentialStore) Basic(*url.URL) (string, string) {
 // providing timeout settings and authentication support, and also verifies the
 // remote API version.
 func NewV2Repository(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint, metaHeaders http.Header, authConfig *cliconfig.AuthConfig, actions ...string) (distribution.Repository, error) {
+	repo, _, err := newV2Repository(repoInfo, endpoint, metaHeaders, authConfig, actions...)
+	return repo, err
+}
+
+// newV2Repository returns a repository (v2 only), and the HTTP client
+// authenticated to its registry.
+func newV2Repository(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint, metaHeaders http.Header, authConfig *cliconfig.AuthConfig, actions ...string) (distribution.Repository, *http.Client, error) {
 	ctx := context.Background()
 
 	repoName := repoInfo.CanonicalName
@@ -63,11 +70,11 @@ func NewV2Repository(repoInfo *registry.RepositoryInfo, endpoint registry.APIEnd
 	endpointStr := strings.TrimRight(endpoint.URL, "/") + "/v2/"
 	req, err := http.NewRequest("GET", endpointStr, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	resp, err := pingClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
@@ -82,13 +89,13 @@ func NewV2Repository(repoInfo *registry.RepositoryInfo, endpoint registry.APIEnd
 			}
 		}
 		if !foundVersion {
-			return nil, errors.New("endpoint does not support v2 API")
+			return nil, nil, errors.New("endpoint does not support v2 API")
 		}
 	}
 
 	challengeManager := auth.NewSimpleChallengeManager()
 	if err := challengeManager.AddResponse(resp); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	creds := dumbCredentialStore{auth: authConfig}
@@ -97,7 +104,11 @@ func NewV2Repository(repoInfo *registry.RepositoryInfo, endpoint registry.APIEnd
 	modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
 	tr := transport.NewTransport(base, modifiers...)
 
-	return client.NewRepository(ctx, repoName, endpoint.URL, tr)
+	repo, err := client.NewRepository(ctx, repoName, endpoint.URL, tr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, &http.Client{Transport: tr}, nil
 }
 
 func digestFromManifest(m *manifest.SignedManifest, localName string) (digest.Digest, int, error) {
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/autogen/dockerversion"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
+)
+
+// Squash creates an image with the configuration of the image id, and a
+// single layer holding the changes of the layers of id since the image base,
+// which must be an ancestor of id. base may be "" to squash all the layers
+// of id. The history of the squashed images is kept in the History of the
+// new image.
+func (graph *Graph) Squash(id, base string) (*image.Image, error) {
+	img, err := graph.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The images squashed, from the most recent
+	var squashedImages []*image.Image
+	current := img
+	for current != nil && current.ID != base {
+		squashedImages = append(squashedImages, current)
+		if current, err = graph.GetParent(current); err != nil {
+			return nil, err
+		}
+	}
+	if current == nil && base != "" {
+		return nil, fmt.Errorf("Image %s is not an ancestor of %s", base, id)
+	}
+	var history []image.History
+	for i := len(squashedImages) - 1; i >= 0; i-- {
+		history = append(history, imageHistory(squashedImages[i])...)
+	}
+
+	layerData, err := graph.squashedDiff(id, base)
+	if err != nil {
+		return nil, err
+	}
+	defer layerData.Close()
+
+	squashed := &image.Image{
+		ID:              stringid.GenerateRandomID(),
+		Parent:          base,
+		Comment:         img.Comment,
+		Created:         time.Now().UTC(),
+		Container:       img.Container,
+		ContainerConfig: img.ContainerConfig,
+		DockerVersion:   dockerversion.VERSION,
+		Author:          img.Author,
+		Config:          img.Config,
+		Architecture:    runtime.GOARCH,
+		OS:              runtime.GOOS,
+		History:         history,
+	}
+	if err := graph.Register(v1Descriptor{squashed}, layerData); err != nil {
+		return nil, err
+	}
+	return squashed, nil
+}
+
+// squashedDiff returns an archive of the changes of the filesystem of the
+// image id since the image base. The changes are computed from the
+// filesystems of both images, as the drivers with a native diff only
+// compare a layer with its parent.
+func (graph *Graph) squashedDiff(id, base string) (_ archive.Archive, err error) {
+	layerFs, err := graph.driver.Get(id, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			graph.driver.Put(id)
+		}
+	}()
+
+	var layerData archive.Archive
+	if base == "" {
+		layerData, err = archive.Tar(layerFs, archive.Uncompressed)
+	} else {
+		var baseFs string
+		baseFs, err = graph.driver.Get(base, "")
+		if err != nil {
+			return nil, err
+		}
+		defer graph.driver.Put(base)
+
+		var changes []archive.Change
+		changes, err = archive.ChangesDirs(layerFs, baseFs)
+		if err != nil {
+			return nil, err
+		}
+		layerData, err = archive.ExportChanges(layerFs, changes, graph.uidMaps, graph.gidMaps)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(layerData, func() error {
+		err := layerData.Close()
+		graph.driver.Put(id)
+		return err
+	}), nil
+}
+
+// imageHistory returns the history of img: its own History if it is
+// squashed, or the entry describing it otherwise.
+func imageHistory(img *image.Image) []image.History {
+	if len(img.History) > 0 {
+		return img.History
+	}
+	return []image.History{{
+		Created:   img.Created,
+		Author:    img.Author,
+		CreatedBy: strings.Join(img.ContainerConfig.Cmd.Slice(), " "),
+		Comment:   img.Comment,
+	}}
+}
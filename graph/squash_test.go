@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/runconfig"
+)
+
+// layerTar returns a layer with the given files. The files named with the
+// ".wh." prefix are whiteouts.
+func layerTar(t *testing.T, names ...string) io.Reader {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0644,
+			Uid:  os.Getuid(),
+			Gid:  os.Getgid(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func registerTestImage(t *testing.T, graph *Graph, parent, cmd string, layerData io.Reader) *image.Image {
+	img := &image.Image{
+		ID:      stringid.GenerateNonCryptoID(),
+		Parent:  parent,
+		Created: time.Now().UTC(),
+		ContainerConfig: runconfig.Config{
+			Cmd: stringutils.NewStrSlice(cmd),
+		},
+	}
+	if err := graph.Register(v1Descriptor{img}, layerData); err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestSquash(t *testing.T) {
+	graph, _ := tempGraph(t)
+	defer nukeGraph(graph)
+
+	base := registerTestImage(t, graph, "", "base", layerTar(t, "a", "b"))
+	img1 := registerTestImage(t, graph, base.ID, "step 1", layerTar(t, "c", ".wh.b"))
+	img2 := registerTestImage(t, graph, img1.ID, "step 2", layerTar(t, "d", ".wh.c"))
+
+	squashed, err := graph.Squash(img2.ID, base.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if squashed.Parent != base.ID {
+		t.Fatalf("Expected the squashed image to have the parent %s, got %s", base.ID, squashed.Parent)
+	}
+	if len(squashed.History) != 2 || squashed.History[0].CreatedBy != "step 1" || squashed.History[1].CreatedBy != "step 2" {
+		t.Fatalf("Expected the history of the squashed images, got %+v", squashed.History)
+	}
+
+	rootfs, err := graph.driver.Get(squashed.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer graph.driver.Put(squashed.ID)
+	for name, exists := range map[string]bool{"a": true, "b": false, "c": false, "d": true} {
+		if _, err := os.Stat(filepath.Join(rootfs, name)); (err == nil) != exists {
+			t.Fatalf("Expected the existence of %s in the squashed image to be %v, got %v", name, exists, err)
+		}
+	}
+
+	// The history of a squashed image is kept when it is squashed again
+	img3 := registerTestImage(t, graph, squashed.ID, "step 3", layerTar(t, "e"))
+	squashed, err = graph.Squash(img3.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if squashed.Parent != "" {
+		t.Fatalf("Expected the squashed image to have no parent, got %s", squashed.Parent)
+	}
+	if len(squashed.History) != 4 || squashed.History[0].CreatedBy != "base" || squashed.History[3].CreatedBy != "step 3" {
+		t.Fatalf("Expected the history of the squashed images, got %+v", squashed.History)
+	}
+
+	if _, err := graph.Squash(base.ID, img1.ID); err == nil {
+		t.Fatal("Expected an error squashing an image onto an image which is not its ancestor")
+	}
+}
@@ -11,14 +11,17 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/distribution/digest"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/graph/tags"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/broadcaster"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/transfer"
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/utils"
 	"github.com/docker/libtrust"
@@ -35,14 +38,28 @@ type TagStore struct {
 	graph *Graph
 	// Repositories is a map of repositories, indexed by name.
 	Repositories map[string]Repository
-	trustKey     libtrust.PrivateKey
+	// TrustedTags maps the tags verified against the trust data of their
+	// repository when they were pulled to their signed digest, indexed by
+	// repository name.
+	TrustedTags map[string]Repository
+	trustKey    libtrust.PrivateKey
 	sync.Mutex
 	// FIXME: move push/pull-related fields
 	// to a helper type
-	pullingPool     map[string]*broadcaster.Buffered
-	pushingPool     map[string]*broadcaster.Buffered
-	registryService *registry.Service
-	eventsService   *events.Events
+	pullingPool map[string]*broadcaster.Buffered
+	pushingPool map[string]*broadcaster.Buffered
+	// downloadManager and uploadManager run the layer transfers of all the
+	// pulls and pushes of the daemon.
+	downloadManager *transfer.Manager
+	uploadManager   *transfer.Manager
+	// maxDownloadAttempts is the number of attempts to download a layer,
+	// and downloadRetryDelay the delay before the first retry.
+	maxDownloadAttempts int
+	downloadRetryDelay  time.Duration
+	registryService     *registry.Service
+	eventsService       *events.Events
+	trustPolicy         TrustPolicy
+	trustDir            string
 }
 
 // Repository maps tags to image IDs.
@@ -78,6 +95,20 @@ type TagStoreConfig struct {
 	Registry *registry.Service
 	// Events is the events service to use for logging.
 	Events *events.Events
+	// TrustPolicy lists the registries whose tags are verified against
+	// the trust data of their repositories when they are pulled.
+	TrustPolicy TrustPolicy
+	// TrustDir is the directory of the cached trust data.
+	TrustDir string
+	// MaxConcurrentDownloads and MaxConcurrentUploads limit the number of
+	// layers downloaded and uploaded at the same time. Zero means no limit.
+	MaxConcurrentDownloads int
+	MaxConcurrentUploads   int
+	// MaxDownloadAttempts is the number of attempts to download a layer
+	// before a pull fails, and DownloadRetryDelay the delay before the
+	// first retry, doubled after each retry.
+	MaxDownloadAttempts int
+	DownloadRetryDelay  time.Duration
 }
 
 // NewTagStore creates a new TagStore at specified path, using the parameters
@@ -89,14 +120,20 @@ func NewTagStore(path string, cfg *TagStoreConfig) (*TagStore, error) {
 	}
 
 	store := &TagStore{
-		path:            abspath,
-		graph:           cfg.Graph,
-		trustKey:        cfg.Key,
-		Repositories:    make(map[string]Repository),
-		pullingPool:     make(map[string]*broadcaster.Buffered),
-		pushingPool:     make(map[string]*broadcaster.Buffered),
-		registryService: cfg.Registry,
-		eventsService:   cfg.Events,
+		path:                abspath,
+		graph:               cfg.Graph,
+		trustKey:            cfg.Key,
+		Repositories:        make(map[string]Repository),
+		pullingPool:         make(map[string]*broadcaster.Buffered),
+		pushingPool:         make(map[string]*broadcaster.Buffered),
+		downloadManager:     transfer.NewManager(cfg.MaxConcurrentDownloads),
+		uploadManager:       transfer.NewManager(cfg.MaxConcurrentUploads),
+		maxDownloadAttempts: cfg.MaxDownloadAttempts,
+		downloadRetryDelay:  cfg.DownloadRetryDelay,
+		registryService:     cfg.Registry,
+		eventsService:       cfg.Events,
+		trustPolicy:         cfg.TrustPolicy,
+		trustDir:            cfg.TrustDir,
 	}
 	// Load the json file if it exists, otherwise create it.
 	if err := store.reload(); os.IsNotExist(err) {
@@ -109,6 +146,16 @@ func NewTagStore(path string, cfg *TagStoreConfig) (*TagStore, error) {
 	return store, nil
 }
 
+// logImageEvent generates an event related to an image. ref is the image
+// reference or ID the action applied to.
+func (store *TagStore) logImageEvent(ref, action string) {
+	actor := eventtypes.Actor{
+		ID:         ref,
+		Attributes: map[string]string{"name": ref},
+	}
+	store.eventsService.Log(action, eventtypes.ImageEventType, actor)
+}
+
 func (store *TagStore) save() error {
 	// Store the json ball
 	jsonData, err := json.Marshal(store)
@@ -0,0 +1,306 @@
+package graph
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/docker/docker/cliconfig"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/graph/tags"
+	"github.com/docker/docker/pkg/parsers"
+	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/docker/docker/pkg/tlsconfig"
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
+	"github.com/docker/notary/client"
+	"github.com/endophage/gotuf/store"
+)
+
+// TrustPolicy is the content trust policy of the daemon. It maps the names
+// of the registries whose tags are verified against the trust data of their
+// repositories when they are pulled, such as "docker.io" or
+// "registry.example.com:5000", to their trust server.
+type TrustPolicy map[string]TrustServer
+
+// TrustServer is the trust server of a registry in a TrustPolicy.
+type TrustServer struct {
+	// URL is the URL of the notary server of the registry. It defaults to
+	// the notary server of the Docker Hub for the official registry, and to
+	// the registry itself for the others.
+	URL string `json:"server"`
+}
+
+// LoadTrustPolicy reads a trust policy from a JSON file.
+func LoadTrustPolicy(path string) (TrustPolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var policy TrustPolicy
+	if err := json.NewDecoder(f).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid trust policy %s: %v", path, err)
+	}
+	for name, server := range policy {
+		if server.URL == "" {
+			continue
+		}
+		if u, err := url.Parse(server.URL); err != nil || u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid trust policy %s: valid https URL required for the trust server of %s, got %s", path, name, server.URL)
+		}
+	}
+	return policy, nil
+}
+
+// server returns the URL of the trust server of a registry, and false if the
+// tags of the registry are not verified.
+func (policy TrustPolicy) server(index *registry.IndexInfo) (string, bool) {
+	server, ok := policy[index.Name]
+	switch {
+	case !ok:
+		return "", false
+	case server.URL != "":
+		return server.URL, true
+	case index.Official:
+		return registry.NotaryServer, true
+	}
+	return "https://" + index.Name, true
+}
+
+// trustedTarget is a tag signed in the trust data of a repository.
+type trustedTarget struct {
+	tag    string
+	digest digest.Digest
+}
+
+// pullTrusted pulls the tags of a repository by the digests signed in its
+// trust data, and tags them once pulled. If tag is empty, all the tags signed
+// in the trust data are pulled.
+func (s *TagStore) pullTrusted(image string, repoInfo *registry.RepositoryInfo, tag, server string, imagePullConfig *ImagePullConfig, sf *streamformatter.StreamFormatter) error {
+	targets, err := s.trustedTargets(repoInfo, tag, server, imagePullConfig.AuthConfig)
+	if err != nil {
+		return err
+	}
+
+	for i, t := range targets {
+		imagePullConfig.OutStream.Write(sf.FormatStatus("", "Pull (%d of %d): %s@%s", i+1, len(targets), utils.ImageReference(repoInfo.LocalName, t.tag), t.digest))
+		if err := s.pullFromEndpoints(image, repoInfo, t.digest.String(), imagePullConfig, sf); err != nil {
+			return err
+		}
+		imagePullConfig.OutStream.Write(sf.FormatStatus("", "Tagging %s@%s as %s", repoInfo.LocalName, t.digest, utils.ImageReference(repoInfo.LocalName, t.tag)))
+		if err := s.tagTrusted(repoInfo.LocalName, t.tag, t.digest.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trustedTargets returns the digest signed for a tag in the trust data of a
+// repository, or all the signed tags if tag is empty.
+func (s *TagStore) trustedTargets(repoInfo *registry.RepositoryInfo, tag, server string, authConfig *cliconfig.AuthConfig) ([]trustedTarget, error) {
+	notaryRepo, err := s.notaryRepository(repoInfo, server, authConfig)
+	if err != nil {
+		return nil, derr.ErrorCodeTrustServer.WithArgs(repoInfo.LocalName, err)
+	}
+
+	var targets []*client.Target
+	if tag == "" {
+		if targets, err = notaryRepo.ListTargets(); err != nil {
+			return nil, trustError(repoInfo.LocalName, err)
+		}
+	} else {
+		t, err := notaryRepo.GetTargetByName(tag)
+		if err != nil {
+			return nil, trustError(utils.ImageReference(repoInfo.LocalName, tag), err)
+		}
+		targets = append(targets, t)
+	}
+
+	trusted := make([]trustedTarget, 0, len(targets))
+	for _, t := range targets {
+		h, ok := t.Hashes["sha256"]
+		if !ok {
+			return nil, derr.ErrorCodeTrustVerification.WithArgs(utils.ImageReference(repoInfo.LocalName, t.Name), errors.New("no valid hash, expecting sha256"))
+		}
+		trusted = append(trusted, trustedTarget{
+			tag:    t.Name,
+			digest: digest.NewDigestFromHex("sha256", hex.EncodeToString(h)),
+		})
+	}
+	return trusted, nil
+}
+
+// trustError converts the errors of the notary client to the errors of the
+// daemon.
+func trustError(name string, err error) error {
+	switch err := err.(type) {
+	case store.ErrMetaNotFound, *json.SyntaxError:
+		return derr.ErrorCodeNoTrustData.WithArgs(name)
+	case client.ErrExpired:
+		return derr.ErrorCodeTrustExpired.WithArgs(name, err)
+	case store.ErrServerUnavailable, *net.OpError, *url.Error:
+		return derr.ErrorCodeTrustServer.WithArgs(name, err)
+	}
+	return derr.ErrorCodeTrustVerification.WithArgs(name, err)
+}
+
+// notaryRepository returns a client of the trust data of a repository. The
+// TLS certificates of the trust server are read from the certificates
+// directory of the daemon, like the ones of the registries.
+func (s *TagStore) notaryRepository(repoInfo *registry.RepositoryInfo, server string, authConfig *cliconfig.AuthConfig) (*client.NotaryRepository, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:         tlsconfig.ClientDefault.MinVersion,
+		CipherSuites:       tlsconfig.ClientDefault.CipherSuites,
+		InsecureSkipVerify: !repoInfo.Index.Secure,
+	}
+	if err := registry.ReadCertsDirectory(cfg, filepath.Join(registry.CertsDir, u.Host)); err != nil {
+		return nil, err
+	}
+
+	base := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			DualStack: true,
+		}).Dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     cfg,
+		DisableKeepAlives:   true,
+	}
+
+	modifiers := registry.DockerHeaders(http.Header{})
+	authTransport := transport.NewTransport(base, modifiers...)
+	pingClient := &http.Client{
+		Transport: authTransport,
+		Timeout:   5 * time.Second,
+	}
+	endpoint := server + "/v2/"
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeManager := auth.NewSimpleChallengeManager()
+	resp, err := pingClient.Do(req)
+	if err != nil {
+		// The trust data cached by a previous pull is used if the trust
+		// server cannot be reached
+		logrus.Debugf("Error pinging notary server %q: %s", endpoint, err)
+	} else {
+		defer resp.Body.Close()
+		if err := challengeManager.AddResponse(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if authConfig == nil {
+		authConfig = &cliconfig.AuthConfig{}
+	}
+	creds := trustCredentials{auth: authConfig}
+	tokenHandler := auth.NewTokenHandler(authTransport, creds, repoInfo.CanonicalName, "pull")
+	basicHandler := auth.NewBasicHandler(creds)
+	modifiers = append(modifiers, transport.RequestModifier(auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler)))
+	tr := transport.NewTransport(base, modifiers...)
+
+	return client.NewNotaryRepository(s.trustDir, repoInfo.CanonicalName, server, tr, noPassphrase)
+}
+
+// trustCredentials provides the credentials of a pull to the trust server.
+type trustCredentials struct {
+	auth *cliconfig.AuthConfig
+}
+
+func (tc trustCredentials) Basic(u *url.URL) (string, string) {
+	return tc.auth.Username, tc.auth.Password
+}
+
+// noPassphrase is the passphrase retriever of the notary client of the
+// daemon, which only reads trust data and never signs it.
+func noPassphrase(keyName, alias string, createNew bool, attempts int) (string, bool, error) {
+	return "", true, errors.New("the daemon does not sign trust data")
+}
+
+// tagTrusted tags the image of a digest pulled from a trusted repository,
+// and records that the tag was verified.
+func (store *TagStore) tagTrusted(repoName, tag, dgst string) error {
+	img, err := store.GetImage(repoName, dgst)
+	if err != nil {
+		return err
+	}
+	if img == nil {
+		return fmt.Errorf("no image for %s@%s", repoName, dgst)
+	}
+	if err := store.Tag(repoName, tag, img.ID, true); err != nil {
+		return err
+	}
+
+	store.Lock()
+	defer store.Unlock()
+	if err := store.reload(); err != nil {
+		return err
+	}
+	repoName = registry.NormalizeLocalName(repoName)
+	if store.TrustedTags == nil {
+		store.TrustedTags = make(map[string]Repository)
+	}
+	if _, exists := store.TrustedTags[repoName]; !exists {
+		store.TrustedTags[repoName] = Repository{}
+	}
+	store.TrustedTags[repoName][tag] = dgst
+	return store.save()
+}
+
+// VerifyTrusted returns an error if name is a tag of a registry of the trust
+// policy which was not verified when it was pulled, or whose image changed
+// since. Image IDs and digests are always trusted.
+func (store *TagStore) VerifyTrusted(name string) error {
+	if len(store.trustPolicy) == 0 {
+		return nil
+	}
+	repoName, ref := parsers.ParseRepositoryTag(name)
+	if ref == "" {
+		ref = tags.DefaultTag
+	}
+	if utils.DigestReference(ref) {
+		return nil
+	}
+
+	store.Lock()
+	defer store.Unlock()
+	localName := registry.NormalizeLocalName(repoName)
+	id, exists := store.Repositories[localName][ref]
+	if !exists {
+		// Not a tag
+		return nil
+	}
+	repoInfo, err := store.registryService.ResolveRepository(repoName)
+	if err != nil {
+		return err
+	}
+	if _, ok := store.trustPolicy.server(repoInfo.Index); !ok {
+		return nil
+	}
+	if dgst, trusted := store.TrustedTags[localName][ref]; trusted && store.Repositories[localName][dgst] == id {
+		return nil
+	}
+	return derr.ErrorCodeUntrustedTag.WithArgs(utils.ImageReference(localName, ref))
+}
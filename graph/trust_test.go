@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/registry"
+	"github.com/docker/docker/utils"
+)
+
+func TestLoadTrustPolicy(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "trust-policy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "policy.json")
+	policyJSON := `{
+		"docker.io": {},
+		"registry.example.com:5000": {},
+		"mirror.example.com": {"server": "https://notary.example.com:4443"}
+	}`
+	if err := ioutil.WriteFile(path, []byte(policyJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadTrustPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		index    *registry.IndexInfo
+		expected string
+	}{
+		{&registry.IndexInfo{Name: "docker.io", Official: true}, registry.NotaryServer},
+		{&registry.IndexInfo{Name: "registry.example.com:5000"}, "https://registry.example.com:5000"},
+		{&registry.IndexInfo{Name: "mirror.example.com"}, "https://notary.example.com:4443"},
+		{&registry.IndexInfo{Name: "other.example.com"}, ""},
+	} {
+		server, ok := policy.server(c.index)
+		if server != c.expected || ok != (c.expected != "") {
+			t.Fatalf("Expected the trust server %q for %s, got %q (%v)", c.expected, c.index.Name, server, ok)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"docker.io": {"server": "http://notary.example.com"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTrustPolicy(path); err == nil {
+		t.Fatal("Expected an error for a trust server without TLS")
+	}
+}
+
+func TestVerifyTrusted(t *testing.T) {
+	tmp, err := utils.TestDirectory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store := mkTestTagStore(tmp, t)
+	defer store.graph.driver.Cleanup()
+	store.registryService = registry.NewService(nil)
+	store.trustPolicy = TrustPolicy{"127.0.0.1:8000": {}}
+
+	// Only the tags of the registries of the policy are verified
+	for _, name := range []string{
+		testOfficialImageName,
+		testPrivateImageID,
+		testPrivateImageName + "@" + testPrivateImageDigest,
+		testPrivateImageName + ":unknown",
+	} {
+		if err := store.VerifyTrusted(name); err != nil {
+			t.Fatalf("Expected %s to be trusted, got %v", name, err)
+		}
+	}
+	if err := store.VerifyTrusted(testPrivateImageName); err == nil {
+		t.Fatalf("Expected %s not to be trusted", testPrivateImageName)
+	}
+
+	if err := store.tagTrusted(testPrivateImageName, testPrivateImageTag, testPrivateImageDigest); err != nil {
+		t.Fatal(err)
+	}
+	name := testPrivateImageName + ":" + testPrivateImageTag
+	if err := store.VerifyTrusted(name); err != nil {
+		t.Fatalf("Expected %s to be trusted, got %v", name, err)
+	}
+
+	// The tag is no longer trusted once it refers to another image
+	if err := store.Tag(testPrivateImageName, testPrivateImageTag, testOfficialImageID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.VerifyTrusted(name); err == nil {
+		t.Fatalf("Expected %s not to be trusted", name)
+	}
+}
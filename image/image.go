@@ -60,6 +60,22 @@ type Image struct {
 	ParentID digest.Digest `json:"parent_id,omitempty"`
 	// LayerID provides the content address of the associated layer.
 	LayerID digest.Digest `json:"layer_id,omitempty"`
+	// History is the history of the images squashed into this image, from
+	// the oldest to the most recent. It is empty if the image is not squashed.
+	History []History `json:"history,omitempty"`
+}
+
+// History describes an image squashed into another image, so that the steps
+// which built an image are kept once its layers are squashed.
+type History struct {
+	// Created timestamp when the squashed image was created
+	Created time.Time `json:"created"`
+	// Author of the squashed image
+	Author string `json:"author,omitempty"`
+	// CreatedBy is the command which created the squashed image
+	CreatedBy string `json:"created_by,omitempty"`
+	// Comment user added comment
+	Comment string `json:"comment,omitempty"`
 }
 
 // NewImgJSON creates an Image configuration from json.
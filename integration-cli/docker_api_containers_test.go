@@ -1553,3 +1553,40 @@ func (s *DockerSuite) TestPostContainersCreateWithWrongCpusetValues(c *check.C)
 	expected = "Invalid value 42-3,1-- for cpuset mems.\n"
 	c.Assert(string(body), check.Equals, expected, check.Commentf("Expected output to contain %q, got %q", expected, string(body)))
 }
+
+func (s *DockerSuite) TestContainerApiCommitSquash(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name=testapicommitsquash1", "busybox", "/bin/sh", "-c", "touch /test1 /removed")
+	dockerCmd(c, "commit", "testapicommitsquash1", "testapicommitsquash1")
+	dockerCmd(c, "run", "--name=testapicommitsquash2", "testapicommitsquash1", "/bin/sh", "-c", "touch /test2 && rm /removed")
+
+	status, b, err := sockRequest("POST", "/commit?repo=testapicommitsquash&squash=1&squashbase=busybox&container=testapicommitsquash2", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusCreated, check.Commentf(string(b)))
+
+	var img types.ContainerCommitResponse
+	c.Assert(json.Unmarshal(b, &img), check.IsNil)
+
+	busyboxID, err := inspectField("busybox", "Id")
+	c.Assert(err, check.IsNil)
+	parent, err := inspectField(img.ID, "Parent")
+	c.Assert(err, check.IsNil)
+	c.Assert(parent, check.Equals, busyboxID)
+
+	dockerCmd(c, "run", "--rm", img.ID, "/bin/sh", "-c", "test -e /test1 && test -e /test2 && test ! -e /removed")
+
+	out, _ := dockerCmd(c, "history", img.ID)
+	c.Assert(strings.Count(out, "<missing>"), check.Equals, 1, check.Commentf(out))
+
+	// The layers are squashed since the image of the container by default
+	status, b, err = sockRequest("POST", "/commit?repo=testapicommitsquash&squash=1&container=testapicommitsquash2", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusCreated, check.Commentf(string(b)))
+	c.Assert(json.Unmarshal(b, &img), check.IsNil)
+
+	baseID, err := inspectField("testapicommitsquash1", "Id")
+	c.Assert(err, check.IsNil)
+	parent, err = inspectField(img.ID, "Parent")
+	c.Assert(err, check.IsNil)
+	c.Assert(parent, check.Equals, baseID)
+}
@@ -114,6 +114,37 @@ func (s *DockerSuite) TestInspectApiContainerVolumeDriver(c *check.C) {
 	}
 }
 
+func (s *DockerSuite) TestInspectApiContainerNetworksPre122(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "busybox", "true")
+
+	cleanedContainerID := strings.TrimSpace(out)
+
+	cases := []string{"1.19", "1.20", "1.21"}
+	for _, version := range cases {
+		endpoint := fmt.Sprintf("/v%s/containers/%s/json", version, cleanedContainerID)
+		status, body, err := sockRequest("GET", endpoint, nil)
+		c.Assert(err, check.IsNil)
+		c.Assert(status, check.Equals, http.StatusOK)
+
+		var inspectJSON struct {
+			NetworkSettings struct {
+				Networks []string
+			}
+		}
+		c.Assert(json.Unmarshal(body, &inspectJSON), check.IsNil, check.Commentf("Api version %s expected to list the names of the networks", version))
+		c.Assert(inspectJSON.NetworkSettings.Networks, check.DeepEquals, []string{"bridge"})
+	}
+
+	status, body, err := sockRequest("GET", "/v1.22/containers/"+cleanedContainerID+"/json", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(status, check.Equals, http.StatusOK)
+
+	var inspectJSON types.ContainerJSON
+	c.Assert(json.Unmarshal(body, &inspectJSON), check.IsNil)
+	c.Assert(inspectJSON.NetworkSettings.Networks["bridge"], check.NotNil)
+}
+
 func (s *DockerSuite) TestInspectApiImageResponse(c *check.C) {
 	dockerCmd(c, "tag", "busybox:latest", "busybox:mytag")
 
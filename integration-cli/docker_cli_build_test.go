@@ -21,6 +21,7 @@ import (
 
 	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/docker/pkg/stringutils"
 	"github.com/go-check/check"
 )
@@ -6277,3 +6278,184 @@ func (s *DockerSuite) TestBuildMultipleTags(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(id1, check.Equals, id2)
 }
+
+func (s *DockerSuite) TestBuildMultiStageCopyFrom(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistage"
+	dockerfile := `
+	FROM busybox AS first
+	RUN echo first > /first
+	FROM busybox
+	RUN echo second > /second
+	FROM busybox
+	COPY --from=first /first /from-name
+	COPY --from=1 /second /from-index
+	COPY --from=busybox /bin/echo /from-image`
+	_, err := buildImage(name, dockerfile, false)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "run", "--rm", name, "cat", "/from-name", "/from-index")
+	c.Assert(out, checker.Equals, "first\nsecond\n")
+	dockerCmd(c, "run", "--rm", name, "test", "-x", "/from-image")
+	// The files of the previous stages are not in the image
+	_, _, err = dockerCmdWithError("run", "--rm", name, "ls", "/first")
+	c.Assert(err, checker.NotNil)
+}
+
+func (s *DockerSuite) TestBuildMultiStageFromStage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistagefrom"
+	dockerfile := `
+	FROM busybox AS base
+	ENV FOO bar
+	RUN echo base > /base
+	FROM base
+	RUN echo $FOO > /foo`
+	_, err := buildImage(name, dockerfile, false)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "run", "--rm", name, "cat", "/base", "/foo")
+	c.Assert(out, checker.Equals, "base\nbar\n")
+}
+
+func (s *DockerSuite) TestBuildMultiStageEmptyStage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	// The empty stage is not mistaken for an image of the same name
+	dockerCmd(c, "tag", "busybox", "empty")
+	defer deleteImages("empty")
+
+	_, out, err := buildImageWithOut("testbuildmultistageempty", "FROM scratch AS empty\nFROM busybox\nCOPY --from=empty / /", false)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "build stage empty produced no image")
+
+	_, out, err = buildImageWithOut("testbuildmultistageempty", "FROM scratch AS empty\nFROM empty", false)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "build stage empty produced no image")
+}
+
+func (s *DockerSuite) TestBuildMultiStageTarget(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistagetarget"
+	dockerfile := `
+	FROM busybox AS base
+	RUN echo base > /base
+	FROM busybox AS unused
+	ARG UNUSED
+	RUN false
+	FROM base AS target
+	RUN echo target > /target
+	FROM busybox
+	RUN false`
+	_, out, err := buildImageWithOut(name, dockerfile, false, "--target", "TARGET", "--build-arg", "UNUSED=1")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Skipping stage unused")
+	c.Assert(out, checker.Contains, "Skipping stage 3")
+
+	out, _ = dockerCmd(c, "run", "--rm", name, "cat", "/base", "/target")
+	c.Assert(out, checker.Equals, "base\ntarget\n")
+
+	_, out, err = buildImageWithOut(name, dockerfile, false, "--target", "missing")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "failed to reach build target missing in Dockerfile")
+}
+
+func (s *DockerSuite) TestBuildMultiStageInvalidName(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildmultistageinvalid", "FROM busybox AS 0base", false)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "invalid name for build stage")
+}
+
+func (s *DockerSuite) TestBuildCacheFromOnlyUsesSources(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerfile := `
+	FROM busybox
+	ENV FOO bar
+	RUN echo cachefrom > /cachefrom`
+	id1, err := buildImage("testbuildcachefrom", dockerfile, true)
+	c.Assert(err, checker.IsNil)
+
+	// The local images are not used as a cache when cache sources are given
+	_, out, err := buildImageWithOut("testbuildcachefrom2", dockerfile, true, "--cache-from", "busybox")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, check.Not(checker.Contains), "Using cache")
+
+	id3, out, err := buildImageWithOut("testbuildcachefrom3", dockerfile, true, "--cache-from", "testbuildcachefrom")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(id3, checker.Equals, id1)
+	c.Assert(strings.Count(out, "Using cache"), checker.Equals, 2)
+}
+
+func (s *DockerRegistrySuite) TestBuildCacheFromPulledImage(c *check.C) {
+	repoName := fmt.Sprintf("%v/dockercli/cachefrom", privateRegistryURL)
+	dockerfile := `
+	FROM busybox
+	ENV FOO bar
+	RUN echo cachefrom > /cachefrom`
+	_, err := buildImage(repoName, dockerfile, true)
+	c.Assert(err, checker.IsNil)
+	dockerCmd(c, "push", repoName)
+
+	// The image pulled on a fresh daemon gets content-addressable IDs, so
+	// that its history is not linked to the busybox image loaded there
+	c.Assert(s.d.StartWithBusybox(), checker.IsNil)
+	out, err := s.d.Cmd("pull", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	pulledID, err := s.d.Cmd("inspect", "-f", "{{.Id}}", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(pulledID))
+
+	ctx, err := fakeContext(dockerfile, nil)
+	c.Assert(err, checker.IsNil)
+	defer ctx.Close()
+
+	out, err = s.d.Cmd("build", "-t", "cachefrom-nosource", ctx.Dir)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, check.Not(checker.Contains), "Using cache")
+
+	out, err = s.d.Cmd("build", "-t", "cachefrom", "--cache-from", repoName, ctx.Dir)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.Count(out, "Using cache"), checker.Equals, 2)
+	id, err := s.d.Cmd("inspect", "-f", "{{.Id}}", "cachefrom")
+	c.Assert(err, checker.IsNil, check.Commentf(id))
+	c.Assert(id, checker.Equals, pulledID)
+}
+
+func (s *DockerSuite) TestBuildCacheFromMissingImage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildcachefrommissing", "FROM busybox\nENV FOO bar", true, "--cache-from", "cachefrom-missing")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Could not find cache source cachefrom-missing, skipping")
+}
+
+func (s *DockerSuite) TestBuildSquash(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildsquash"
+	dockerfile := `
+	FROM busybox
+	RUN echo squash > /squash
+	RUN dd if=/dev/zero of=/removed bs=1024 count=1024
+	RUN rm /removed`
+	_, out, err := buildImageWithOut(name, dockerfile, false, "--squash")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Squashed the layers into")
+
+	busyboxID, err := inspectField("busybox", "Id")
+	c.Assert(err, checker.IsNil)
+	parent, err := inspectField(name, "Parent")
+	c.Assert(err, checker.IsNil)
+	c.Assert(parent, checker.Equals, busyboxID, check.Commentf("The squashed layer should be on top of the base image"))
+
+	size, err := inspectField(name, "Size")
+	c.Assert(err, checker.IsNil)
+	sizeInt, err := strconv.ParseInt(size, 10, 64)
+	c.Assert(err, checker.IsNil)
+	c.Assert(sizeInt < 1024*1024, checker.True, check.Commentf("The removed file should not take space in the squashed layer, got size %d", sizeInt))
+
+	out, _ = dockerCmd(c, "run", "--rm", name, "sh", "-c", "cat /squash && test ! -e /removed")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "squash")
+
+	out, _ = dockerCmd(c, "history", "--no-trunc", name)
+	c.Assert(out, checker.Contains, "echo squash > /squash")
+	c.Assert(out, checker.Contains, "rm /removed")
+	c.Assert(strings.Count(out, "<missing>"), checker.Equals, 2, check.Commentf(out))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestCheckpointCreateAndRestore(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon, NativeExecDriver, Criu)
+
+	name := "checkpointed"
+	dockerCmd(c, "run", "-d", "--name", name, "busybox", "sh", "-c", "i=0; while true; do echo $i; i=$((i+1)); sleep 1; done")
+	c.Assert(waitRun(name), checker.IsNil)
+
+	out, _ := dockerCmd(c, "checkpoint", "create", name, "checkpoint1")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "checkpoint1")
+	c.Assert(waitExited(name, 10*time.Second), checker.IsNil)
+	exitCode, err := inspectField(name, "State.ExitCode")
+	c.Assert(err, checker.IsNil)
+	c.Assert(exitCode, checker.Equals, "0")
+
+	out, _ = dockerCmd(c, "checkpoint", "ls", name)
+	c.Assert(out, checker.Contains, "checkpoint1")
+
+	// The counter of the restored process continues from its checkpoint
+	before, _ := dockerCmd(c, "logs", name)
+	dockerCmd(c, "start", "--checkpoint", "checkpoint1", name)
+	c.Assert(waitRun(name), checker.IsNil)
+	time.Sleep(2 * time.Second)
+	after, _ := dockerCmd(c, "logs", name)
+	c.Assert(strings.HasPrefix(after, before), checker.True)
+	c.Assert(after, checker.Not(checker.Contains), before+"0\n")
+
+	out, _ = dockerCmd(c, "checkpoint", "create", "--leave-running", name, "checkpoint2")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "checkpoint2")
+	c.Assert(waitRun(name), checker.IsNil)
+
+	out, _, err = dockerCmdWithError("checkpoint", "create", name, "checkpoint2")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "already exists")
+
+	dockerCmd(c, "checkpoint", "rm", name, "checkpoint1", "checkpoint2")
+	out, _ = dockerCmd(c, "checkpoint", "ls", name)
+	c.Assert(out, checker.Not(checker.Contains), "checkpoint")
+}
+
+func (s *DockerSuite) TestCheckpointErrors(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	name := "notcheckpointed"
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+
+	out, _, err := dockerCmdWithError("checkpoint", "create", name, "checkpoint1")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+
+	out, _, err = dockerCmdWithError("checkpoint", "create", name, "invalid/name")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+
+	out, _ = dockerCmd(c, "checkpoint", "ls", name)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "CHECKPOINT NAME")
+
+	out, _, err = dockerCmdWithError("checkpoint", "rm", name, "checkpoint1")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "No such checkpoint")
+
+	out, _, err = dockerCmdWithError("start", "--checkpoint", "checkpoint1", name)
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+}
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/pkg/integration/checker"
@@ -1804,3 +1806,260 @@ func (s *DockerDaemonSuite) TestDaemonStartWithDefalutTlsHost(c *check.C) {
 		c.Fatalf("docker version should return information of server side")
 	}
 }
+
+func (s *DockerDaemonSuite) TestDaemonEventsPersistAcrossRestart(c *check.C) {
+	c.Assert(s.d.StartWithBusybox(), checker.IsNil)
+
+	// More events than the daemon keeps in memory
+	for i := 0; i < 40; i++ {
+		out, err := s.d.Cmd("create", "busybox")
+		c.Assert(err, checker.IsNil, check.Commentf(out))
+	}
+	out, err := s.d.Cmd("run", "-d", "busybox", "true")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+
+	c.Assert(s.d.Restart(), checker.IsNil)
+
+	until := fmt.Sprintf("%d", time.Now().Unix())
+	out, err = s.d.Cmd("events", "--since=0", "--until="+until)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.Count(out, "(from busybox) create"), checker.GreaterOrEqualThan, 41)
+	c.Assert(out, checker.Contains, id+": (from busybox) die")
+}
+
+func (s *DockerDaemonSuite) TestDaemonMetricsEndpoint(c *check.C) {
+	c.Assert(s.d.StartWithBusybox("--metrics-addr=127.0.0.1:9323"), checker.IsNil)
+
+	out, err := s.d.Cmd("run", "--name", "metrics", "busybox", "true")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("rm", "metrics")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+
+	resp, err := http.Get("http://127.0.0.1:9323/metrics")
+	c.Assert(err, checker.IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, checker.Equals, http.StatusOK)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, checker.IsNil)
+
+	metrics := string(body)
+	c.Assert(metrics, checker.Contains, "# TYPE engine_daemon_container_actions_seconds histogram")
+	for _, action := range []string{"create", "start", "die", "delete"} {
+		c.Assert(metrics, checker.Contains, fmt.Sprintf(`engine_daemon_container_actions_seconds_count{action=%q,result="success"}`, action))
+	}
+	c.Assert(metrics, checker.Contains, `engine_api_request_duration_seconds_count{method="POST",route="/containers/create"}`)
+}
+
+func (s *DockerDaemonSuite) TestDaemonMetricsEndpointDisabled(c *check.C) {
+	c.Assert(s.d.Start(), checker.IsNil)
+
+	_, err := http.Get("http://127.0.0.1:9323/metrics")
+	c.Assert(err, checker.NotNil)
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigFileReload(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	configDir, err := ioutil.TempDir("", "test-daemon-config")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(configDir)
+	configFile := filepath.Join(configDir, "daemon.json")
+
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644), checker.IsNil)
+	c.Assert(s.d.Start("--config-file", configFile), checker.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "foo=bar")
+
+	// The storage driver is only applied when the daemon restarts
+	since := time.Now().Unix()
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=baz"], "storage-driver": "unknown"}`), 0644), checker.IsNil)
+	c.Assert(s.d.cmd.Process.Signal(syscall.SIGHUP), checker.IsNil)
+
+	for i := 0; ; i++ {
+		out, err = s.d.Cmd("info")
+		c.Assert(err, checker.IsNil, check.Commentf(out))
+		if strings.Contains(out, "foo=baz") {
+			break
+		}
+		if i == 50 {
+			c.Fatalf("The labels were not reloaded: %s", out)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(out, checker.Not(checker.Contains), "foo=bar")
+
+	out, err = s.d.Cmd("events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", time.Now().Unix()+1), "--filter", "type=daemon")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "daemon reload")
+	c.Assert(out, checker.Contains, "label=foo=baz")
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigFileConflict(c *check.C) {
+	configDir, err := ioutil.TempDir("", "test-daemon-config")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(configDir)
+	configFile := filepath.Join(configDir, "daemon.json")
+
+	c.Assert(ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644), checker.IsNil)
+	c.Assert(s.d.Start("--config-file", configFile, "--label", "foo=baz"), checker.NotNil)
+
+	content, _ := ioutil.ReadFile(s.d.logFile.Name())
+	c.Assert(string(content), checker.Contains, "the following options are set both as a flag and in the configuration file: label")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestore(c *check.C) {
+	testRequires(c, DaemonIsLinux, NativeExecDriver)
+	c.Assert(s.d.StartWithBusybox("--live-restore"), checker.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "live", "busybox", "sh", "-c", "while true; do echo tick; sleep 1; done")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	// Containers with a TTY are rejected
+	out, err = s.d.Cmd("run", "-d", "-t", "--name", "tty", "busybox", "top")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Containers with a TTY cannot be kept running")
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Pid}}", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	pid := strings.TrimSpace(out)
+
+	c.Assert(s.d.Stop(), checker.IsNil)
+	p, err := strconv.Atoi(pid)
+	c.Assert(err, checker.IsNil)
+	c.Assert(syscall.Kill(p, 0), checker.IsNil, check.Commentf("the container was stopped with the daemon"))
+	c.Assert(s.d.Start("--live-restore"), checker.IsNil)
+
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}} {{.State.Pid}}", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "true "+pid)
+
+	// The output of the restored container is still logged
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	ticks := strings.Count(out, "tick")
+	time.Sleep(3 * time.Second)
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.Count(out, "tick"), checker.GreaterThan, ticks)
+
+	out, err = s.d.Cmd("kill", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("wait", "live")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "137")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestoreNetwork(c *check.C) {
+	testRequires(c, DaemonIsLinux, NativeExecDriver)
+	bridgeName := "external-bridge"
+	bridgeIP := "192.169.1.1/24"
+
+	out, err := createInterface(c, "bridge", bridgeName, bridgeIP)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	defer deleteInterface(c, bridgeName)
+
+	args := []string{"--live-restore", "--bridge", bridgeName, "--icc=false"}
+	c.Assert(s.d.StartWithBusybox(args...), checker.IsNil)
+
+	out, err = s.d.Cmd("run", "-d", "--expose", "4567", "--name", "server", "busybox", "sh", "-c", "while true; do echo ok | nc -l -p 4567; done")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("run", "-d", "--name", "client", "--link", "server:server", "busybox", "top")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	serverIP := s.d.findContainerIP("server")
+	clientIP := s.d.findContainerIP("client")
+
+	c.Assert(s.d.Restart(args...), checker.IsNil)
+
+	// The restored containers keep their addresses and their link
+	c.Assert(s.d.findContainerIP("server"), checker.Equals, serverIP)
+	c.Assert(s.d.findContainerIP("client"), checker.Equals, clientIP)
+	destinationRule := []string{"-i", bridgeName, "-o", bridgeName, "-p", "tcp", "-s", clientIP, "--dport", "4567", "-d", serverIP, "-j", "ACCEPT"}
+	c.Assert(iptables.Exists("filter", "DOCKER", destinationRule...), checker.True, check.Commentf("the iptables rules of the link were not restored"))
+
+	out, err = s.d.Cmd("exec", "client", "nc", "server", "4567")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "ok")
+
+	out, err = s.d.Cmd("kill", "client", "server")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestoreExitWhileStopped(c *check.C) {
+	testRequires(c, DaemonIsLinux, NativeExecDriver)
+	c.Assert(s.d.StartWithBusybox("--live-restore"), checker.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "exits", "busybox", "sh", "-c", "sleep 3; exit 3")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Pid}}", "exits")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	pid, err := strconv.Atoi(strings.TrimSpace(out))
+	c.Assert(err, checker.IsNil)
+
+	c.Assert(s.d.Stop(), checker.IsNil)
+	for i := 0; syscall.Kill(pid, 0) == nil; i++ {
+		if i == 100 {
+			c.Fatal("The container did not exit")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Without --live-restore, the restored containers are stopped when the daemon exits
+	c.Assert(s.d.Start(), checker.IsNil)
+
+	out, err = s.d.Cmd("wait", "exits")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "3")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestoreHealthcheck(c *check.C) {
+	testRequires(c, DaemonIsLinux, NativeExecDriver)
+	c.Assert(s.d.StartWithBusybox("--live-restore"), checker.IsNil)
+
+	ctx, err := fakeContext(`FROM busybox
+		RUN echo OK > /status
+		CMD ["/bin/sleep", "120"]
+		HEALTHCHECK --interval=1s --retries=1 CMD cat /status`, nil)
+	c.Assert(err, checker.IsNil)
+	defer ctx.Close()
+	out, err := s.d.Cmd("build", "-t", "livehealth", ctx.Dir)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+
+	out, err = s.d.Cmd("run", "-d", "--name", "health", "livehealth")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	waitForDaemonHealthStatus(c, s.d, "health", "healthy")
+
+	c.Assert(s.d.Restart("--live-restore"), checker.IsNil)
+
+	// The health of the restored container is still monitored
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Health.Status}}", "health")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "healthy")
+	out, err = s.d.Cmd("exec", "health", "rm", "/status")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	waitForDaemonHealthStatus(c, s.d, "health", "unhealthy")
+}
+
+// waitForDaemonHealthStatus waits for the container name of the daemon d to
+// have the health status expected.
+func waitForDaemonHealthStatus(c *check.C, d *Daemon, name string, expected string) {
+	for i := 0; ; i++ {
+		out, err := d.Cmd("inspect", "-f", "{{.State.Health.Status}}", name)
+		c.Assert(err, checker.IsNil, check.Commentf(out))
+		if strings.TrimSpace(out) == expected {
+			return
+		}
+		if i == 100 {
+			c.Fatalf("The health status of %s is %s, expected %s", name, strings.TrimSpace(out), expected)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (s *DockerDaemonSuite) TestDaemonStorageOptNotSupported(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	s.d.storageDriver = "vfs"
+	c.Assert(s.d.StartWithBusybox(), checker.IsNil)
+
+	out, err := s.d.Cmd("create", "--storage-opt", "size=1G", "busybox")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "--storage-opt is not supported for vfs")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+// startTrustPolicyDaemon starts a daemon verifying the tags of the private
+// registry against the test notary server.
+func startTrustPolicyDaemon(c *check.C) (*Daemon, func()) {
+	tmp, err := ioutil.TempDir("", "trust-policy-")
+	c.Assert(err, checker.IsNil)
+	policy := filepath.Join(tmp, "policy.json")
+	policyJSON := fmt.Sprintf(`{%q: {"server": %q}}`, privateRegistryURL, notaryURL)
+	c.Assert(ioutil.WriteFile(policy, []byte(policyJSON), 0600), checker.IsNil)
+
+	d := NewDaemon(c)
+	if err := d.StartWithBusybox("--content-trust-policy", policy); err != nil {
+		os.RemoveAll(tmp)
+		c.Fatalf("Could not start daemon with a trust policy: %v", err)
+	}
+	return d, func() {
+		d.Stop()
+		os.RemoveAll(tmp)
+	}
+}
+
+func (s *DockerTrustSuite) TestDaemonTrustPolicyPull(c *check.C) {
+	testRequires(c, SameHostDaemon, DaemonIsLinux)
+	repoName := s.setupTrustedImage(c, "daemon-trusted-pull")
+	d, cleanup := startTrustPolicyDaemon(c)
+	defer cleanup()
+
+	// The daemon verifies the tag without the client
+	out, err := d.Cmd("pull", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Tagging")
+
+	out, err = d.Cmd("run", "--rm", repoName, "true")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+
+	// A tag set locally is not trusted
+	out, err = d.Cmd("tag", "-f", "busybox", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = d.Cmd("create", repoName)
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "is not a trusted tag")
+
+	// The tags of other registries are not verified
+	out, err = d.Cmd("run", "--rm", "busybox", "true")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+}
+
+func (s *DockerTrustSuite) TestDaemonTrustPolicyPullUnsigned(c *check.C) {
+	testRequires(c, SameHostDaemon, DaemonIsLinux)
+	repoName := fmt.Sprintf("%v/dockercli/daemon-untrusted-pull:latest", privateRegistryURL)
+	dockerCmd(c, "tag", "busybox", repoName)
+	dockerCmd(c, "push", repoName)
+	dockerCmd(c, "rmi", repoName)
+	d, cleanup := startTrustPolicyDaemon(c)
+	defer cleanup()
+
+	out, err := d.Cmd("pull", repoName)
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "No trust data for")
+
+	// Nothing is pulled
+	out, err = d.Cmd("images")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Not(checker.Contains), "daemon-untrusted-pull")
+}
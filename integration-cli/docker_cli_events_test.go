@@ -81,7 +81,7 @@ func (s *DockerSuite) TestEventsContainerFailStartDie(c *check.C) {
 		c.Fatalf("Container run with command blerg should have failed, but it did not")
 	}
 
-	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	if len(events) <= 1 {
 		c.Fatalf("Missing expected event")
@@ -101,6 +101,7 @@ func (s *DockerSuite) TestEventsContainerFailStartDie(c *check.C) {
 
 func (s *DockerSuite) TestEventsLimit(c *check.C) {
 	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
 	var waitGroup sync.WaitGroup
 	errChan := make(chan error, 17)
 
@@ -122,18 +123,20 @@ func (s *DockerSuite) TestEventsLimit(c *check.C) {
 		}
 	}
 
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	nEvents := len(events) - 1
-	if nEvents != 64 {
-		c.Fatalf("events should be limited to 64, but received %d", nEvents)
+	// create, attach, start, die and destroy for each container, more than
+	// the 64 events the daemon keeps in memory
+	if nEvents != 17*5 {
+		c.Fatalf("events should not be limited to 64, expected %d but received %d", 17*5, nEvents)
 	}
 }
 
 func (s *DockerSuite) TestEventsContainerEvents(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	dockerCmd(c, "run", "--rm", "busybox", "true")
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	if len(events) < 5 {
@@ -168,7 +171,7 @@ func (s *DockerSuite) TestEventsContainerEventsSinceUnixEpoch(c *check.C) {
 	timeBeginning := time.Unix(0, 0).Format(time.RFC3339Nano)
 	timeBeginning = strings.Replace(timeBeginning, "Z", ".000000000Z", -1)
 	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since='%s'", timeBeginning),
-		fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+		fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	if len(events) < 5 {
@@ -586,7 +589,7 @@ func (s *DockerSuite) TestEventsCommit(c *check.C) {
 	dockerCmd(c, "commit", "-m", "test", cID)
 	dockerCmd(c, "stop", cID)
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container="+cID, "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " commit\n") {
 		c.Fatalf("Missing 'commit' log event\n%s", out)
 	}
@@ -619,14 +622,14 @@ func (s *DockerSuite) TestEventsCopy(c *check.C) {
 
 	dockerCmd(c, "cp", "cptest:/tmp/file", tempFile.Name())
 
-	out, _ := dockerCmd(c, "events", "--since=0", "-f", "container=cptest", "--until="+strconv.Itoa(int(since)))
+	out, _ := dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container=cptest", "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " archive-path\n") {
 		c.Fatalf("Missing 'archive-path' log event\n%s", out)
 	}
 
 	dockerCmd(c, "cp", tempFile.Name(), "cptest:/tmp/filecopy")
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container=cptest", "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container=cptest", "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " extract-to-dir\n") {
 		c.Fatalf("Missing 'extract-to-dir' log event\n%s", out)
 	}
@@ -647,7 +650,7 @@ func (s *DockerSuite) TestEventsResize(c *check.C) {
 
 	dockerCmd(c, "stop", cID)
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container="+cID, "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " resize\n") {
 		c.Fatalf("Missing 'resize' log event\n%s", out)
 	}
@@ -684,7 +687,7 @@ func (s *DockerSuite) TestEventsAttach(c *check.C) {
 
 	dockerCmd(c, "stop", cID)
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container="+cID, "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " attach\n") {
 		c.Fatalf("Missing 'attach' log event\n%s", out)
 	}
@@ -697,7 +700,7 @@ func (s *DockerSuite) TestEventsRename(c *check.C) {
 	dockerCmd(c, "run", "--name", "oldName", "busybox", "true")
 	dockerCmd(c, "rename", "oldName", "newName")
 
-	out, _ := dockerCmd(c, "events", "--since=0", "-f", "container=newName", "--until="+strconv.Itoa(int(since)))
+	out, _ := dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container=newName", "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " rename\n") {
 		c.Fatalf("Missing 'rename' log event\n%s", out)
 	}
@@ -714,7 +717,7 @@ func (s *DockerSuite) TestEventsTop(c *check.C) {
 	dockerCmd(c, "top", cID)
 	dockerCmd(c, "stop", cID)
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "container="+cID, "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "container="+cID, "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, " top\n") {
 		c.Fatalf("Missing 'top' log event\n%s", out)
 	}
@@ -743,7 +746,7 @@ func (s *DockerRegistrySuite) TestEventsImageFilterPush(c *check.C) {
 	dockerCmd(c, "stop", cID)
 	dockerCmd(c, "push", repoName)
 
-	out, _ = dockerCmd(c, "events", "--since=0", "-f", "image="+repoName, "-f", "event=push", "--until="+strconv.Itoa(int(since)))
+	out, _ = dockerCmd(c, "events", "--since="+strconv.Itoa(int(since)), "-f", "image="+repoName, "-f", "event=push", "--until="+strconv.Itoa(int(daemonTime(c).Unix())))
 	if !strings.Contains(out, repoName+": push\n") {
 		c.Fatalf("Missing 'push' log event for image %s\n%s", repoName, out)
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/go-check/check"
 	"github.com/kr/pty"
 )
@@ -153,3 +154,50 @@ func (s *DockerSuite) TestEventsOOMDisableTrue(c *check.C) {
 		}
 	}
 }
+
+func (s *DockerSuite) TestEventsVolumeEvents(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+
+	dockerCmd(c, "volume", "create", "--name", "test-event-volume-local")
+	dockerCmd(c, "run", "--name", "test-volume-container", "--volume", "test-event-volume-local:/foo", "busybox", "true")
+	dockerCmd(c, "rm", "test-volume-container")
+	dockerCmd(c, "volume", "rm", "test-event-volume-local")
+
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=volume")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(len(events), checker.Equals, 4, check.Commentf(out))
+
+	c.Assert(events[0], checker.Contains, "volume create test-event-volume-local (driver=local)")
+	c.Assert(events[1], checker.Contains, "volume mount test-event-volume-local (container=")
+	c.Assert(events[1], checker.Contains, "destination=/foo, driver=local, read/write=true)")
+	c.Assert(events[2], checker.Contains, "volume unmount test-event-volume-local (container=")
+	c.Assert(events[3], checker.Contains, "volume destroy test-event-volume-local (driver=local)")
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "volume=test-event-volume-local", "--filter", "event=destroy")
+	events = strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(len(events), checker.Equals, 1, check.Commentf(out))
+	c.Assert(events[0], checker.Contains, "volume destroy test-event-volume-local")
+}
+
+func (s *DockerSuite) TestEventsNetworkEvents(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	since := daemonTime(c).Unix()
+
+	dockerCmd(c, "network", "create", "test-event-network-local")
+	out, _ := dockerCmd(c, "run", "--name", "test-network-container", "--net", "test-event-network-local", "-d", "busybox", "top")
+	containerID := strings.TrimSpace(out)
+	dockerCmd(c, "rm", "-f", "test-network-container")
+	dockerCmd(c, "network", "rm", "test-event-network-local")
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "network=test-event-network-local")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(len(events), checker.Equals, 4, check.Commentf(out))
+
+	c.Assert(events[0], checker.Contains, "network create ")
+	c.Assert(events[0], checker.Contains, "(name=test-event-network-local, type=bridge)")
+	c.Assert(events[1], checker.Contains, "network connect ")
+	c.Assert(events[1], checker.Contains, "(container="+containerID+", name=test-event-network-local, type=bridge)")
+	c.Assert(events[2], checker.Contains, "network disconnect ")
+	c.Assert(events[3], checker.Contains, "network destroy ")
+}
@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/go-check/check"
 )
 
@@ -56,3 +57,23 @@ func (s *DockerSuite) TestExportContainerWithOutputAndImportImage(c *check.C) {
 		c.Fatalf("output should have been an image id, got: %s", out)
 	}
 }
+
+// export a gzip compressed container and import it
+func (s *DockerSuite) TestExportGzipCompressionAndImportImage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	containerID := "testexportgzipcompressionandimportimage"
+
+	dockerCmd(c, "run", "--name", containerID, "busybox", "sh", "-c", "echo hello > /hello")
+	dockerCmd(c, "export", "--compression=gzip", "--output=testexp.tar.gz", containerID)
+	defer os.Remove("testexp.tar.gz")
+
+	out, _, err := runCommandWithOutput(exec.Command("gzip", "-t", "testexp.tar.gz"))
+	c.Assert(err, checker.IsNil, check.Commentf("expected a gzip archive: %s", out))
+
+	// import detects the compression of the archive
+	out, _ = dockerCmd(c, "import", "testexp.tar.gz", "repo/testexpgzip:v1")
+	c.Assert(strings.TrimSpace(out), checker.Not(checker.Equals), "")
+
+	out, _ = dockerCmd(c, "run", "--rm", "repo/testexpgzip:v1", "cat", "/hello")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "hello")
+}
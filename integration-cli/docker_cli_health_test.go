@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func waitForHealthStatus(c *check.C, name string, prev string, expected string) {
+	prev = prev + "\n"
+	expected = expected + "\n"
+	for {
+		out, _ := dockerCmd(c, "inspect", "--format={{.State.Health.Status}}", name)
+		if out == expected {
+			return
+		}
+		c.Check(out, checker.Equals, prev)
+		if out != prev {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func getHealth(c *check.C, name string) *types.Health {
+	out, _ := dockerCmd(c, "inspect", "--format={{json .State.Health}}", name)
+	var health types.Health
+	err := json.Unmarshal([]byte(out), &health)
+	c.Check(err, checker.Equals, nil)
+	return &health
+}
+
+func (s *DockerSuite) TestHealth(c *check.C) {
+	testRequires(c, DaemonIsLinux) // busybox doesn't work on Windows
+
+	imageName := "testhealth"
+	_, err := buildImage(imageName,
+		`FROM busybox
+		RUN echo OK > /status
+		CMD ["/bin/sleep", "120"]
+		STOPSIGNAL SIGKILL
+		HEALTHCHECK --interval=1s --timeout=30s \
+		  CMD cat /status`,
+		true)
+
+	c.Check(err, check.IsNil)
+
+	// No health status before starting
+	name := "test_health"
+	dockerCmd(c, "create", "--name", name, imageName)
+	out, _ := dockerCmd(c, "ps", "-a", "--format={{.Status}}")
+	c.Check(out, checker.Equals, "Created\n")
+
+	// Inspect the options
+	out, _ = dockerCmd(c, "inspect",
+		"--format=timeout={{.Config.Healthcheck.Timeout}} "+
+			"interval={{.Config.Healthcheck.Interval}} "+
+			"retries={{.Config.Healthcheck.Retries}} "+
+			"test={{.Config.Healthcheck.Test}}", name)
+	c.Check(out, checker.Equals, "timeout=30s interval=1s retries=0 test=[CMD-SHELL cat /status]\n")
+
+	// Start
+	dockerCmd(c, "start", name)
+	waitForHealthStatus(c, name, "starting", "healthy")
+
+	// The health filter only matches the exact status
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter=health=healthy")
+	id, err := inspectField(name, "Id")
+	c.Assert(err, check.IsNil)
+	c.Check(out, checker.Contains, id)
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter=health=unhealthy")
+	c.Check(out, checker.Not(checker.Contains), id)
+
+	// Make it fail
+	dockerCmd(c, "exec", name, "rm", "/status")
+	waitForHealthStatus(c, name, "healthy", "unhealthy")
+
+	// Inspect the status
+	out, _ = dockerCmd(c, "inspect", "--format={{.State.Health.Status}}", name)
+	c.Check(out, checker.Equals, "unhealthy\n")
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter=health=unhealthy")
+	c.Check(out, checker.Contains, id)
+
+	// Make it healthy again
+	dockerCmd(c, "exec", name, "touch", "/status")
+	waitForHealthStatus(c, name, "unhealthy", "healthy")
+
+	// Remove container
+	dockerCmd(c, "rm", "-f", name)
+
+	// Disable the check from the Dockerfile
+	buildImage("no_healthcheck",
+		`FROM testhealth
+		HEALTHCHECK NONE`, true)
+
+	out, _ = dockerCmd(c, "inspect", "--format={{.Config.Healthcheck.Test}}", "no_healthcheck")
+	c.Check(out, checker.Equals, "[NONE]\n")
+
+	// A container without a healthcheck matches the "none" filter
+	_, _ = dockerCmd(c, "run", "-d", "--name=fatal_healthcheck", "no_healthcheck")
+	out, _ = dockerCmd(c, "ps", "-q", "--no-trunc", "--filter=health=none")
+	fatalID, err := inspectField("fatal_healthcheck", "Id")
+	c.Assert(err, check.IsNil)
+	c.Check(out, checker.Contains, fatalID)
+	dockerCmd(c, "rm", "-f", "fatal_healthcheck")
+
+	// Note: if the interval is too small, it seems that Docker spends all its time running health
+	// checks and never gets around to killing it.
+	_, err = buildImage("test_fatal_healthcheck",
+		`FROM busybox
+		CMD ["/bin/sleep", "120"]
+		STOPSIGNAL SIGKILL
+		HEALTHCHECK --interval=1s --retries=1 CMD sh -c 'echo "Oops"; exit 1'`,
+		true)
+	c.Check(err, check.IsNil)
+	dockerCmd(c, "run", "-d", "--name=fatal_healthcheck", "test_fatal_healthcheck")
+	waitForHealthStatus(c, "fatal_healthcheck", "starting", "unhealthy")
+	failsStr, _ := dockerCmd(c, "inspect", "--format={{.State.Health.FailingStreak}}", "fatal_healthcheck")
+	fails, err := strconv.Atoi(strings.TrimSpace(failsStr))
+	c.Check(err, check.IsNil)
+	c.Check(fails >= 1, checker.Equals, true)
+	last := getHealth(c, "fatal_healthcheck").Log[0]
+	c.Check(last.ExitCode, checker.Equals, 1)
+	c.Check(last.Output, checker.Equals, "Oops")
+
+	// A health_status event is emitted when the status changes
+	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "container=fatal_healthcheck")
+	c.Check(out, checker.Contains, "health_status: unhealthy")
+	// The probes don't log exec events
+	c.Check(out, checker.Not(checker.Contains), "exec_create")
+	c.Check(out, checker.Not(checker.Contains), "exec_start")
+	dockerCmd(c, "rm", "-f", "fatal_healthcheck")
+
+	// Check that the timeout works
+	_, err = buildImage("test_healthcheck_timeout",
+		`FROM busybox
+		CMD ["/bin/sleep", "120"]
+		STOPSIGNAL SIGKILL
+		HEALTHCHECK --interval=1s --timeout=1s --retries=1 CMD sleep 10`,
+		true)
+	c.Check(err, check.IsNil)
+	dockerCmd(c, "run", "-d", "--name=test", "test_healthcheck_timeout")
+	waitForHealthStatus(c, "test", "starting", "unhealthy")
+	health := getHealth(c, "test")
+	c.Check(health.Log[0].ExitCode, checker.Equals, -1)
+	c.Check(health.Log[0].Output, checker.Contains, "Health check exceeded timeout")
+	// The probes are killed when they time out, so they don't pile up
+	time.Sleep(4 * time.Second)
+	out, _ = dockerCmd(c, "top", "test")
+	c.Check(strings.Count(out, "sleep 10") <= 1, checker.True, check.Commentf(out))
+	dockerCmd(c, "rm", "-f", "test")
+}
@@ -470,18 +470,128 @@ func (s *DockerDaemonSuite) TestDockerNetworkNoDiscoveryDefaultBridgeNetwork(c *
 	cid3 := strings.TrimSpace(out)
 	defer s.d.Cmd("stop", cid3)
 
-	// container1 etc/hosts file should contain an entry for the third container
+	// container1 resolves the third container through the embedded DNS
+	// server, its etc/hosts file is left unchanged
+	out, err = s.d.Cmd("exec", cid1, "ping", "-c", "1", cName)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
 	hostsPost, err = s.d.Cmd("exec", cid1, "cat", hostsFile)
 	c.Assert(err, checker.IsNil)
-	c.Assert(string(hostsPost), checker.Contains, cName,
-		check.Commentf("Container 1  %s file does not contain entries for named container %q: %s", hostsFile, cName, string(hostsPost)))
+	c.Assert(string(hosts), checker.Equals, string(hostsPost),
+		check.Commentf("Unexpected %s change on third container creation", hostsFile))
 
-	// on container3 disconnect, first container's etc/hosts should go back to original form
+	// on container3 disconnect, its name no longer resolves
 	out, err = s.d.Cmd("network", "disconnect", network, cid3)
 	c.Assert(err, check.IsNil, check.Commentf(out))
 
-	hostsPost, err = s.d.Cmd("exec", cid1, "cat", hostsFile)
+	out, err = s.d.Cmd("exec", cid1, "ping", "-c", "1", cName)
+	c.Assert(err, check.NotNil, check.Commentf(out))
+}
+
+func (s *DockerNetworkSuite) TestDockerNetworkEmbeddedDNS(c *check.C) {
+	dockerCmd(c, "network", "create", "dnsnet")
+	assertNwIsAvailable(c, "dnsnet")
+
+	dockerCmd(c, "run", "-d", "--name", "first", "--net", "dnsnet", "--network-alias", "db", "busybox", "top")
+	c.Assert(waitRun("first"), check.IsNil)
+	dockerCmd(c, "run", "-d", "--name", "second", "--net", "dnsnet", "busybox", "top")
+	c.Assert(waitRun("second"), check.IsNil)
+
+	// the resolv.conf file of the container points to the embedded DNS server
+	out, _ := dockerCmd(c, "exec", "second", "cat", "/etc/resolv.conf")
+	c.Assert(out, checker.Contains, "nameserver 127.0.0.11")
+	c.Assert(out, checker.Contains, "ndots:0")
+
+	// the container name, the network scoped name and the alias resolve
+	// without being written to the hosts file
+	for _, name := range []string{"first", "first.dnsnet", "db"} {
+		out, _ = dockerCmd(c, "exec", "second", "nslookup", name)
+		c.Assert(out, checker.Contains, "Name:", check.Commentf("%s does not resolve: %s", name, out))
+	}
+	out, _ = dockerCmd(c, "exec", "second", "cat", "/etc/hosts")
+	c.Assert(out, check.Not(checker.Contains), "first")
+
+	// the containers on the default bridge network keep using the hosts file
+	dockerCmd(c, "run", "-d", "--name", "third", "busybox", "top")
+	c.Assert(waitRun("third"), check.IsNil)
+	out, _ = dockerCmd(c, "exec", "third", "cat", "/etc/resolv.conf")
+	c.Assert(out, check.Not(checker.Contains), "127.0.0.11")
+}
+
+func (s *DockerNetworkSuite) TestDockerNetworkRunStaticIPAndAlias(c *check.C) {
+	dockerCmd(c, "network", "create", "--subnet=172.28.0.0/16", "n1")
+	assertNwIsAvailable(c, "n1")
+
+	dockerCmd(c, "run", "-d", "--name", "first", "--net", "n1", "--ip", "172.28.0.5", "--network-alias", "db", "busybox", "top")
+	c.Assert(waitRun("first"), check.IsNil)
+	ip, err := inspectField("first", `(index .NetworkSettings.Networks "n1").IPAddress`)
 	c.Assert(err, checker.IsNil)
-	c.Assert(string(hosts), checker.Equals, string(hostsPost),
-		check.Commentf("Unexpected %s content after disconnecting from second network", hostsFile))
+	c.Assert(ip, checker.Equals, "172.28.0.5")
+
+	// the alias of the first container resolves in the network
+	dockerCmd(c, "run", "-d", "--name", "second", "--net", "n1", "busybox", "top")
+	c.Assert(waitRun("second"), check.IsNil)
+	_, _, err = dockerCmdWithError("exec", "second", "ping", "-c", "1", "db")
+	c.Assert(err, check.IsNil)
+
+	// the address is kept across restarts
+	dockerCmd(c, "restart", "first")
+	c.Assert(waitRun("first"), check.IsNil)
+	ip, err = inspectField("first", `(index .NetworkSettings.Networks "n1").IPAddress`)
+	c.Assert(err, checker.IsNil)
+	c.Assert(ip, checker.Equals, "172.28.0.5")
+}
+
+func (s *DockerNetworkSuite) TestDockerNetworkRunStaticIPOnDefaultNetwork(c *check.C) {
+	out, _, err := dockerCmdWithError("run", "--ip", "172.17.0.5", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "User specified IP address is supported on user defined networks only")
+
+	out, _, err = dockerCmdWithError("run", "--network-alias", "db", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Network-scoped alias is supported only for containers in user defined networks")
+}
+
+func (s *DockerNetworkSuite) TestDockerNetworkCreateEndpointSettingsOfOtherNetwork(c *check.C) {
+	dockerCmd(c, "network", "create", "--subnet=172.30.0.0/16", "n3")
+	assertNwIsAvailable(c, "n3")
+
+	config := map[string]interface{}{
+		"Image":      "busybox",
+		"HostConfig": map[string]interface{}{"NetworkMode": "bridge"},
+		"NetworkingConfig": map[string]interface{}{
+			"EndpointsConfig": map[string]interface{}{
+				"n3": map[string]interface{}{
+					"IPAMConfig": map[string]interface{}{"IPv4Address": "172.30.0.5"},
+				},
+			},
+		},
+	}
+	status, body, err := sockRequest("POST", "/containers/create", config)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusBadRequest)
+	c.Assert(string(body), checker.Contains, "Endpoint settings given for network n3, but the container is created in network bridge")
+}
+
+func (s *DockerNetworkSuite) TestDockerNetworkConnectStaticIPAndAlias(c *check.C) {
+	dockerCmd(c, "network", "create", "--subnet=172.29.0.0/16", "n2")
+	assertNwIsAvailable(c, "n2")
+
+	dockerCmd(c, "run", "-d", "--name", "first", "busybox", "top")
+	c.Assert(waitRun("first"), check.IsNil)
+	dockerCmd(c, "network", "connect", "--ip", "172.29.0.9", "--alias", "web", "n2", "first")
+	ip, err := inspectField("first", `(index .NetworkSettings.Networks "n2").IPAddress`)
+	c.Assert(err, checker.IsNil)
+	c.Assert(ip, checker.Equals, "172.29.0.9")
+
+	dockerCmd(c, "run", "-d", "--name", "second", "--net", "n2", "busybox", "top")
+	c.Assert(waitRun("second"), check.IsNil)
+	_, _, err = dockerCmdWithError("exec", "second", "ping", "-c", "1", "web")
+	c.Assert(err, check.IsNil)
+
+	// an address outside of the subnets of the network is rejected
+	dockerCmd(c, "run", "-d", "--name", "third", "busybox", "top")
+	c.Assert(waitRun("third"), check.IsNil)
+	_, _, err = dockerCmdWithError("network", "connect", "--ip", "10.10.0.9", "n2", "third")
+	c.Assert(err, checker.NotNil)
 }
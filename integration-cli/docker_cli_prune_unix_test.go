@@ -0,0 +1,151 @@
+// +build !windows
+
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestPruneContainers(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "busybox", "top")
+	running := strings.TrimSpace(out)
+	out, _ = dockerCmd(c, "run", "-d", "busybox", "true")
+	stopped := strings.TrimSpace(out)
+	dockerCmd(c, "wait", stopped)
+
+	out, _ = dockerCmd(c, "system", "prune", "-f")
+	c.Assert(out, checker.Contains, "Deleted Containers:")
+	c.Assert(out, checker.Contains, stopped)
+	c.Assert(out, check.Not(checker.Contains), running)
+	c.Assert(out, checker.Contains, "Total reclaimed space:")
+
+	out, _ = dockerCmd(c, "ps", "-aq", "--no-trunc")
+	c.Assert(out, checker.Contains, running)
+	c.Assert(out, check.Not(checker.Contains), stopped)
+}
+
+func (s *DockerSuite) TestPruneContainersLabelFilter(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "prune-keep", "busybox", "true")
+	dockerCmd(c, "run", "--name", "prune-label", "--label", "prune=yes", "busybox", "true")
+
+	dockerCmd(c, "system", "prune", "-f", "--filter", "label=prune=yes")
+
+	out, _ := dockerCmd(c, "ps", "-a", "--format", "{{.Names}}")
+	c.Assert(out, checker.Contains, "prune-keep")
+	c.Assert(out, check.Not(checker.Contains), "prune-label")
+}
+
+func (s *DockerSuite) TestPruneContainersUntilFilter(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "prune-recent", "busybox", "true")
+
+	// The container was created less than an hour ago
+	dockerCmd(c, "system", "prune", "-f", "--filter", "until=1h")
+
+	out, _ := dockerCmd(c, "ps", "-a", "--format", "{{.Names}}")
+	c.Assert(out, checker.Contains, "prune-recent")
+}
+
+func (s *DockerSuite) TestPruneInvalidFilter(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("system", "prune", "-f", "--filter", "name=foo")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Invalid filter 'name'")
+}
+
+func (s *DockerSuite) TestPruneVolumes(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "volume", "create", "--name", "prune-unused")
+	dockerCmd(c, "volume", "create", "--name", "prune-used")
+	dockerCmd(c, "create", "-v", "prune-used:/foo", "busybox", "true")
+
+	out, _ := dockerCmd(c, "volume", "prune", "-f")
+	c.Assert(out, checker.Contains, "Deleted Volumes:\nprune-unused\n")
+	c.Assert(out, check.Not(checker.Contains), "prune-used")
+
+	out, _ = dockerCmd(c, "volume", "ls", "-q")
+	c.Assert(out, checker.Contains, "prune-used\n")
+	c.Assert(out, check.Not(checker.Contains), "prune-unused\n")
+}
+
+func (s *DockerSuite) TestPruneNetworks(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "network", "create", "prune-unused")
+	dockerCmd(c, "network", "create", "prune-used")
+	dockerCmd(c, "run", "-d", "--net", "prune-used", "busybox", "top")
+
+	out, _ := dockerCmd(c, "network", "prune", "-f")
+	c.Assert(out, checker.Contains, "Deleted Networks:\nprune-unused\n")
+	c.Assert(out, check.Not(checker.Contains), "prune-used")
+	c.Assert(out, check.Not(checker.Contains), "bridge")
+
+	out, _ = dockerCmd(c, "network", "ls")
+	c.Assert(out, checker.Contains, "prune-used")
+	c.Assert(out, checker.Contains, "bridge")
+	c.Assert(out, checker.Contains, "host")
+	c.Assert(out, checker.Contains, "none")
+	c.Assert(out, check.Not(checker.Contains), "prune-unused")
+}
+
+func (s *DockerSuite) TestPruneVolumesFilterRejected(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "volume", "create", "--name", "prune-unused")
+
+	for _, filter := range []string{`{"label":["foo=bar"]}`, `{"until":["1h"]}`} {
+		status, body, err := sockRequest("POST", "/volumes/prune?filters="+url.QueryEscape(filter), nil)
+		c.Assert(err, checker.IsNil)
+		c.Assert(status, checker.Equals, http.StatusBadRequest, check.Commentf("%s", body))
+	}
+
+	// system prune leaves the volumes alone when a filter is given
+	out, _ := dockerCmd(c, "system", "prune", "-f", "--filter", "until=1h")
+	c.Assert(out, check.Not(checker.Contains), "prune-unused")
+	out, _ = dockerCmd(c, "volume", "ls", "-q")
+	c.Assert(out, checker.Contains, "prune-unused\n")
+}
+
+func (s *DockerSuite) TestPruneNetworksFilterRejected(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "network", "create", "prune-unused")
+
+	for _, filter := range []string{`{"label":["foo=bar"]}`, `{"until":["1h"]}`} {
+		status, body, err := sockRequest("POST", "/networks/prune?filters="+url.QueryEscape(filter), nil)
+		c.Assert(err, checker.IsNil)
+		c.Assert(status, checker.Equals, http.StatusBadRequest, check.Commentf("%s", body))
+	}
+
+	// system prune leaves the networks alone when a filter is given
+	out, _ := dockerCmd(c, "system", "prune", "-f", "--filter", "label=prune=yes")
+	c.Assert(out, check.Not(checker.Contains), "prune-unused")
+	out, _ = dockerCmd(c, "network", "ls")
+	c.Assert(out, checker.Contains, "prune-unused")
+}
+
+func (s *DockerSuite) TestPruneDanglingImages(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "prune-dangling"
+	_, err := buildImage(name, "FROM busybox\nLABEL prune=first", true)
+	c.Assert(err, checker.IsNil)
+	dangling, err := inspectField(name, "Id")
+	c.Assert(err, checker.IsNil)
+	// Building the image again under the same tag leaves the first one dangling
+	_, err = buildImage(name, "FROM busybox\nLABEL prune=second", true)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "system", "prune", "-f")
+	c.Assert(out, checker.Contains, "Deleted Images:")
+	c.Assert(out, checker.Contains, "deleted: "+dangling)
+
+	out, _ = dockerCmd(c, "images", "-q", "--no-trunc")
+	c.Assert(out, check.Not(checker.Contains), dangling)
+	out, _ = dockerCmd(c, "images")
+	c.Assert(out, checker.Contains, name)
+	c.Assert(out, checker.Contains, "busybox")
+}
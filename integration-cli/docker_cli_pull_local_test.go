@@ -159,3 +159,42 @@ func (s *DockerRegistrySuite) TestConcurrentPullMultipleTags(c *check.C) {
 		c.Assert(strings.TrimSpace(out), check.Equals, "/bin/sh -c echo "+repo, check.Commentf("CMD did not contain /bin/sh -c echo %s; %s", repo, out))
 	}
 }
+
+// TestConcurrentPullSharedLayers pulls images sharing their base layers in
+// parallel with a daemon downloading a single layer at a time.
+func (s *DockerRegistrySuite) TestConcurrentPullSharedLayers(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	repoName := fmt.Sprintf("%v/dockercli/shared", privateRegistryURL)
+
+	repos := []string{}
+	for _, tag := range []string{"first", "second"} {
+		repo := fmt.Sprintf("%v:%v", repoName, tag)
+		_, err := buildImage(repo, fmt.Sprintf(`
+		    FROM busybox
+		    RUN echo %s > /tag
+		`, tag), true)
+		c.Assert(err, check.IsNil)
+		dockerCmd(c, "push", repo)
+		repos = append(repos, repo)
+	}
+
+	c.Assert(s.d.Start("--max-concurrent-downloads=1"), check.IsNil)
+
+	results := make(chan error)
+	for _, repo := range repos {
+		go func(repo string) {
+			_, err := s.d.Cmd("pull", repo)
+			results <- err
+		}(repo)
+	}
+	for range repos {
+		err := <-results
+		c.Assert(err, check.IsNil, check.Commentf("concurrent pull failed with error: %v", err))
+	}
+
+	for _, tag := range []string{"first", "second"} {
+		out, err := s.d.Cmd("run", "--rm", fmt.Sprintf("%v:%v", repoName, tag), "cat", "/tag")
+		c.Assert(err, check.IsNil, check.Commentf(out))
+		c.Assert(strings.TrimSpace(out), check.Equals, tag)
+	}
+}
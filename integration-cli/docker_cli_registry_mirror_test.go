@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerRegistrySuite) TestPullFromRegistryMirrors(c *check.C) {
+	testRequires(c, SameHostDaemon)
+	repoName := fmt.Sprintf("%v/dockercli/mirrored", privateRegistryURL)
+	dockerCmd(c, "tag", "busybox", repoName)
+	dockerCmd(c, "push", repoName)
+
+	// A pull-through cache which is unavailable
+	failing, err := newTestRegistry(c)
+	c.Assert(err, checker.IsNil)
+	defer failing.server.Close()
+	failing.registerHandler(".*", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	tmp, err := ioutil.TempDir("", "registry-config-")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(tmp)
+	config := filepath.Join(tmp, "registries.json")
+	configJSON := fmt.Sprintf(`{%q: {"mirrors": [{"url": "http://%s"}, {"url": "http://%s"}]}}`, privateRegistryURL, failing.hostport, privateRegistryURL)
+	c.Assert(ioutil.WriteFile(config, []byte(configJSON), 0600), checker.IsNil)
+	c.Assert(s.d.Start("--registry-config", config), checker.IsNil)
+
+	out, err := s.d.Cmd("pull", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, fmt.Sprintf("Error pulling from http://%s/, trying the next endpoint", failing.hostport))
+	c.Assert(out, checker.Contains, fmt.Sprintf("Pulled from mirror http://%s/", privateRegistryURL))
+
+	out, err = s.d.Cmd("inspect", repoName)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+}
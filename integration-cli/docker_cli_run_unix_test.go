@@ -279,6 +279,49 @@ func (s *DockerSuite) TestRunWithBlkioInvalidWeight(c *check.C) {
 	c.Assert(out, checker.Contains, expected)
 }
 
+func (s *DockerSuite) TestRunWithBlkioWeightDevice(c *check.C) {
+	testRequires(c, blkioWeightDevice)
+	out, _ := dockerCmd(c, "run", "--blkio-weight-device", "/dev/sda:300", "busybox", "cat", "/sys/fs/cgroup/blkio/blkio.weight_device")
+	c.Assert(out, checker.Contains, " 300")
+}
+
+func (s *DockerSuite) TestRunWithBlkioInvalidWeightDevice(c *check.C) {
+	testRequires(c, blkioWeightDevice)
+	out, _, err := dockerCmdWithError("run", "--blkio-weight-device", "/dev/sda:5", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "invalid weight for device")
+
+	out, _, err = dockerCmdWithError("run", "--blkio-weight-device", "sda:300", "busybox", "true")
+	c.Assert(err, check.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "bad format for device path")
+}
+
+func (s *DockerSuite) TestRunWithBlkioThrottleDevices(c *check.C) {
+	testRequires(c, blkioThrottleDevice)
+	out, _ := dockerCmd(c, "run", "--device-read-bps", "/dev/sda:1mb", "--device-write-iops", "/dev/sda:1000",
+		"busybox", "cat", "/sys/fs/cgroup/blkio/blkio.throttle.read_bps_device", "/sys/fs/cgroup/blkio/blkio.throttle.write_iops_device")
+	c.Assert(out, checker.Contains, " 1048576")
+	c.Assert(out, checker.Contains, " 1000")
+}
+
+func (s *DockerSuite) TestRunWithBlkioInvalidThrottleDevices(c *check.C) {
+	testRequires(c, blkioThrottleDevice)
+	for _, flag := range []string{"--device-read-bps", "--device-write-bps", "--device-read-iops", "--device-write-iops"} {
+		out, _, err := dockerCmdWithError("run", flag, "/dev/sda:-1", "busybox", "true")
+		c.Assert(err, check.NotNil, check.Commentf(out))
+		c.Assert(out, checker.Contains, "invalid rate for device")
+	}
+}
+
+func (s *DockerSuite) TestRunWithPidsLimit(c *check.C) {
+	testRequires(c, pidsLimit)
+	out, _ := dockerCmd(c, "run", "--pids-limit", "4", "busybox", "cat", "/sys/fs/cgroup/pids/pids.max")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "4")
+
+	out, _ = dockerCmd(c, "run", "--pids-limit", "-1", "busybox", "cat", "/sys/fs/cgroup/pids/pids.max")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "max")
+}
+
 func (s *DockerSuite) TestRunOOMExitCode(c *check.C) {
 	testRequires(c, oomControl)
 	errChan := make(chan error)
@@ -161,6 +161,40 @@ func (s *DockerSuite) TestSaveAndLoadRepoFlags(c *check.C) {
 	c.Assert(before, checker.Equals, after, check.Commentf("inspect is not the same after a save / load"))
 }
 
+func (s *DockerSuite) TestSaveGzipCompressionAndLoad(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "test-save-gzip-compression-and-load"
+	dockerCmd(c, "run", "--name", name, "busybox", "true")
+
+	repoName := "foobar-save-load-test-gzip"
+
+	deleteImages(repoName)
+	dockerCmd(c, "commit", name, repoName)
+
+	before, _ := dockerCmd(c, "inspect", repoName)
+
+	out, _, err := runCommandPipelineWithOutput(
+		exec.Command(dockerBinary, "save", "--compression=gzip", repoName),
+		exec.Command("gzip", "-t"))
+	c.Assert(err, checker.IsNil, check.Commentf("expected a gzip archive: %s, %v", out, err))
+
+	// load detects the compression of the archive
+	out, _, err = runCommandPipelineWithOutput(
+		exec.Command(dockerBinary, "save", "--compression=gzip", repoName),
+		exec.Command(dockerBinary, "load"))
+	c.Assert(err, checker.IsNil, check.Commentf("failed to save and load repo: %s, %v", out, err))
+
+	after, _ := dockerCmd(c, "inspect", repoName)
+	c.Assert(before, checker.Equals, after, check.Commentf("inspect is not the same after a save / load"))
+}
+
+func (s *DockerSuite) TestSaveInvalidCompression(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("save", "--compression=xz", "-o", "/dev/null", "busybox")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, `invalid compression "xz"`)
+}
+
 func (s *DockerSuite) TestSaveMultipleNames(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	repoName := "foobar-save-multi-name-test"
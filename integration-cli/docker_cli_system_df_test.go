@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestSystemDf(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "volume", "create", "--name", "df-volume")
+	dockerCmd(c, "run", "--name", "df-container", "busybox", "sh", "-c", "echo hello > /file")
+
+	out, _ := dockerCmd(c, "system", "df")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines, checker.HasLen, 4)
+	c.Assert(lines[0], checker.Contains, "RECLAIMABLE")
+	c.Assert(lines[1], checker.HasPrefix, "Images")
+	c.Assert(lines[2], checker.HasPrefix, "Containers")
+	c.Assert(lines[3], checker.HasPrefix, "Local Volumes")
+
+	out, _ = dockerCmd(c, "system", "df", "-v")
+	c.Assert(out, checker.Contains, "Images space usage:")
+	c.Assert(out, checker.Contains, "busybox")
+	c.Assert(out, checker.Contains, "df-container")
+	c.Assert(out, checker.Contains, "df-volume")
+}
+
+func (s *DockerSuite) TestSystemDfAPI(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "df-api", "busybox", "sh", "-c", "dd if=/dev/zero of=/file bs=1024 count=64")
+
+	status, body, err := sockRequest("GET", "/system/df", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var du types.DiskUsage
+	c.Assert(json.Unmarshal(body, &du), checker.IsNil)
+	c.Assert(du.LayersSize, checker.GreaterThan, int64(0))
+
+	var found bool
+	for _, ct := range du.Containers {
+		if ct.Names[0] == "/df-api" {
+			found = true
+			c.Assert(ct.Running, checker.False)
+			c.Assert(ct.SizeRw, checker.GreaterOrEqualThan, int64(64*1024))
+		}
+	}
+	c.Assert(found, checker.True, check.Commentf("container df-api not in %v", du.Containers))
+
+	for _, img := range du.Images {
+		c.Assert(img.Size+img.SharedSize, checker.Equals, img.VirtualSize)
+	}
+}
+
+func (s *DockerSuite) TestSystemDfAPICopiedFiles(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "df-cp", "busybox", "true")
+
+	// Cache the size of the stopped container
+	before := dfContainerSizeRw(c, "/df-cp")
+
+	tmpDir, err := ioutil.TempDir("", "df-cp")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(tmpDir)
+	tmpFile := filepath.Join(tmpDir, "file")
+	c.Assert(ioutil.WriteFile(tmpFile, make([]byte, 64*1024), 0644), checker.IsNil)
+	dockerCmd(c, "cp", tmpFile, "df-cp:/file")
+
+	c.Assert(dfContainerSizeRw(c, "/df-cp"), checker.GreaterOrEqualThan, before+64*1024)
+}
+
+// dfContainerSizeRw returns the size of the writable layer of the container
+// with the given name reported by the disk usage endpoint.
+func dfContainerSizeRw(c *check.C, name string) int64 {
+	status, body, err := sockRequest("GET", "/system/df", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var du types.DiskUsage
+	c.Assert(json.Unmarshal(body, &du), checker.IsNil)
+	for _, ct := range du.Containers {
+		if ct.Names[0] == name {
+			return ct.SizeRw
+		}
+	}
+	c.Fatalf("container %s not in %v", name, du.Containers)
+	return 0
+}
@@ -0,0 +1,93 @@
+// +build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/docker/runconfig"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestUpdateRunningContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux, NativeExecDriver)
+	testRequires(c, memoryLimitSupport)
+
+	name := "test-update-container"
+	dockerCmd(c, "run", "-d", "--name", name, "-m", "300M", "busybox", "top")
+	dockerCmd(c, "update", "-m", "500M", name)
+
+	memory, err := inspectField(name, "HostConfig.Memory")
+	c.Assert(err, checker.IsNil)
+	c.Assert(memory, checker.Equals, "524288000")
+
+	file := "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	out, _ := dockerCmd(c, "exec", name, "cat", file)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "524288000")
+}
+
+func (s *DockerSuite) TestUpdateStoppedContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux, cpuShare)
+
+	name := "test-update-stopped-container"
+	dockerCmd(c, "create", "--name", name, "--cpu-shares", "512", "busybox", "true")
+	dockerCmd(c, "update", "--cpu-shares", "1024", name)
+
+	shares, err := inspectField(name, "HostConfig.CpuShares")
+	c.Assert(err, checker.IsNil)
+	c.Assert(shares, checker.Equals, "1024")
+}
+
+func (s *DockerSuite) TestUpdateRequiresFlags(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	name := "test-update-no-flags"
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+
+	out, _, err := dockerCmdWithError("update", name)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "You must provide one or more flags")
+}
+
+func (s *DockerSuite) TestUpdateKernelMemoryOnRunningContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux, kernelMemorySupport)
+
+	name := "test-update-kernel-memory"
+	dockerCmd(c, "run", "-d", "--name", name, "busybox", "top")
+
+	out, _, err := dockerCmdWithError("update", "--kernel-memory", "50M", name)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "kernel memory can not be updated on a running container")
+}
+
+func (s *DockerSuite) TestUpdateEmitsEvent(c *check.C) {
+	testRequires(c, DaemonIsLinux, cpuShare)
+
+	name := "test-update-event"
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+	dockerCmd(c, "update", "--cpu-shares", "512", name)
+
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "container="+name)
+	c.Assert(out, checker.Contains, "update")
+}
+
+func (s *DockerSuite) TestAPIUpdateContainer(c *check.C) {
+	testRequires(c, DaemonIsLinux, cpuShare)
+
+	name := "test-api-update"
+	dockerCmd(c, "create", "--name", name, "busybox", "true")
+
+	status, body, err := sockRequest("POST", "/containers/"+name+"/update", runconfig.HostConfig{CPUShares: 768})
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, 200)
+
+	var resp struct{ Warnings []string }
+	c.Assert(json.Unmarshal(body, &resp), checker.IsNil)
+
+	shares, err := inspectField(name, "HostConfig.CpuShares")
+	c.Assert(err, checker.IsNil)
+	c.Assert(shares, checker.Equals, "768")
+}
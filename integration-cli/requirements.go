@@ -117,6 +117,13 @@ var (
 		},
 		"Test requires the native (libcontainer) exec driver.",
 	}
+	Criu = testRequirement{
+		func() bool {
+			_, err := exec.LookPath("criu")
+			return err == nil
+		},
+		"Test requires CRIU to checkpoint containers.",
+	}
 	NotOverlay = testRequirement{
 		func() bool {
 			cmd := exec.Command("grep", "^overlay / overlay", "/proc/mounts")
@@ -69,6 +69,25 @@ var (
 		},
 		"Test requires an environment that supports blkio weight.",
 	}
+	blkioWeightDevice = testRequirement{
+		func() bool {
+			return SysInfo.BlkioWeightDevice
+		},
+		"Test requires an environment that supports blkio weight_device.",
+	}
+	blkioThrottleDevice = testRequirement{
+		func() bool {
+			return SysInfo.BlkioReadBpsDevice && SysInfo.BlkioWriteBpsDevice &&
+				SysInfo.BlkioReadIOpsDevice && SysInfo.BlkioWriteIOpsDevice
+		},
+		"Test requires an environment that supports blkio throttle devices.",
+	}
+	pidsLimit = testRequirement{
+		func() bool {
+			return SysInfo.PidsLimit
+		},
+		"Test requires an environment that supports cgroup pids limit.",
+	}
 	cgroupCpuset = testRequirement{
 		func() bool {
 			return SysInfo.Cpuset
@@ -0,0 +1,55 @@
+package opts
+
+import "testing"
+
+func TestValidateThrottleBpsDevice(t *testing.T) {
+	valid := map[string]uint64{
+		"/dev/sda:1024": 1024,
+		"/dev/sda:1kb":  1024,
+		"/dev/sda:1mb":  1024 * 1024,
+		"/dev/sda:0":    0,
+	}
+	for val, rate := range valid {
+		td, err := ValidateThrottleBpsDevice(val)
+		if err != nil {
+			t.Fatalf("ValidateThrottleBpsDevice(`%s`) should succeed: %v", val, err)
+		}
+		if td.Path != "/dev/sda" || td.Rate != rate {
+			t.Fatalf("Expected /dev/sda:%d, got %s", rate, td)
+		}
+	}
+
+	invalid := []string{
+		"/dev/sda",
+		"sda:1mb",
+		"/dev/sda:-1",
+		"/dev/sda:1tbps",
+	}
+	for _, val := range invalid {
+		if _, err := ValidateThrottleBpsDevice(val); err == nil {
+			t.Fatalf("ValidateThrottleBpsDevice(`%s`) should have failed validation", val)
+		}
+	}
+}
+
+func TestValidateThrottleIOpsDevice(t *testing.T) {
+	td, err := ValidateThrottleIOpsDevice("/dev/sda:1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if td.Path != "/dev/sda" || td.Rate != 1000 {
+		t.Fatalf("Expected /dev/sda:1000, got %s", td)
+	}
+
+	invalid := []string{
+		"/dev/sda",
+		"sda:1000",
+		"/dev/sda:-1",
+		"/dev/sda:1kb",
+	}
+	for _, val := range invalid {
+		if _, err := ValidateThrottleIOpsDevice(val); err == nil {
+			t.Fatalf("ValidateThrottleIOpsDevice(`%s`) should have failed validation", val)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package opts
+
+import "testing"
+
+func TestValidateWeightDevice(t *testing.T) {
+	valid := map[string]uint16{
+		"/dev/sda:10":   10,
+		"/dev/sda:1000": 1000,
+		"/dev/sda:0":    0,
+	}
+	for val, weight := range valid {
+		wd, err := ValidateWeightDevice(val)
+		if err != nil {
+			t.Fatalf("ValidateWeightDevice(`%s`) should succeed: %v", val, err)
+		}
+		if wd.Path != "/dev/sda" || wd.Weight != weight {
+			t.Fatalf("Expected /dev/sda:%d, got %s", weight, wd)
+		}
+	}
+
+	invalid := []string{
+		"/dev/sda",
+		"sda:100",
+		"/dev/sda:9",
+		"/dev/sda:1001",
+		"/dev/sda:-1",
+		"/dev/sda:abc",
+	}
+	for _, val := range invalid {
+		if _, err := ValidateWeightDevice(val); err == nil {
+			t.Fatalf("ValidateWeightDevice(`%s`) should have failed validation", val)
+		}
+	}
+}
+
+func TestWeightdeviceOpt(t *testing.T) {
+	opt := NewWeightdeviceOpt(ValidateWeightDevice)
+	if err := opt.Set("/dev/sda:100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := opt.Set("/dev/sdb:5"); err == nil {
+		t.Fatal("Expected an error for an invalid weight")
+	}
+	if err := opt.Set("/dev/sdb:200"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[/dev/sda:100 /dev/sdb:200]"
+	if opt.String() != expected {
+		t.Fatalf("Expected %v, got %v", expected, opt.String())
+	}
+	if len(opt.GetList()) != 2 {
+		t.Fatalf("Expected 2 weight devices, got %d", len(opt.GetList()))
+	}
+}
@@ -0,0 +1,117 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/docker/docker/pkg/system"
+)
+
+// setupOverlayTestDir creates a directory with overlay whiteouts: a
+// character device with a 0/0 device number for the removed file "deleted",
+// and the opaque directory "opaque".
+func setupOverlayTestDir(t *testing.T, src string) {
+	if err := os.Mkdir(filepath.Join(src, "opaque"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := system.Lsetxattr(filepath.Join(src, "opaque"), "trusted.overlay.opaque", []byte("y"), 0); err != nil {
+		t.Skipf("Cannot set the trusted.overlay.opaque extended attribute: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "opaque", "file"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mknod(filepath.Join(src, "deleted"), syscall.S_IFCHR, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOverlayTarUntar(t *testing.T) {
+	src, err := ioutil.TempDir("", "docker-test-overlay-tar-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	setupOverlayTestDir(t, src)
+
+	options := &TarOptions{
+		Compression:    Uncompressed,
+		WhiteoutFormat: OverlayWhiteoutFormat,
+	}
+	archive, err := TarWithOptions(src, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	content, err := ioutil.ReadAll(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The archive has AUFS whiteout files
+	expected := map[string]byte{
+		".wh.deleted":                 tar.TypeReg,
+		"opaque/":                     tar.TypeDir,
+		"opaque/" + WhiteoutOpaqueDir: tar.TypeReg,
+		"opaque/file":                 tar.TypeReg,
+	}
+	tr := tar.NewReader(bytes.NewReader(content))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ, ok := expected[hdr.Name]
+		if !ok {
+			t.Fatalf("Unexpected file %s in the archive", hdr.Name)
+		}
+		if hdr.Typeflag != typ {
+			t.Fatalf("Expected %s to have the type %c, got %c", hdr.Name, typ, hdr.Typeflag)
+		}
+		delete(expected, hdr.Name)
+	}
+	if len(expected) > 0 {
+		t.Fatalf("Missing files in the archive: %v", expected)
+	}
+
+	dst, err := ioutil.TempDir("", "docker-test-overlay-tar-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	if err := Untar(bytes.NewReader(content), dst, options); err != nil {
+		t.Fatal(err)
+	}
+
+	// The whiteouts are unpacked as overlay whiteouts
+	fi, err := os.Lstat(filepath.Join(dst, "deleted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeCharDevice == 0 || fi.Sys().(*syscall.Stat_t).Rdev != 0 {
+		t.Fatalf("Expected a 0/0 character device for the removed file, got %v", fi.Mode())
+	}
+	opaque, err := system.Lgetxattr(filepath.Join(dst, "opaque"), "trusted.overlay.opaque")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opaque) != "y" {
+		t.Fatalf("Expected the directory to be opaque, got %q", opaque)
+	}
+	for _, name := range []string{".wh.deleted", filepath.Join("opaque", WhiteoutOpaqueDir)} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Fatalf("Expected the whiteout file %s not to be unpacked, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "opaque", "file")); err != nil {
+		t.Fatal(err)
+	}
+}
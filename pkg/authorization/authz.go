@@ -0,0 +1,194 @@
+package authorization
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+const maxBodySize = 1048576 // 1MB
+
+// NewCtx creates a new authZ context, used to store the authorization
+// information related to a single API request.
+//
+// A context provides two methods:
+//
+// AuthZRequest calls the authZ plugins with the request sent to the daemon
+// and the authenticated user, before the request is handled.
+//
+// AuthZResponse calls the authZ plugins with the request and the buffered
+// response of the daemon, before the response is sent to the client.
+//
+// If multiple authZ plugins are specified, the allow/deny decision is based
+// on ANDing all plugin results, in the order given on the daemon command line.
+func NewCtx(authZPlugins []Plugin, user, userAuthNMethod, requestMethod, requestURI string) *Ctx {
+	return &Ctx{
+		plugins:         authZPlugins,
+		user:            user,
+		userAuthNMethod: userAuthNMethod,
+		requestMethod:   requestMethod,
+		requestURI:      requestURI,
+	}
+}
+
+// Ctx stores a single request-response interaction context
+type Ctx struct {
+	user            string
+	userAuthNMethod string
+	requestMethod   string
+	requestURI      string
+	plugins         []Plugin
+	// authReq stores the cached request object for the current transaction
+	authReq *Request
+}
+
+// DeniedError is returned when an authorization plugin denies a request or
+// a response.
+type DeniedError struct {
+	// Plugin is the name of the plugin that denied the request
+	Plugin string
+	// Msg is the message returned by the plugin
+	Msg string
+}
+
+// Error returns a description of the denial.
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("authorization denied by plugin %s: %s", e.Plugin, e.Msg)
+}
+
+// PluginError is returned when an authorization plugin could not be
+// reached or reported an error.
+type PluginError struct {
+	// Plugin is the name of the failing plugin
+	Plugin string
+	// Err is the error message
+	Err string
+}
+
+// Error returns a description of the failure.
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin %s failed with error: %s", e.Plugin, e.Err)
+}
+
+// AuthZRequest authorizes the request to the docker daemon using authZ plugins
+func (ctx *Ctx) AuthZRequest(r *http.Request) error {
+	var body []byte
+	if sendBody(ctx.requestURI, r.Header) && r.ContentLength > 0 && r.ContentLength < maxBodySize {
+		var err error
+		body, r.Body, err = drainBody(r.Body)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.authReq = &Request{
+		User:            ctx.user,
+		UserAuthNMethod: ctx.userAuthNMethod,
+		RequestMethod:   ctx.requestMethod,
+		RequestURI:      ctx.requestURI,
+		RequestBody:     body,
+		RequestHeaders:  headers(r.Header),
+	}
+
+	for _, plugin := range ctx.plugins {
+		logrus.Debugf("AuthZ request using plugin %s", plugin.Name())
+
+		authRes, err := plugin.AuthZRequest(ctx.authReq)
+		if err != nil {
+			return &PluginError{Plugin: plugin.Name(), Err: err.Error()}
+		}
+
+		if authRes.Err != "" {
+			return &PluginError{Plugin: plugin.Name(), Err: authRes.Err}
+		}
+
+		if !authRes.Allow {
+			return &DeniedError{Plugin: plugin.Name(), Msg: authRes.Msg}
+		}
+	}
+
+	return nil
+}
+
+// AuthZResponse authorizes the response from the docker daemon using authZ
+// plugins, and writes it out to the client if all plugins allow it
+func (ctx *Ctx) AuthZResponse(rm ResponseModifier, r *http.Request) error {
+	ctx.authReq.ResponseStatusCode = rm.StatusCode()
+	ctx.authReq.ResponseHeaders = headers(rm.Header())
+
+	if sendBody(ctx.requestURI, rm.Header()) {
+		ctx.authReq.ResponseBody = rm.RawBody()
+	}
+
+	for _, plugin := range ctx.plugins {
+		logrus.Debugf("AuthZ response using plugin %s", plugin.Name())
+
+		authRes, err := plugin.AuthZResponse(ctx.authReq)
+		if err != nil {
+			return &PluginError{Plugin: plugin.Name(), Err: err.Error()}
+		}
+
+		if authRes.Err != "" {
+			return &PluginError{Plugin: plugin.Name(), Err: authRes.Err}
+		}
+
+		if !authRes.Allow {
+			return &DeniedError{Plugin: plugin.Name(), Msg: authRes.Msg}
+		}
+	}
+
+	return rm.FlushAll()
+}
+
+// drainBody reads up to maxBodySize bytes of the body into memory, and
+// returns them along with a new reader positioned at the start of the body.
+// See go sources /go/src/net/http/httputil/dump.go
+func drainBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	bufReader := bufio.NewReaderSize(body, maxBodySize)
+	newBody := ioutils.NewReadCloserWrapper(bufReader, func() error { return body.Close() })
+
+	data, err := bufReader.Peek(maxBodySize)
+	// Body size exceeds max body size
+	if err == nil {
+		logrus.Warnf("Request body is larger than: '%d' skipping body", maxBodySize)
+		return nil, newBody, nil
+	}
+	// Body size is less than maximum size
+	if err == io.EOF {
+		return data, newBody, nil
+	}
+	// Unknown error
+	return nil, newBody, err
+}
+
+// sendBody returns true when request/response body should be sent to AuthZPlugin
+func sendBody(url string, header http.Header) bool {
+	// Skip body for auth endpoint
+	if strings.HasSuffix(url, "/auth") {
+		return false
+	}
+
+	// body is sent only for text or json messages
+	v := header.Get("Content-Type")
+	return strings.HasPrefix(v, "application/json")
+}
+
+// headers returns flatten version of the http headers excluding authorization
+func headers(header http.Header) map[string]string {
+	v := make(map[string]string, 0)
+	for k, values := range header {
+		// Skip authorization headers
+		if strings.EqualFold(k, "Authorization") || strings.EqualFold(k, "X-Registry-Config") || strings.EqualFold(k, "X-Registry-Auth") {
+			continue
+		}
+		for _, val := range values {
+			v[k] = val
+		}
+	}
+	return v
+}
@@ -0,0 +1,216 @@
+package authorization
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakePlugin records the requests it receives and answers with fixed
+// responses.
+type fakePlugin struct {
+	name     string
+	reqRes   Response
+	resRes   Response
+	requests []Request
+}
+
+func (p *fakePlugin) Name() string {
+	return p.name
+}
+
+func (p *fakePlugin) AuthZRequest(r *Request) (*Response, error) {
+	p.requests = append(p.requests, *r)
+	return &p.reqRes, nil
+}
+
+func (p *fakePlugin) AuthZResponse(r *Request) (*Response, error) {
+	p.requests = append(p.requests, *r)
+	return &p.resRes, nil
+}
+
+func TestAuthZRequestAllow(t *testing.T) {
+	plugin := &fakePlugin{name: "allow", reqRes: Response{Allow: true}}
+	body := `{"Image":"busybox"}`
+	r, err := http.NewRequest("POST", "/containers/create", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Registry-Auth", "secret")
+
+	ctx := NewCtx([]Plugin{plugin}, "user", "TLS", r.Method, r.URL.String())
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plugin.requests) != 1 {
+		t.Fatalf("Expected 1 request to the plugin, got %d", len(plugin.requests))
+	}
+	req := plugin.requests[0]
+	if req.User != "user" || req.UserAuthNMethod != "TLS" {
+		t.Fatalf("Unexpected user %q (%q)", req.User, req.UserAuthNMethod)
+	}
+	if req.RequestMethod != "POST" || req.RequestURI != "/containers/create" {
+		t.Fatalf("Unexpected request %s %s", req.RequestMethod, req.RequestURI)
+	}
+	if string(req.RequestBody) != body {
+		t.Fatalf("Expected body %q, got %q", body, req.RequestBody)
+	}
+	if _, ok := req.RequestHeaders["X-Registry-Auth"]; ok {
+		t.Fatal("Expected registry credentials to be stripped from the headers")
+	}
+
+	// The request body must still be readable by the handler
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != body {
+		t.Fatalf("Expected drained body %q, got %q", body, b)
+	}
+}
+
+func TestAuthZRequestDeny(t *testing.T) {
+	allow := &fakePlugin{name: "allow", reqRes: Response{Allow: true}}
+	deny := &fakePlugin{name: "deny", reqRes: Response{Allow: false, Msg: "no way"}}
+	last := &fakePlugin{name: "last", reqRes: Response{Allow: true}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{allow, deny, last}, "", "", r.Method, r.URL.String())
+	err = ctx.AuthZRequest(r)
+	denied, ok := err.(*DeniedError)
+	if !ok {
+		t.Fatalf("Expected a DeniedError, got %v", err)
+	}
+	if denied.Plugin != "deny" || denied.Msg != "no way" {
+		t.Fatalf("Unexpected denial %v", denied)
+	}
+	if len(last.requests) != 0 {
+		t.Fatal("Expected plugins after a denial not to be consulted")
+	}
+}
+
+func TestAuthZRequestPluginError(t *testing.T) {
+	plugin := &fakePlugin{name: "broken", reqRes: Response{Err: "boom"}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{plugin}, "", "", r.Method, r.URL.String())
+	if _, ok := ctx.AuthZRequest(r).(*PluginError); !ok {
+		t.Fatal("Expected a PluginError")
+	}
+}
+
+func TestAuthZResponse(t *testing.T) {
+	plugin := &fakePlugin{name: "plugin", reqRes: Response{Allow: true}, resRes: Response{Allow: true}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{plugin}, "", "", r.Method, r.URL.String())
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	rm.Header().Set("Content-Type", "application/json")
+	rm.WriteHeader(http.StatusCreated)
+	rm.Write([]byte(`{"ID":"abc"}`))
+
+	if recorder.Body.Len() != 0 {
+		t.Fatal("Expected the response to be buffered")
+	}
+
+	if err := ctx.AuthZResponse(rm, r); err != nil {
+		t.Fatal(err)
+	}
+
+	req := plugin.requests[1]
+	if req.ResponseStatusCode != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, req.ResponseStatusCode)
+	}
+	if string(req.ResponseBody) != `{"ID":"abc"}` {
+		t.Fatalf("Unexpected response body %q", req.ResponseBody)
+	}
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != `{"ID":"abc"}` {
+		t.Fatalf("Unexpected response %d %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatal("Expected the headers to be flushed")
+	}
+}
+
+func TestAuthZResponseDeny(t *testing.T) {
+	plugin := &fakePlugin{name: "plugin", reqRes: Response{Allow: true}, resRes: Response{Msg: "hidden"}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{plugin}, "", "", r.Method, r.URL.String())
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	rm.Write([]byte("secret"))
+
+	if _, ok := ctx.AuthZResponse(rm, r).(*DeniedError); !ok {
+		t.Fatal("Expected a DeniedError")
+	}
+	if recorder.Body.Len() != 0 {
+		t.Fatal("Expected a denied response not to be sent")
+	}
+}
+
+func TestResponseModifierFlush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	rm.Write([]byte("first"))
+	if rm.Streamed() {
+		t.Fatal("Expected the response not to be streamed before a flush")
+	}
+
+	rm.Flush()
+	rm.Write([]byte("second"))
+
+	if !rm.Streamed() {
+		t.Fatal("Expected the response to be streamed after a flush")
+	}
+	if !recorder.Flushed {
+		t.Fatal("Expected the underlying writer to be flushed")
+	}
+	if recorder.Body.String() != "firstsecond" {
+		t.Fatalf("Unexpected body %q", recorder.Body.String())
+	}
+}
+
+func TestDrainBody(t *testing.T) {
+	large := bytes.Repeat([]byte("a"), maxBodySize+1)
+	data, body, err := drainBody(ioutil.NopCloser(bytes.NewReader(large)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		t.Fatal("Expected no data for a body larger than the maximum size")
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != len(large) {
+		t.Fatalf("Expected %d bytes, got %d", len(large), len(b))
+	}
+}
@@ -0,0 +1,140 @@
+package authorization
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseModifier allows authorization plugins to read the response sent by
+// the daemon before it is written back to the client.
+type ResponseModifier interface {
+	http.ResponseWriter
+	http.Flusher
+	http.CloseNotifier
+
+	// RawBody returns the current body of the response
+	RawBody() []byte
+
+	// StatusCode returns the current status code of the response
+	StatusCode() int
+
+	// Streamed returns whether the response was hijacked or flushed, i.e.
+	// whether (part of) it has already been sent to the client
+	Streamed() bool
+
+	// FlushAll writes the buffered headers, status code and body to the
+	// underlying writer
+	FlushAll() error
+}
+
+// NewResponseModifier creates a wrapper to an http.ResponseWriter that
+// buffers the response until FlushAll is called.
+func NewResponseModifier(rw http.ResponseWriter) ResponseModifier {
+	return &responseModifier{rw: rw, header: make(http.Header)}
+}
+
+// responseModifier buffers the response of the daemon so that it can be
+// inspected by the authorization plugins. Once the handler flushes or
+// hijacks the connection (streaming endpoints such as attach, logs and
+// events) the buffer is written out and the writer becomes a passthrough.
+type responseModifier struct {
+	// rw is the original http.ResponseWriter
+	rw http.ResponseWriter
+	// body holds the buffered response body
+	body []byte
+	// statusCode holds the response status code
+	statusCode int
+	// header holds the response headers
+	header http.Header
+	// streamed indicates the response has been hijacked or flushed
+	streamed bool
+}
+
+// WriteHeader stores the http status code
+func (rm *responseModifier) WriteHeader(s int) {
+	if rm.streamed {
+		rm.rw.WriteHeader(s)
+		return
+	}
+	rm.statusCode = s
+}
+
+// Header returns the internal http header
+func (rm *responseModifier) Header() http.Header {
+	if rm.streamed {
+		return rm.rw.Header()
+	}
+	return rm.header
+}
+
+// Write stores the byte array inside content
+func (rm *responseModifier) Write(b []byte) (int, error) {
+	if rm.streamed {
+		return rm.rw.Write(b)
+	}
+	rm.body = append(rm.body, b...)
+	return len(b), nil
+}
+
+// RawBody returns the response body
+func (rm *responseModifier) RawBody() []byte {
+	return rm.body
+}
+
+// StatusCode returns the response status code, defaulting to 200
+func (rm *responseModifier) StatusCode() int {
+	if rm.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rm.statusCode
+}
+
+// Streamed returns whether the response is no longer buffered
+func (rm *responseModifier) Streamed() bool {
+	return rm.streamed
+}
+
+// Hijack returns the internal connection of the wrapped http.ResponseWriter
+func (rm *responseModifier) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rm.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Internal response writer doesn't support the Hijacker interface")
+	}
+	rm.streamed = true
+	return hijacker.Hijack()
+}
+
+// CloseNotify uses the internal close notify API of the wrapped http.ResponseWriter
+func (rm *responseModifier) CloseNotify() <-chan bool {
+	if notifier, ok := rm.rw.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return nil
+}
+
+// Flush writes out any buffered data and switches to passthrough mode, so
+// that streaming responses reach the client as they are produced.
+func (rm *responseModifier) Flush() {
+	if !rm.streamed {
+		if err := rm.FlushAll(); err != nil {
+			return
+		}
+		rm.streamed = true
+	}
+	if flusher, ok := rm.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// FlushAll flushes all data to the HTTP response
+func (rm *responseModifier) FlushAll() error {
+	for k, v := range rm.header {
+		rm.rw.Header()[k] = v
+	}
+	rm.rw.WriteHeader(rm.StatusCode())
+	_, err := rm.rw.Write(rm.body)
+	rm.body = nil
+	return err
+}
@@ -216,3 +216,37 @@ func TestArgsMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestArgsExactMatch(t *testing.T) {
+	source := "healthy"
+	matches := map[*Args]string{
+		&Args{}: "field",
+		&Args{
+			"health": []string{"healthy"},
+		}: "health",
+		&Args{
+			"health": []string{"unhealthy", "healthy"},
+		}: "health",
+	}
+	differs := map[*Args]string{
+		&Args{
+			"health": []string{"unhealthy"},
+		}: "health",
+		&Args{
+			"health": []string{"heal(.*)"},
+		}: "health",
+		&Args{
+			"health": []string{"health"},
+		}: "health",
+	}
+	for args, field := range matches {
+		if args.ExactMatch(field, source) != true {
+			t.Fatalf("Expected true for %v on %v, got false", source, args)
+		}
+	}
+	for args, field := range differs {
+		if args.ExactMatch(field, source) != false {
+			t.Fatalf("Expected false for %v on %v, got true", source, args)
+		}
+	}
+}
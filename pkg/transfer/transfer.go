@@ -0,0 +1,198 @@
+// Package transfer runs transfers, such as the downloads and uploads of image
+// layers, which are shared by all the operations requesting them.
+package transfer
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/pkg/broadcaster"
+	"golang.org/x/net/context"
+)
+
+var errReleased = errors.New("transfer watcher released")
+
+// DoFunc performs a transfer and writes its progress to progress. The
+// context is cancelled when all the watchers of the transfer release it
+// before it completes. If the result implements io.Closer, it is closed once
+// the transfer completed and all its watchers released it.
+type DoFunc func(ctx context.Context, progress io.Writer) (interface{}, error)
+
+// Manager runs transfers identified by a key, such as the digest of a layer.
+// A transfer requested while another one with the same key is in progress is
+// shared with it instead of being run again.
+type Manager struct {
+	mu        sync.Mutex
+	transfers map[string]*transfer
+	// slots limits the number of transfers running at the same time. It is
+	// nil if there is no limit.
+	slots chan struct{}
+}
+
+// NewManager returns a Manager running at most concurrencyLimit transfers at
+// the same time. A limit of 0 or less means no limit.
+func NewManager(concurrencyLimit int) *Manager {
+	m := &Manager{
+		transfers: make(map[string]*transfer),
+	}
+	if concurrencyLimit > 0 {
+		m.slots = make(chan struct{}, concurrencyLimit)
+	}
+	return m
+}
+
+// transfer is a transfer shared by its watchers.
+type transfer struct {
+	key         string
+	broadcaster *broadcaster.Buffered
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+
+	// watchers, result and err are protected by the mutex of the manager.
+	watchers int
+	result   interface{}
+	err      error
+}
+
+// Transfer starts the transfer of key with do, or joins the transfer of key
+// in progress. The progress of the transfer is written to out until it
+// completes or the returned watcher is released. The watcher must always be
+// released.
+func (m *Manager) Transfer(key string, do DoFunc, out io.Writer) *Watcher {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.transfers[key]
+	if !exists {
+		ctx, cancel := context.WithCancel(context.Background())
+		t = &transfer{
+			key:         key,
+			broadcaster: broadcaster.NewBuffered(),
+			ctx:         ctx,
+			cancel:      cancel,
+			done:        make(chan struct{}),
+		}
+		m.transfers[key] = t
+		go m.run(t, do)
+	}
+	t.watchers++
+
+	w := &Watcher{
+		manager: m,
+		t:       t,
+		out:     &watcherWriter{out: out},
+	}
+	// The progress of a completed transfer is not replayed
+	t.broadcaster.Add(w.out)
+	return w
+}
+
+// run runs a transfer once a slot is available.
+func (m *Manager) run(t *transfer, do DoFunc) {
+	var (
+		result interface{}
+		err    error
+	)
+	if m.slots != nil {
+		select {
+		case m.slots <- struct{}{}:
+			result, err = do(t.ctx, t.broadcaster)
+			<-m.slots
+		case <-t.ctx.Done():
+			err = t.ctx.Err()
+		}
+	} else {
+		result, err = do(t.ctx, t.broadcaster)
+	}
+
+	// Wait for the watchers to receive the whole progress of the transfer
+	t.broadcaster.CloseWithError(err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.result, t.err = result, err
+	close(t.done)
+	if t.watchers == 0 {
+		m.remove(t)
+	}
+}
+
+// remove removes a transfer which has no more watchers, cancelling it if it
+// is still in progress. It must be called with the mutex of the manager
+// held.
+func (m *Manager) remove(t *transfer) {
+	if m.transfers[t.key] == t {
+		delete(m.transfers, t.key)
+	}
+	select {
+	case <-t.done:
+		if closer, ok := t.result.(io.Closer); ok {
+			closer.Close()
+		}
+	default:
+		t.cancel()
+	}
+}
+
+// Watcher is the handle of an operation on a transfer it requested.
+type Watcher struct {
+	manager *Manager
+	t       *transfer
+	out     *watcherWriter
+	once    sync.Once
+}
+
+// Done returns a channel which is closed when the transfer completes.
+func (w *Watcher) Done() <-chan struct{} {
+	return w.t.done
+}
+
+// Result waits for the transfer to complete and returns its result.
+func (w *Watcher) Result() (interface{}, error) {
+	<-w.t.done
+	w.manager.mu.Lock()
+	defer w.manager.mu.Unlock()
+	return w.t.result, w.t.err
+}
+
+// Release stops writing the progress of the transfer to the output of the
+// watcher. The transfer is cancelled if it is still in progress and it was
+// its last watcher.
+func (w *Watcher) Release() {
+	w.once.Do(func() {
+		w.out.release()
+
+		w.manager.mu.Lock()
+		defer w.manager.mu.Unlock()
+		w.t.watchers--
+		if w.t.watchers == 0 {
+			w.manager.remove(w.t)
+		}
+	})
+}
+
+// watcherWriter writes the progress of a transfer to the output of a
+// watcher until it is released.
+type watcherWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *watcherWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.out == nil {
+		return 0, errReleased
+	}
+	return w.out.Write(p)
+}
+
+// release waits for the write in progress, if any, and discards the
+// following ones.
+func (w *watcherWriter) release() {
+	w.mu.Lock()
+	w.out = nil
+	w.mu.Unlock()
+}
@@ -0,0 +1,192 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type closeRecorder struct {
+	closed chan struct{}
+}
+
+func (r *closeRecorder) Close() error {
+	close(r.closed)
+	return nil
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTransferSharedByKey(t *testing.T) {
+	m := NewManager(0)
+	start := make(chan struct{})
+	result := &closeRecorder{closed: make(chan struct{})}
+	var runs int
+	do := func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		runs++
+		<-start
+		progress.Write([]byte("progress"))
+		return result, nil
+	}
+
+	var outs [2]syncBuffer
+	w1 := m.Transfer("key", do, &outs[0])
+	w2 := m.Transfer("key", do, &outs[1])
+	close(start)
+
+	for i, w := range []*Watcher{w1, w2} {
+		r, err := w.Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != result {
+			t.Fatalf("Expected the result of the shared transfer, got %v", r)
+		}
+		if outs[i].String() != "progress" {
+			t.Fatalf("Expected the progress of the transfer, got %q", outs[i].String())
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("Expected the transfer to run once, got %d", runs)
+	}
+
+	// The result is closed once both watchers released it
+	w1.Release()
+	select {
+	case <-result.closed:
+		t.Fatal("Expected the result to be kept for the second watcher")
+	default:
+	}
+	w2.Release()
+	select {
+	case <-result.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the result to be closed")
+	}
+}
+
+func TestTransferCancelledWhenReleased(t *testing.T) {
+	m := NewManager(0)
+	cancelled := make(chan struct{})
+	do := func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+
+	w1 := m.Transfer("key", do, nil)
+	w2 := m.Transfer("key", do, nil)
+	w1.Release()
+	select {
+	case <-cancelled:
+		t.Fatal("Expected the transfer to continue for the second watcher")
+	case <-time.After(100 * time.Millisecond):
+	}
+	w2.Release()
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the transfer to be cancelled")
+	}
+	if _, err := w2.Result(); err != context.Canceled {
+		t.Fatalf("Expected the transfer to be cancelled, got %v", err)
+	}
+
+	// A new transfer is started for the key
+	w3 := m.Transfer("key", func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		return "new", nil
+	}, nil)
+	defer w3.Release()
+	if r, err := w3.Result(); err != nil || r != "new" {
+		t.Fatalf("Expected a new transfer, got %v (%v)", r, err)
+	}
+}
+
+func TestTransferConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	m := NewManager(limit)
+
+	var (
+		mu      sync.Mutex
+		running int
+		max     int
+	)
+	do := func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	}
+
+	var watchers []*Watcher
+	for i := 0; i < 6; i++ {
+		watchers = append(watchers, m.Transfer(fmt.Sprintf("key%d", i), do, nil))
+	}
+	for _, w := range watchers {
+		if _, err := w.Result(); err != nil {
+			t.Fatal(err)
+		}
+		w.Release()
+	}
+	if max != limit {
+		t.Fatalf("Expected at most %d transfers at the same time, got %d", limit, max)
+	}
+}
+
+func TestTransferReleasedWatcherOutput(t *testing.T) {
+	m := NewManager(0)
+	release := make(chan struct{})
+	do := func(ctx context.Context, progress io.Writer) (interface{}, error) {
+		progress.Write([]byte("before"))
+		<-release
+		progress.Write([]byte("after"))
+		return nil, nil
+	}
+
+	var out1, out2 syncBuffer
+	w1 := m.Transfer("key", do, &out1)
+	w2 := m.Transfer("key", do, &out2)
+	defer w2.Release()
+	for out1.String() == "" {
+		time.Sleep(10 * time.Millisecond)
+	}
+	w1.Release()
+	close(release)
+
+	if _, err := w2.Result(); err != nil {
+		t.Fatal(err)
+	}
+	if out1.String() != "before" {
+		t.Fatalf("Expected no progress after the release, got %q", out1.String())
+	}
+	if out2.String() != "beforeafter" {
+		t.Fatalf("Expected the whole progress, got %q", out2.String())
+	}
+}
@@ -18,6 +18,7 @@ import (
 type Options struct {
 	Mirrors            opts.ListOpts
 	InsecureRegistries opts.ListOpts
+	Registries         RegistriesOpt
 }
 
 const (
@@ -57,6 +58,7 @@ func (options *Options) InstallFlags(cmd *flag.FlagSet, usageFn func(string) str
 	cmd.Var(&options.Mirrors, []string{"-registry-mirror"}, usageFn("Preferred Docker registry mirror"))
 	options.InsecureRegistries = opts.NewListOpts(ValidateIndexName)
 	cmd.Var(&options.InsecureRegistries, []string{"-insecure-registry"}, usageFn("Enable insecure registry communication"))
+	cmd.Var(&options.Registries, []string{"-registry-config"}, usageFn("Registry configuration file with the mirrors of each registry"))
 	cmd.BoolVar(&V2Only, []string{"-disable-legacy-registry"}, false, "Do not contact legacy registries")
 }
 
@@ -82,6 +84,9 @@ type ServiceConfig struct {
 	InsecureRegistryCIDRs []*netIPNet           `json:"InsecureRegistryCIDRs"`
 	IndexConfigs          map[string]*IndexInfo `json:"IndexConfigs"`
 	Mirrors               []string
+	// Registries holds the configuration of the registries read from the
+	// registry configuration file, indexed by registry name.
+	Registries map[string]RegistryConfig `json:"-"`
 }
 
 // NewServiceConfig returns a new instance of ServiceConfig
@@ -105,7 +110,8 @@ func NewServiceConfig(options *Options) *ServiceConfig {
 		IndexConfigs:          make(map[string]*IndexInfo, 0),
 		// Hack: Bypass setting the mirrors to IndexConfigs since they are going away
 		// and Mirrors are only for the official registry anyways.
-		Mirrors: options.Mirrors.GetAll(),
+		Mirrors:    options.Mirrors.GetAll(),
+		Registries: options.Registries.Registries,
 	}
 	// Split --insecure-registry into CIDR and registry-specific settings.
 	for _, r := range options.InsecureRegistries.GetAll() {
@@ -125,10 +131,27 @@ func NewServiceConfig(options *Options) *ServiceConfig {
 		}
 	}
 
+	// Configure the mirrors of the private registries of the registry
+	// configuration file.
+	for name, registryConfig := range config.Registries {
+		if name == IndexName {
+			continue
+		}
+		index, ok := config.IndexConfigs[name]
+		if !ok {
+			index = &IndexInfo{
+				Name:   name,
+				Secure: config.isSecureIndex(name),
+			}
+			config.IndexConfigs[name] = index
+		}
+		index.Mirrors = registryConfig.mirrorURLs()
+	}
+
 	// Configure public registry.
 	config.IndexConfigs[IndexName] = &IndexInfo{
 		Name:     IndexName,
-		Mirrors:  config.Mirrors,
+		Mirrors:  append(append([]string{}, config.Mirrors...), config.Registries[IndexName].mirrorURLs()...),
 		Secure:   true,
 		Official: true,
 	}
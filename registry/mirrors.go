@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+)
+
+// RegistryConfig is the configuration of a registry in the registry
+// configuration file of the daemon.
+type RegistryConfig struct {
+	// Mirrors are the mirrors of the registry, in order of preference. The
+	// images are pulled from the registry itself only if they cannot be
+	// pulled from any of its mirrors.
+	Mirrors []MirrorConfig `json:"mirrors"`
+}
+
+// MirrorConfig is a mirror of a registry, and the settings to communicate
+// with it.
+type MirrorConfig struct {
+	// URL is the URL of the mirror, such as "https://mirror.example.com".
+	URL string `json:"url"`
+	// Insecure allows HTTPS with a certificate from an unknown CA.
+	Insecure bool `json:"insecure"`
+	// CAFile, CertFile and KeyFile are the CA certificate trusted for the
+	// mirror, and the client certificate and key presented to it. They
+	// default to the certificates of the certs.d directory of the mirror.
+	CAFile   string `json:"ca"`
+	CertFile string `json:"cert"`
+	KeyFile  string `json:"key"`
+}
+
+// RegistriesOpt is the value of the --registry-config flag of the daemon,
+// which reads the configuration of the registries from a JSON file keyed by
+// registry name.
+type RegistriesOpt struct {
+	path       string
+	Registries map[string]RegistryConfig
+}
+
+// Set reads the registry configuration file at path. An empty path clears
+// the configuration.
+func (o *RegistriesOpt) Set(path string) error {
+	if path == "" {
+		o.path, o.Registries = "", nil
+		return nil
+	}
+	registries, err := LoadRegistryConfig(path)
+	if err != nil {
+		return err
+	}
+	o.path = path
+	o.Registries = registries
+	return nil
+}
+
+// String returns the path of the registry configuration file.
+func (o *RegistriesOpt) String() string {
+	return o.path
+}
+
+// Path returns the path of the registry configuration file.
+func (o *RegistriesOpt) Path() string {
+	return o.path
+}
+
+// LoadRegistryConfig reads and validates a registry configuration file.
+func LoadRegistryConfig(path string) (map[string]RegistryConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var raw map[string]RegistryConfig
+	if err := json.NewDecoder(f).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid registry configuration %s: %v", path, err)
+	}
+
+	registries := make(map[string]RegistryConfig, len(raw))
+	for name, config := range raw {
+		indexName, err := ValidateIndexName(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry configuration %s: %v", path, err)
+		}
+		for i, mirror := range config.Mirrors {
+			if config.Mirrors[i].URL, err = ValidateMirror(mirror.URL); err != nil {
+				return nil, fmt.Errorf("invalid mirror %s of %s in registry configuration %s: %v", mirror.URL, name, path, err)
+			}
+			if (mirror.CertFile == "") != (mirror.KeyFile == "") {
+				return nil, fmt.Errorf("invalid mirror %s of %s in registry configuration %s: both a certificate and a key are required", mirror.URL, name, path)
+			}
+			// Read the certificates now to report the errors early
+			if _, err := mirror.tlsConfig(emptyServiceConfig); err != nil {
+				return nil, fmt.Errorf("invalid mirror %s of %s in registry configuration %s: %v", mirror.URL, name, path, err)
+			}
+		}
+		registries[indexName] = config
+	}
+	return registries, nil
+}
+
+// mirrorURLs returns the URLs of the mirrors of a registry.
+func (config RegistryConfig) mirrorURLs() []string {
+	urls := make([]string, 0, len(config.Mirrors))
+	for _, mirror := range config.Mirrors {
+		urls = append(urls, mirror.URL)
+	}
+	return urls
+}
+
+// tlsConfig returns the TLS configuration to communicate with a mirror.
+func (mirror MirrorConfig) tlsConfig(config *ServiceConfig) (*tls.Config, error) {
+	u, err := url.Parse(mirror.URL)
+	if err != nil {
+		return nil, err
+	}
+	secure := !mirror.Insecure && config.isSecureIndex(u.Host)
+	tlsConfig, err := newTLSConfig(u.Host, secure && mirror.CAFile == "" && mirror.CertFile == "")
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.InsecureSkipVerify = !secure
+
+	if mirror.CAFile != "" {
+		pem, err := ioutil.ReadFile(mirror.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate %q: %v", mirror.CAFile, err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to append certificates from PEM file: %q", mirror.CAFile)
+		}
+	}
+	if mirror.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(mirror.CertFile, mirror.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load X509 key pair: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// mirrorEndpoints returns the endpoints of the mirrors of a registry which
+// are configured in the registry configuration file of the daemon. Like the
+// mirrors of the official registry, they are expected to serve the v2 API.
+func (config *ServiceConfig) mirrorEndpoints(indexName string) ([]APIEndpoint, error) {
+	var endpoints []APIEndpoint
+	for _, mirror := range config.Registries[indexName].Mirrors {
+		tlsConfig, err := mirror.tlsConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, APIEndpoint{
+			URL:          mirror.URL,
+			Version:      APIVersion2,
+			Mirror:       true,
+			TrimHostname: true,
+			TLSConfig:    tlsConfig,
+		})
+	}
+	return endpoints, nil
+}
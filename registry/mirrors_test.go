@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRegistryConfig(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "registries.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRegistryConfig(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "registry-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := writeRegistryConfig(t, tmp, `{
+		"docker.io": {"mirrors": [{"url": "https://mirror.example.com"}]},
+		"registry.corp:5000": {"mirrors": [
+			{"url": "https://cache-1.corp"},
+			{"url": "http://cache-2.corp", "insecure": true}
+		]}
+	}`)
+	registries, err := LoadRegistryConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirrors := registries[IndexName].mirrorURLs(); len(mirrors) != 1 || mirrors[0] != "https://mirror.example.com/" {
+		t.Fatalf("Expected the mirror of the official registry, got %v", mirrors)
+	}
+	mirrors := registries["registry.corp:5000"].Mirrors
+	if len(mirrors) != 2 || mirrors[0].URL != "https://cache-1.corp/" || mirrors[1].URL != "http://cache-2.corp/" || !mirrors[1].Insecure {
+		t.Fatalf("Expected the mirrors of registry.corp:5000 in order, got %v", mirrors)
+	}
+
+	for _, invalid := range []string{
+		`{"registry.corp": {"mirrors": [{"url": "ftp://cache.corp"}]}}`,
+		`{"registry.corp": {"mirrors": [{"url": "https://cache.corp", "cert": "/cert.pem"}]}}`,
+		`{"registry.corp": {"mirrors": [{"url": "https://cache.corp", "ca": "/does/not/exist.crt"}]}}`,
+		`{"-registry.corp": {}}`,
+		`[]`,
+	} {
+		if _, err := LoadRegistryConfig(writeRegistryConfig(t, tmp, invalid)); err == nil {
+			t.Fatalf("Expected an error loading %s", invalid)
+		}
+	}
+}
+
+func TestRegistryConfigEndpointLookup(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "registry-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := writeRegistryConfig(t, tmp, `{
+		"docker.io": {"mirrors": [{"url": "https://mirror.example.com"}]},
+		"registry.corp": {"mirrors": [
+			{"url": "https://cache-1.corp"},
+			{"url": "https://cache-2.corp", "insecure": true}
+		]}
+	}`)
+	s := NewService(nil)
+	var registries RegistriesOpt
+	if err := registries.Set(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadMirrors([]string{"https://flag-mirror.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadRegistries(registries); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mirrors are tried in order before the registry
+	endpoints, err := s.LookupPullEndpoints("registry.corp/test/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) < 3 {
+		t.Fatalf("Expected the mirrors and the registry, got %v", endpoints)
+	}
+	for i, url := range []string{"https://cache-1.corp/", "https://cache-2.corp/", "https://registry.corp"} {
+		if endpoints[i].URL != url || endpoints[i].Mirror != (i < 2) {
+			t.Fatalf("Expected %s as endpoint %d, got %v", url, i, endpoints[i])
+		}
+	}
+	if endpoints[0].TLSConfig.InsecureSkipVerify || !endpoints[1].TLSConfig.InsecureSkipVerify {
+		t.Fatal("Expected only the insecure mirror to skip the verification of its certificate")
+	}
+
+	// The mirrors are not used to push
+	endpoints, err = s.LookupPushEndpoints("registry.corp/test/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Mirror {
+			t.Fatalf("Push endpoint should not contain mirror %s", endpoint.URL)
+		}
+	}
+
+	// The mirrors of the flags are tried before the ones of the file
+	endpoints, err = s.LookupPullEndpoints(IndexName + "/test/image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) < 3 || endpoints[0].URL != "https://flag-mirror.example.com/" || endpoints[1].URL != "https://mirror.example.com/" || endpoints[2].URL != DefaultV2Registry {
+		t.Fatalf("Expected the mirrors before the official registry, got %v", endpoints)
+	}
+
+	config := s.ServiceConfig()
+	if mirrors := config.IndexConfigs[IndexName].Mirrors; len(mirrors) != 2 {
+		t.Fatalf("Expected the mirrors of the official registry, got %v", mirrors)
+	}
+	if index, ok := config.IndexConfigs["registry.corp"]; !ok || len(index.Mirrors) != 2 || !index.Secure {
+		t.Fatalf("Expected the mirrors of registry.corp, got %v", index)
+	}
+
+	// An empty path clears the configuration
+	if err := registries.Set(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadRegistries(registries); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.ServiceConfig().IndexConfigs["registry.corp"]; ok {
+		t.Fatal("Expected the configuration of registry.corp to be removed")
+	}
+}
@@ -193,7 +193,8 @@ func shouldV2Fallback(err errcode.Error) bool {
 	case v2.ErrorCodeUnauthorized, v2.ErrorCodeManifestUnknown:
 		return true
 	}
-	return false
+	// Server errors, such as an unavailable pull-through cache
+	return err.Code.Descriptor().HTTPStatusCode >= http.StatusInternalServerError
 }
 
 // ErrNoSupport is an error type used for errors indicating that an operation
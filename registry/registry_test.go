@@ -929,6 +929,54 @@ func TestIsSecureIndex(t *testing.T) {
 	}
 }
 
+func TestServiceReload(t *testing.T) {
+	s := NewService(nil)
+	if s.ServiceConfig().isSecureIndex("example.com") != true {
+		t.Fatal("Expected example.com to be secure")
+	}
+
+	if err := s.LoadInsecureRegistries([]string{"example.com", "42.42.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadMirrors([]string{"https://mirror.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	config := s.ServiceConfig()
+	if config.isSecureIndex("example.com") {
+		t.Fatal("Expected example.com to be insecure after the reload")
+	}
+	if mirrors := config.IndexConfigs[IndexName].Mirrors; len(mirrors) != 1 || mirrors[0] != "https://mirror.example.com/" {
+		t.Fatalf("Expected the reloaded mirror, got %v", mirrors)
+	}
+	if insecure := s.InsecureRegistries(); len(insecure) != 2 {
+		t.Fatalf("Expected the insecure registries to be kept when the mirrors are reloaded, got %v", insecure)
+	}
+
+	if err := s.LoadMirrors([]string{"ftp://mirror.example.com"}); err == nil {
+		t.Fatal("Expected an error loading an invalid mirror")
+	}
+	if mirrors := s.Mirrors(); len(mirrors) != 1 {
+		t.Fatalf("Expected the mirrors to be unchanged after a failed reload, got %v", mirrors)
+	}
+
+	if err := ValidateReload([]string{"https://other.example.com"}, []string{"-invalid"}); err == nil {
+		t.Fatal("Expected an error validating an invalid insecure registry")
+	}
+	if err := s.Reload([]string{"https://other.example.com"}, []string{"-invalid"}, s.Registries()); err == nil {
+		t.Fatal("Expected an error reloading an invalid insecure registry")
+	}
+	if mirrors := s.Mirrors(); len(mirrors) != 1 || mirrors[0] != "https://mirror.example.com/" {
+		t.Fatalf("Expected the mirrors to be unchanged when the insecure registries are invalid, got %v", mirrors)
+	}
+
+	if err := s.Reload(nil, []string{"example.com"}, s.Registries()); err != nil {
+		t.Fatal(err)
+	}
+	if mirrors, insecure := s.Mirrors(), s.InsecureRegistries(); len(mirrors) != 0 || len(insecure) != 1 {
+		t.Fatalf("Expected the mirrors and the insecure registries to be replaced together, got %v and %v", mirrors, insecure)
+	}
+}
+
 type debugTransport struct {
 	http.RoundTripper
 	log func(...interface{})
@@ -4,23 +4,136 @@ import (
 	"crypto/tls"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/docker/cliconfig"
+	"github.com/docker/docker/opts"
 )
 
 // Service is a registry service. It tracks configuration data such as a list
 // of mirrors.
 type Service struct {
 	Config *ServiceConfig
+
+	// mu protects Config, which is replaced when the mirrors, the insecure
+	// registries or the registry configuration file are reloaded.
+	mu                 sync.RWMutex
+	mirrors            []string
+	insecureRegistries []string
+	registries         RegistriesOpt
 }
 
 // NewService returns a new instance of Service ready to be
 // installed into an engine.
 func NewService(options *Options) *Service {
-	return &Service{
-		Config: NewServiceConfig(options),
+	s := &Service{}
+	if options != nil {
+		s.mirrors = options.Mirrors.GetAll()
+		s.insecureRegistries = options.InsecureRegistries.GetAll()
+		s.registries = options.Registries
 	}
+	s.Config = NewServiceConfig(options)
+	return s
+}
+
+// ServiceConfig returns the current configuration of the service.
+func (s *Service) ServiceConfig() *ServiceConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Config
+}
+
+// Mirrors returns the mirrors of the official registry.
+func (s *Service) Mirrors() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mirrors
+}
+
+// InsecureRegistries returns the registries, or the subnets in CIDR
+// notation, with which insecure communication is allowed.
+func (s *Service) InsecureRegistries() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.insecureRegistries
+}
+
+// Registries returns the registry configuration file of the service.
+func (s *Service) Registries() RegistriesOpt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registries
+}
+
+// LoadMirrors replaces the mirrors of the official registry.
+func (s *Service) LoadMirrors(mirrors []string) error {
+	return s.reload(mirrors, s.InsecureRegistries(), s.Registries())
+}
+
+// LoadInsecureRegistries replaces the registries, or the subnets in CIDR
+// notation, with which insecure communication is allowed.
+func (s *Service) LoadInsecureRegistries(insecureRegistries []string) error {
+	return s.reload(s.Mirrors(), insecureRegistries, s.Registries())
+}
+
+// LoadRegistries replaces the configuration of the registries read from
+// the registry configuration file.
+func (s *Service) LoadRegistries(registries RegistriesOpt) error {
+	return s.reload(s.Mirrors(), s.InsecureRegistries(), registries)
+}
+
+// Reload replaces the mirrors of the official registry, the insecure
+// registries and the configuration of the registries at once. Nothing is
+// replaced if a mirror or an insecure registry is invalid.
+func (s *Service) Reload(mirrors, insecureRegistries []string, registries RegistriesOpt) error {
+	return s.reload(mirrors, insecureRegistries, registries)
+}
+
+// ValidateReload returns an error if a mirror or an insecure registry
+// cannot be loaded into the service.
+func ValidateReload(mirrors, insecureRegistries []string) error {
+	_, err := newReloadOptions(mirrors, insecureRegistries, RegistriesOpt{})
+	return err
+}
+
+func newReloadOptions(mirrors, insecureRegistries []string, registries RegistriesOpt) (*Options, error) {
+	options := &Options{
+		Mirrors:            opts.NewListOpts(ValidateMirror),
+		InsecureRegistries: opts.NewListOpts(ValidateIndexName),
+		Registries:         registries,
+	}
+	for _, mirror := range mirrors {
+		// The mirrors of the service were normalized with a trailing slash
+		if err := options.Mirrors.Set(strings.TrimSuffix(mirror, "/")); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range insecureRegistries {
+		if err := options.InsecureRegistries.Set(r); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func (s *Service) reload(mirrors, insecureRegistries []string, registries RegistriesOpt) error {
+	options, err := newReloadOptions(mirrors, insecureRegistries, registries)
+	if err != nil {
+		return err
+	}
+	mirrors = options.Mirrors.GetAll()
+	insecureRegistries = options.InsecureRegistries.GetAll()
+	config := NewServiceConfig(options)
+
+	s.mu.Lock()
+	s.Config = config
+	s.mirrors = mirrors
+	s.insecureRegistries = insecureRegistries
+	s.registries = registries
+	s.mu.Unlock()
+	return nil
 }
 
 // Auth contacts the public registry with the provided credentials,
@@ -76,18 +189,18 @@ func (s *Service) Search(term string, authConfig *cliconfig.AuthConfig, headers
 // ResolveRepository splits a repository name into its components
 // and configuration of the associated registry.
 func (s *Service) ResolveRepository(name string) (*RepositoryInfo, error) {
-	return s.Config.NewRepositoryInfo(name, false)
+	return s.ServiceConfig().NewRepositoryInfo(name, false)
 }
 
 // ResolveRepositoryBySearch splits a repository name into its components
 // and configuration of the associated registry.
 func (s *Service) ResolveRepositoryBySearch(name string) (*RepositoryInfo, error) {
-	return s.Config.NewRepositoryInfo(name, true)
+	return s.ServiceConfig().NewRepositoryInfo(name, true)
 }
 
 // ResolveIndex takes indexName and returns index info
 func (s *Service) ResolveIndex(name string) (*IndexInfo, error) {
-	return s.Config.NewIndexInfo(name)
+	return s.ServiceConfig().NewIndexInfo(name)
 }
 
 // APIEndpoint represents a remote API endpoint
@@ -109,7 +222,7 @@ func (e APIEndpoint) ToV1Endpoint(metaHeaders http.Header) (*Endpoint, error) {
 
 // TLSConfig constructs a client TLS configuration based on server defaults
 func (s *Service) TLSConfig(hostname string) (*tls.Config, error) {
-	return newTLSConfig(hostname, s.Config.isSecureIndex(hostname))
+	return newTLSConfig(hostname, s.ServiceConfig().isSecureIndex(hostname))
 }
 
 func (s *Service) tlsConfigForMirror(mirror string) (*tls.Config, error) {
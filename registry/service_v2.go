@@ -11,9 +11,10 @@ import (
 func (s *Service) lookupV2Endpoints(repoName string) (endpoints []APIEndpoint, err error) {
 	var cfg = tlsconfig.ServerDefault
 	tlsConfig := &cfg
+	config := s.ServiceConfig()
 	if strings.HasPrefix(repoName, DefaultNamespace+"/") {
 		// v2 mirrors
-		for _, mirror := range s.Config.Mirrors {
+		for _, mirror := range config.Mirrors {
 			mirrorTLSConfig, err := s.tlsConfigForMirror(mirror)
 			if err != nil {
 				return nil, err
@@ -27,6 +28,11 @@ func (s *Service) lookupV2Endpoints(repoName string) (endpoints []APIEndpoint, e
 				TLSConfig:    mirrorTLSConfig,
 			})
 		}
+		mirrors, err := config.mirrorEndpoints(IndexName)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, mirrors...)
 		// v2 registry
 		endpoints = append(endpoints, APIEndpoint{
 			URL:          DefaultV2Registry,
@@ -45,6 +51,12 @@ func (s *Service) lookupV2Endpoints(repoName string) (endpoints []APIEndpoint, e
 	}
 	hostname := repoName[:slashIndex]
 
+	// v2 mirrors
+	endpoints, err = config.mirrorEndpoints(hostname)
+	if err != nil {
+		return nil, err
+	}
+
 	tlsConfig, err = s.TLSConfig(hostname)
 	if err != nil {
 		return nil, err
@@ -56,16 +68,14 @@ func (s *Service) lookupV2Endpoints(repoName string) (endpoints []APIEndpoint, e
 			Version: "2.0",
 		},
 	}
-	endpoints = []APIEndpoint{
-		{
-			URL:           "https://" + hostname,
-			Version:       APIVersion2,
-			TrimHostname:  true,
-			TLSConfig:     tlsConfig,
-			VersionHeader: DefaultRegistryVersionHeader,
-			Versions:      v2Versions,
-		},
-	}
+	endpoints = append(endpoints, APIEndpoint{
+		URL:           "https://" + hostname,
+		Version:       APIVersion2,
+		TrimHostname:  true,
+		TLSConfig:     tlsConfig,
+		VersionHeader: DefaultRegistryVersionHeader,
+		Versions:      v2Versions,
+	})
 
 	if tlsConfig.InsecureSkipVerify {
 		endpoints = append(endpoints, APIEndpoint{
@@ -59,5 +59,24 @@ func Compare(a, b *Config) bool {
 			return false
 		}
 	}
+	return compareHealthConfig(a.Healthcheck, b.Healthcheck)
+}
+
+// compareHealthConfig returns true if both health configurations are equal.
+func compareHealthConfig(a, b *HealthConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Interval != b.Interval ||
+		a.Timeout != b.Timeout ||
+		a.Retries != b.Retries ||
+		len(a.Test) != len(b.Test) {
+		return false
+	}
+	for i := range a.Test {
+		if a.Test[i] != b.Test[i] {
+			return false
+		}
+	}
 	return true
 }
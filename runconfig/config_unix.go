@@ -2,13 +2,16 @@
 
 package runconfig
 
-// ContainerConfigWrapper is a Config wrapper that hold the container Config (portable)
-// and the corresponding HostConfig (non-portable).
+import "github.com/docker/docker/daemon/network"
+
+// ContainerConfigWrapper is a Config wrapper that hold the container Config (portable),
+// the corresponding HostConfig (non-portable) and the NetworkingConfig.
 type ContainerConfigWrapper struct {
 	*Config
-	InnerHostConfig *HostConfig `json:"HostConfig,omitempty"`
-	Cpuset          string      `json:",omitempty"` // Deprecated. Exported for backwards compatibility.
-	*HostConfig                 // Deprecated. Exported to read attributes from json that are not in the inner host config structure.
+	InnerHostConfig  *HostConfig               `json:"HostConfig,omitempty"`
+	Cpuset           string                    `json:",omitempty"` // Deprecated. Exported for backwards compatibility.
+	NetworkingConfig *network.NetworkingConfig `json:",omitempty"`
+	*HostConfig                                // Deprecated. Exported to read attributes from json that are not in the inner host config structure.
 }
 
 // getHostConfig gets the HostConfig of the Config.
@@ -1,10 +1,13 @@
 package runconfig
 
-// ContainerConfigWrapper is a Config wrapper that hold the container Config (portable)
-// and the corresponding HostConfig (non-portable).
+import "github.com/docker/docker/daemon/network"
+
+// ContainerConfigWrapper is a Config wrapper that hold the container Config (portable),
+// the corresponding HostConfig (non-portable) and the NetworkingConfig.
 type ContainerConfigWrapper struct {
 	*Config
-	HostConfig *HostConfig `json:"HostConfig,omitempty"`
+	HostConfig       *HostConfig               `json:"HostConfig,omitempty"`
+	NetworkingConfig *network.NetworkingConfig `json:",omitempty"`
 }
 
 // getHostConfig gets the HostConfig of the Config.
@@ -218,7 +218,7 @@ func TestMergeConfigs(t *testing.T) {
 	hostConfig := &HostConfig{
 		ContainerIDFile: expectedContainerIDFile,
 	}
-	containerConfigWrapper := MergeConfigs(config, hostConfig)
+	containerConfigWrapper := MergeConfigs(config, hostConfig, nil)
 	if containerConfigWrapper.Config.Hostname != expectedHostname {
 		t.Fatalf("containerConfigWrapper config hostname expected %v got %v", expectedHostname, containerConfigWrapper.Config.Hostname)
 	}
@@ -4,6 +4,8 @@ package runconfig
 
 import (
 	"strings"
+
+	"github.com/docker/docker/daemon/network"
 )
 
 // IsPrivate indicates whether container uses it's private network stack.
@@ -74,12 +76,12 @@ func (n NetworkMode) UserDefined() string {
 	return ""
 }
 
-// MergeConfigs merges the specified container Config and HostConfig.
-// It creates a ContainerConfigWrapper.
-func MergeConfigs(config *Config, hostConfig *HostConfig) *ContainerConfigWrapper {
+// MergeConfigs merges the specified container Config, HostConfig and
+// NetworkingConfig. It creates a ContainerConfigWrapper.
+func MergeConfigs(config *Config, hostConfig *HostConfig, networkingConfig *network.NetworkingConfig) *ContainerConfigWrapper {
 	return &ContainerConfigWrapper{
 		config,
 		hostConfig,
-		"", nil,
+		"", networkingConfig, nil,
 	}
 }
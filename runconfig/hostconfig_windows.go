@@ -1,5 +1,7 @@
 package runconfig
 
+import "github.com/docker/docker/daemon/network"
+
 // IsDefault indicates whether container uses the default network stack.
 func (n NetworkMode) IsDefault() bool {
 	return n == "default"
@@ -19,11 +21,12 @@ func (n NetworkMode) NetworkName() string {
 	return ""
 }
 
-// MergeConfigs merges the specified container Config and HostConfig.
-// It creates a ContainerConfigWrapper.
-func MergeConfigs(config *Config, hostConfig *HostConfig) *ContainerConfigWrapper {
+// MergeConfigs merges the specified container Config, HostConfig and
+// NetworkingConfig. It creates a ContainerConfigWrapper.
+func MergeConfigs(config *Config, hostConfig *HostConfig, networkingConfig *network.NetworkingConfig) *ContainerConfigWrapper {
 	return &ContainerConfigWrapper{
 		config,
 		hostConfig,
+		networkingConfig,
 	}
 }
@@ -2,6 +2,7 @@ package runconfig
 
 import (
 	"testing"
+	"time"
 
 	"github.com/docker/docker/pkg/nat"
 )
@@ -81,3 +82,36 @@ func TestMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeHealthcheck(t *testing.T) {
+	configImage := &Config{
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "true"},
+			Interval: time.Minute,
+			Retries:  5,
+		},
+	}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Healthcheck != configImage.Healthcheck {
+		t.Fatalf("Expected the image healthcheck to be inherited, got %v", configUser.Healthcheck)
+	}
+
+	configUser = &Config{
+		Healthcheck: &HealthConfig{
+			Interval: time.Second,
+		},
+	}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Healthcheck.Interval != time.Second {
+		t.Fatalf("Expected the user interval to be kept, got %v", configUser.Healthcheck.Interval)
+	}
+	if len(configUser.Healthcheck.Test) != 2 || configUser.Healthcheck.Retries != 5 {
+		t.Fatalf("Expected the image test and retries to be inherited, got %v", configUser.Healthcheck)
+	}
+}
@@ -10,12 +10,18 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/daemon/network"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/parsers"
 )
 
 func parseRun(args []string) (*Config, *HostConfig, *flag.FlagSet, error) {
+	config, hostConfig, _, cmd, err := parseRunNetworking(args)
+	return config, hostConfig, cmd, err
+}
+
+func parseRunNetworking(args []string) (*Config, *HostConfig, *network.NetworkingConfig, *flag.FlagSet, error) {
 	cmd := flag.NewFlagSet("run", flag.ContinueOnError)
 	cmd.SetOutput(ioutil.Discard)
 	cmd.Usage = nil
@@ -303,7 +309,7 @@ func callDecodeContainerConfig(volumes []string, binds []string) (*Config, *Host
 	if b, err = json.Marshal(w); err != nil {
 		return nil, nil, fmt.Errorf("Error on marshal %s", err.Error())
 	}
-	c, h, err = DecodeContainerConfig(bytes.NewReader(b))
+	c, h, _, err = DecodeContainerConfig(bytes.NewReader(b))
 	if err != nil {
 		return nil, nil, fmt.Errorf("Error parsing %s: %v", string(b), err)
 	}
@@ -411,6 +417,56 @@ func TestParseWithMemorySwap(t *testing.T) {
 	}
 }
 
+func TestParseWithBlkioDevices(t *testing.T) {
+	_, hostconfig := mustParse(t, "--blkio-weight-device=/dev/sda:300 --device-read-bps=/dev/sda:1mb --device-write-iops=/dev/sdb:1000")
+	if len(hostconfig.BlkioWeightDevice) != 1 || hostconfig.BlkioWeightDevice[0].String() != "/dev/sda:300" {
+		t.Fatalf("Expected a weight of 300 for /dev/sda, got %v", hostconfig.BlkioWeightDevice)
+	}
+	if len(hostconfig.BlkioDeviceReadBps) != 1 || hostconfig.BlkioDeviceReadBps[0].Rate != 1048576 {
+		t.Fatalf("Expected a read rate of 1048576 bytes per second for /dev/sda, got %v", hostconfig.BlkioDeviceReadBps)
+	}
+	if len(hostconfig.BlkioDeviceWriteIOps) != 1 || hostconfig.BlkioDeviceWriteIOps[0].String() != "/dev/sdb:1000" {
+		t.Fatalf("Expected a write rate of 1000 IO per second for /dev/sdb, got %v", hostconfig.BlkioDeviceWriteIOps)
+	}
+	if _, _, _, err := parseRun([]string{"--device-read-bps=sda:1mb", "img", "cmd"}); err == nil {
+		t.Fatalf("Expected an error with an invalid device path")
+	}
+}
+
+func TestParseWithPidsLimit(t *testing.T) {
+	if _, hostconfig := mustParse(t, ""); hostconfig.PidsLimit != 0 {
+		t.Fatalf("Expected no pids limit by default, got %d", hostconfig.PidsLimit)
+	}
+	if _, hostconfig := mustParse(t, "--pids-limit=100"); hostconfig.PidsLimit != 100 {
+		t.Fatalf("Expected a pids limit of 100, got %d", hostconfig.PidsLimit)
+	}
+}
+
+func TestParseNetworkingConfig(t *testing.T) {
+	_, _, networkingConfig, _, err := parseRunNetworking([]string{"img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(networkingConfig.EndpointsConfig) != 0 {
+		t.Fatalf("Expected no endpoint settings, got %v", networkingConfig.EndpointsConfig)
+	}
+
+	_, _, networkingConfig, _, err = parseRunNetworking([]string{"--net=mynet", "--ip=10.1.0.5", "--ip6=2001:db8::33", "--network-alias=db", "--network-alias=database", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	epConfig, ok := networkingConfig.EndpointsConfig["mynet"]
+	if !ok || len(networkingConfig.EndpointsConfig) != 1 {
+		t.Fatalf("Expected endpoint settings for network mynet, got %v", networkingConfig.EndpointsConfig)
+	}
+	if epConfig.IPAMConfig == nil || epConfig.IPAMConfig.IPv4Address != "10.1.0.5" || epConfig.IPAMConfig.IPv6Address != "2001:db8::33" {
+		t.Fatalf("Expected the addresses 10.1.0.5 and 2001:db8::33, got %v", epConfig.IPAMConfig)
+	}
+	if len(epConfig.Aliases) != 2 || epConfig.Aliases[0] != "db" || epConfig.Aliases[1] != "database" {
+		t.Fatalf("Expected the aliases db and database, got %v", epConfig.Aliases)
+	}
+}
+
 func TestParseHostname(t *testing.T) {
 	hostname := "--hostname=hostname"
 	hostnameWithDomain := "--hostname=hostname.domainname"
@@ -606,6 +662,21 @@ func TestParseLoggingOpts(t *testing.T) {
 	}
 }
 
+func TestParseStorageOpts(t *testing.T) {
+	// storage opts ko
+	if _, _, _, err := parseRun([]string{"--storage-opt=size", "img", "cmd"}); err == nil || err.Error() != `Invalid storage option "size": the format is key=value` {
+		t.Fatalf("Expected an error with message 'Invalid storage option \"size\": the format is key=value', got %v", err)
+	}
+	// storage opts ok
+	_, hostconfig, _, err := parseRun([]string{"--storage-opt=size=10G", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hostconfig.StorageOpt) != 1 || hostconfig.StorageOpt["size"] != "10G" {
+		t.Fatalf("Expected a StorageOpt with size=10G, got %v", hostconfig.StorageOpt)
+	}
+}
+
 func TestParseEnvfileVariables(t *testing.T) {
 	e := "open nonexistent: no such file or directory"
 	if runtime.GOOS == "windows" {
@@ -26,10 +26,21 @@ func New() *VolumeStore {
 	}
 }
 
+// EventLogger is called by a VolumeStore with the name of a volume, the
+// action performed on it and the attributes of the volume.
+type EventLogger func(name, action string, attributes map[string]string)
+
 // VolumeStore is a struct that stores the list of volumes available and keeps track of their usage counts
 type VolumeStore struct {
-	vols map[string]*volumeCounter
-	mu   sync.Mutex
+	vols     map[string]*volumeCounter
+	mu       sync.Mutex
+	logEvent EventLogger
+}
+
+// SetEventLogger sets the function called when the store creates or
+// removes a volume.
+func (s *VolumeStore) SetEventLogger(l EventLogger) {
+	s.logEvent = l
 }
 
 // volumeCounter keeps track of references to a volume
@@ -80,6 +91,9 @@ func (s *VolumeStore) Create(name, driverName string, opts map[string]string) (v
 	s.vols[normaliseVolumeName(v.Name())] = &volumeCounter{v, 0}
 	s.mu.Unlock()
 
+	if s.logEvent != nil {
+		s.logEvent(v.Name(), "create", map[string]string{"driver": v.DriverName()})
+	}
 	return v, nil
 }
 
@@ -118,6 +132,10 @@ func (s *VolumeStore) Remove(v volume.Volume) error {
 		return err
 	}
 	delete(s.vols, name)
+
+	if s.logEvent != nil {
+		s.logEvent(vc.Name(), "destroy", map[string]string{"driver": vc.DriverName()})
+	}
 	return nil
 }
 
@@ -82,6 +82,31 @@ func TestRemove(t *testing.T) {
 	}
 }
 
+func TestEventLogger(t *testing.T) {
+	volumedrivers.Register(vt.FakeDriver{}, "fake")
+	s := New()
+	var events []string
+	s.SetEventLogger(func(name, action string, attributes map[string]string) {
+		events = append(events, action+" "+name+" "+attributes["driver"])
+	})
+
+	v, err := s.Create("fake1", "fake", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Getting an existing volume does not create it again
+	if _, err := s.Create("fake1", "fake", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove(v); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || events[0] != "create fake1 fake" || events[1] != "destroy fake1 fake" {
+		t.Fatalf("Unexpected events %v", events)
+	}
+}
+
 func TestIncrement(t *testing.T) {
 	s := New()
 	v := vt.NewFakeVolume("fake1")